@@ -20,7 +20,7 @@ import (
 
 func main() {
 	// Initialize application with wire-generated dependency injection
-	app, err := InitializeApplication()
+	app, cleanup, err := InitializeApplication()
 	if err != nil {
 		log.Fatalf("Failed to initialize application: %v", err)
 	}
@@ -36,6 +36,13 @@ func main() {
 	app.Probes.PingHandler.RegisterRoutes(fiberApp)
 	app.Probes.HealthHandler.RegisterRoutes(fiberApp)
 	app.RateLimit.RateLimitHandler.RegisterRoutes(fiberApp)
+	app.RateLimit.RateLimitAdminHandler.RegisterRoutes(fiberApp)
+	app.RateLimit.ConcurrencyLimitHandler.RegisterRoutes(fiberApp)
+	app.RateLimit.QuotaHandler.RegisterRoutes(fiberApp)
+	app.RateLimit.PolicyHandler.RegisterRoutes(fiberApp)
+	app.RateLimit.OverrideHandler.RegisterRoutes(fiberApp)
+	app.RateLimit.TierHandler.RegisterRoutes(fiberApp)
+	app.RateLimit.AccessHandler.RegisterRoutes(fiberApp)
 	app.Swagger.DocsHandler.RegisterRoutes(fiberApp, app.Config.Swagger.Enabled)
 	app.Logger.Info().Msg("Routes registered successfully")
 
@@ -59,5 +66,8 @@ func main() {
 		app.Logger.Error().Err(err).Msg("Server forced to shutdown")
 	}
 
+	// Stop background work once no more requests are served
+	cleanup()
+
 	app.Logger.Info().Msg("Server exited")
 }
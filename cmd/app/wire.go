@@ -35,7 +35,14 @@ type ProbesModule struct {
 
 // RateLimitModule holds all rate limit-related dependencies
 type RateLimitModule struct {
-	RateLimitHandler *rateLimitHttp.RateLimitHandler
+	RateLimitHandler        *rateLimitHttp.RateLimitHandler
+	RateLimitAdminHandler   *rateLimitHttp.RateLimitAdminHandler
+	ConcurrencyLimitHandler *rateLimitHttp.ConcurrencyLimitHandler
+	QuotaHandler            *rateLimitHttp.QuotaHandler
+	PolicyHandler           *rateLimitHttp.PolicyHandler
+	OverrideHandler         *rateLimitHttp.OverrideHandler
+	TierHandler             *rateLimitHttp.TierHandler
+	AccessHandler           *rateLimitHttp.AccessHandler
 }
 
 // SwaggerModule holds all swagger-related dependencies
@@ -43,15 +50,16 @@ type SwaggerModule struct {
 	DocsHandler *swaggerHttp.DocsHandler
 }
 
-// InitializeApplication creates and initializes the application with all dependencies
-func InitializeApplication() (*Application, error) {
+// InitializeApplication creates and initializes the application with all dependencies.
+// The returned cleanup function stops the background work of the dependencies.
+func InitializeApplication() (*Application, func(), error) {
 	wire.Build(
 		// Platform providers
 		platform.PlatformSet,
 
 		// Internal module providers
 		probes.ProbesSet,
-		ratelimit.ProviderSet,
+		ratelimit.HybridProviderSet,
 		swagger.SwaggerSet,
 
 		// Application structure providers
@@ -60,7 +68,7 @@ func InitializeApplication() (*Application, error) {
 		ProvideSwaggerModule,
 		ProvideApplication,
 	)
-	return &Application{}, nil
+	return &Application{}, nil, nil
 }
 
 // ProvideProbesModule provides the probes module
@@ -77,9 +85,23 @@ func ProvideProbesModule(
 // ProvideRateLimitModule provides the rate limit module
 func ProvideRateLimitModule(
 	rateLimitHandler *rateLimitHttp.RateLimitHandler,
+	rateLimitAdminHandler *rateLimitHttp.RateLimitAdminHandler,
+	concurrencyLimitHandler *rateLimitHttp.ConcurrencyLimitHandler,
+	quotaHandler *rateLimitHttp.QuotaHandler,
+	policyHandler *rateLimitHttp.PolicyHandler,
+	overrideHandler *rateLimitHttp.OverrideHandler,
+	tierHandler *rateLimitHttp.TierHandler,
+	accessHandler *rateLimitHttp.AccessHandler,
 ) *RateLimitModule {
 	return &RateLimitModule{
-		RateLimitHandler: rateLimitHandler,
+		RateLimitHandler:        rateLimitHandler,
+		RateLimitAdminHandler:   rateLimitAdminHandler,
+		ConcurrencyLimitHandler: concurrencyLimitHandler,
+		QuotaHandler:            quotaHandler,
+		PolicyHandler:           policyHandler,
+		OverrideHandler:         overrideHandler,
+		TierHandler:             tierHandler,
+		AccessHandler:           accessHandler,
 	}
 }
 
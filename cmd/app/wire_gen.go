@@ -10,6 +10,8 @@ import (
 	"github.com/go-clean/internal/probes"
 	http3 "github.com/go-clean/internal/probes/presentation/http"
 	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/application/limits"
+	"github.com/go-clean/internal/ratelimit/application/query"
 	"github.com/go-clean/internal/ratelimit/infrastructure"
 	"github.com/go-clean/internal/ratelimit/presentation/http"
 	"github.com/go-clean/internal/swagger"
@@ -22,24 +24,25 @@ import (
 
 // Injectors from wire.go:
 
-// InitializeApplication creates and initializes the application with all dependencies
-func InitializeApplication() (*Application, error) {
+// InitializeApplication creates and initializes the application with all dependencies.
+// The returned cleanup function stops the background work of the dependencies.
+func InitializeApplication() (*Application, func(), error) {
 	logger := platform.ProvideLogger()
 	config, err := platform.ProvideConfig(logger)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	server := platform.ProvideHTTPServer(config, logger)
 	pingQueryHandler := probes.ProvidePingQueryHandler(logger)
 	pingHandler := probes.ProvidePingHandler(logger, pingQueryHandler)
 	pool, err := platform.ProvideDatabase(config, logger)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	databaseChecker := probes.ProvideDatabaseChecker(logger, pool)
 	client, err := platform.ProvideRedis(config, logger)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	redisChecker := probes.ProvideRedisChecker(logger, client)
 	getHealthQueryHandler := probes.ProvideHealthQueryHandler(logger, databaseChecker, redisChecker)
@@ -48,20 +51,94 @@ func InitializeApplication() (*Application, error) {
 	livenessService := probes.ProvideLivenessService(logger, getLivenessQueryHandler)
 	healthHandler := probes.ProvideHealthHandler(logger, healthService, livenessService)
 	probesModule := ProvideProbesModule(pingHandler, healthHandler)
-	redisRateLimitRepository := infrastructure.NewRedisRateLimitRepository(logger, client)
-	checkRateLimitWithDetailCommandHandler := command.NewCheckRateLimitWithDetailCommandHandler(logger, redisRateLimitRepository)
-	rateLimitHandler := http.NewRateLimitHandler(logger, checkRateLimitWithDetailCommandHandler)
-	rateLimitModule := ProvideRateLimitModule(rateLimitHandler)
+	systemClock := infrastructure.NewSystemClock()
+	redisRateLimitRepository := infrastructure.NewRedisRateLimitRepository(logger, client, systemClock)
+	hybridRateLimitRepository, cleanup, err := infrastructure.NewHybridRateLimitRepository(logger, redisRateLimitRepository, systemClock, config)
+	if err != nil {
+		return nil, nil, err
+	}
+	tokenBucketRateLimitRepository := infrastructure.NewTokenBucketRateLimitRepository(logger, client, config)
+	slidingWindowLogRateLimitRepository := infrastructure.NewSlidingWindowLogRateLimitRepository(logger, client)
+	slidingWindowCounterRateLimitRepository := infrastructure.NewSlidingWindowCounterRateLimitRepository(logger, client)
+	gcraRateLimitRepository := infrastructure.NewGCRARateLimitRepository(logger, client, config)
+	algorithmRepositoryProvider := infrastructure.NewAlgorithmRepositoryProvider(logger, hybridRateLimitRepository, hybridRateLimitRepository, tokenBucketRateLimitRepository, slidingWindowLogRateLimitRepository, slidingWindowCounterRateLimitRepository, gcraRateLimitRepository)
+	postgresPolicyRepository := infrastructure.NewPostgresPolicyRepository(logger, pool)
+	cachedPolicyRepository := infrastructure.NewCachedPolicyRepository(logger, postgresPolicyRepository, config)
+	filePolicySource, err := infrastructure.NewFilePolicySource(logger, config)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	postgresOverrideRepository := infrastructure.NewPostgresOverrideRepository(logger, pool)
+	cachedOverrideRepository := infrastructure.NewCachedOverrideRepository(logger, postgresOverrideRepository, config)
+	postgresTierRepository := infrastructure.NewPostgresTierRepository(logger, pool)
+	cachedTierRepository := infrastructure.NewCachedTierRepository(logger, postgresTierRepository, config)
+	resolver := limits.NewResolver(logger, cachedPolicyRepository, filePolicySource, cachedOverrideRepository, cachedTierRepository, systemClock, config)
+	postgresAccessRuleRepository := infrastructure.NewPostgresAccessRuleRepository(logger, pool)
+	cachedAccessRuleRepository := infrastructure.NewCachedAccessRuleRepository(logger, postgresAccessRuleRepository, config)
+	redisShadowLog := infrastructure.NewRedisShadowLog(logger, client, config)
+	checkRateLimitWithDetailCommandHandler := command.NewCheckRateLimitWithDetailCommandHandler(logger, algorithmRepositoryProvider, resolver, cachedAccessRuleRepository, redisShadowLog)
+	getRateLimitStatusQueryHandler := query.NewGetRateLimitStatusQueryHandler(logger, algorithmRepositoryProvider, resolver)
+	refundRateLimitCommandHandler := command.NewRefundRateLimitCommandHandler(logger, algorithmRepositoryProvider, resolver, cachedAccessRuleRepository)
+	rateLimitHandler := http.NewRateLimitHandler(logger, checkRateLimitWithDetailCommandHandler, getRateLimitStatusQueryHandler, refundRateLimitCommandHandler)
+	resetRateLimitCommandHandler := command.NewResetRateLimitCommandHandler(logger, algorithmRepositoryProvider)
+	adjustRateLimitCommandHandler := command.NewAdjustRateLimitCommandHandler(logger, algorithmRepositoryProvider)
+	rateLimitAdminHandler := http.NewRateLimitAdminHandler(logger, resetRateLimitCommandHandler, adjustRateLimitCommandHandler)
+	redisConcurrencyLimitRepository := infrastructure.NewRedisConcurrencyLimitRepository(logger, client)
+	acquireLeaseCommandHandler := command.NewAcquireLeaseCommandHandler(logger, redisConcurrencyLimitRepository, config)
+	releaseLeaseCommandHandler := command.NewReleaseLeaseCommandHandler(logger, redisConcurrencyLimitRepository)
+	concurrencyLimitHandler := http.NewConcurrencyLimitHandler(logger, acquireLeaseCommandHandler, releaseLeaseCommandHandler)
+	postgresQuotaUsageStore := infrastructure.NewPostgresQuotaUsageStore(logger, pool)
+	redisQuotaRepository, cleanup2, err := infrastructure.NewRedisQuotaRepository(logger, client, postgresQuotaUsageStore, config)
+	if err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+	consumeQuotaCommandHandler, err := command.NewConsumeQuotaCommandHandler(logger, redisQuotaRepository, config)
+	if err != nil {
+		cleanup2()
+		cleanup()
+		return nil, nil, err
+	}
+	quotaHandler := http.NewQuotaHandler(logger, consumeQuotaCommandHandler)
+	createPolicyCommandHandler := command.NewCreatePolicyCommandHandler(logger, cachedPolicyRepository)
+	updatePolicyCommandHandler := command.NewUpdatePolicyCommandHandler(logger, cachedPolicyRepository)
+	deletePolicyCommandHandler := command.NewDeletePolicyCommandHandler(logger, cachedPolicyRepository)
+	getPolicyQueryHandler := query.NewGetPolicyQueryHandler(logger, cachedPolicyRepository)
+	listPoliciesQueryHandler := query.NewListPoliciesQueryHandler(logger, cachedPolicyRepository)
+	getShadowReportQueryHandler := query.NewGetShadowReportQueryHandler(logger, redisShadowLog)
+	policyHandler := http.NewPolicyHandler(logger, createPolicyCommandHandler, updatePolicyCommandHandler, deletePolicyCommandHandler, getPolicyQueryHandler, listPoliciesQueryHandler, getShadowReportQueryHandler)
+	putOverrideCommandHandler := command.NewPutOverrideCommandHandler(logger, cachedOverrideRepository, systemClock)
+	deleteOverrideCommandHandler := command.NewDeleteOverrideCommandHandler(logger, cachedOverrideRepository)
+	getOverrideQueryHandler := query.NewGetOverrideQueryHandler(logger, cachedOverrideRepository)
+	listOverridesQueryHandler := query.NewListOverridesQueryHandler(logger, cachedOverrideRepository)
+	overrideHandler := http.NewOverrideHandler(logger, putOverrideCommandHandler, deleteOverrideCommandHandler, getOverrideQueryHandler, listOverridesQueryHandler, systemClock)
+	assignTierCommandHandler := command.NewAssignTierCommandHandler(logger, cachedTierRepository)
+	unassignTierCommandHandler := command.NewUnassignTierCommandHandler(logger, cachedTierRepository)
+	importTiersCommandHandler := command.NewImportTiersCommandHandler(logger, cachedTierRepository, cachedPolicyRepository)
+	getTierQueryHandler := query.NewGetTierQueryHandler(logger, cachedTierRepository)
+	tierHandler := http.NewTierHandler(logger, assignTierCommandHandler, unassignTierCommandHandler, importTiersCommandHandler, getTierQueryHandler)
+	createAccessRuleCommandHandler := command.NewCreateAccessRuleCommandHandler(logger, cachedAccessRuleRepository)
+	deleteAccessRuleCommandHandler := command.NewDeleteAccessRuleCommandHandler(logger, cachedAccessRuleRepository)
+	listAccessRulesQueryHandler := query.NewListAccessRulesQueryHandler(logger, cachedAccessRuleRepository)
+	listAccessAuditQueryHandler := query.NewListAccessAuditQueryHandler(logger, cachedAccessRuleRepository)
+	accessHandler := http.NewAccessHandler(logger, createAccessRuleCommandHandler, deleteAccessRuleCommandHandler, listAccessRulesQueryHandler, listAccessAuditQueryHandler)
+	rateLimitModule := ProvideRateLimitModule(rateLimitHandler, rateLimitAdminHandler, concurrencyLimitHandler, quotaHandler, policyHandler, overrideHandler, tierHandler, accessHandler)
 	swaggerConfig := swagger.ProvideSwaggerConfig()
 	swaggerLoader, err := swagger.ProvideSwaggerLoader(logger, swaggerConfig)
 	if err != nil {
-		return nil, err
+		cleanup2()
+		cleanup()
+		return nil, nil, err
 	}
 	swaggerQueryHandler := swagger.ProvideSwaggerQueryHandler(logger, swaggerLoader)
 	docsHandler := swagger.ProvideDocsHandler(logger, swaggerQueryHandler)
 	swaggerModule := ProvideSwaggerModule(docsHandler)
 	application := ProvideApplication(config, logger, server, probesModule, rateLimitModule, swaggerModule)
-	return application, nil
+	return application, func() {
+		cleanup2()
+		cleanup()
+	}, nil
 }
 
 // wire.go:
@@ -84,7 +161,14 @@ type ProbesModule struct {
 
 // RateLimitModule holds all rate limit-related dependencies
 type RateLimitModule struct {
-	RateLimitHandler *http.RateLimitHandler
+	RateLimitHandler        *http.RateLimitHandler
+	RateLimitAdminHandler   *http.RateLimitAdminHandler
+	ConcurrencyLimitHandler *http.ConcurrencyLimitHandler
+	QuotaHandler            *http.QuotaHandler
+	PolicyHandler           *http.PolicyHandler
+	OverrideHandler         *http.OverrideHandler
+	TierHandler             *http.TierHandler
+	AccessHandler           *http.AccessHandler
 }
 
 // SwaggerModule holds all swagger-related dependencies
@@ -106,9 +190,23 @@ func ProvideProbesModule(
 // ProvideRateLimitModule provides the rate limit module
 func ProvideRateLimitModule(
 	rateLimitHandler *http.RateLimitHandler,
+	rateLimitAdminHandler *http.RateLimitAdminHandler,
+	concurrencyLimitHandler *http.ConcurrencyLimitHandler,
+	quotaHandler *http.QuotaHandler,
+	policyHandler *http.PolicyHandler,
+	overrideHandler *http.OverrideHandler,
+	tierHandler *http.TierHandler,
+	accessHandler *http.AccessHandler,
 ) *RateLimitModule {
 	return &RateLimitModule{
-		RateLimitHandler: rateLimitHandler,
+		RateLimitHandler:        rateLimitHandler,
+		RateLimitAdminHandler:   rateLimitAdminHandler,
+		ConcurrencyLimitHandler: concurrencyLimitHandler,
+		QuotaHandler:            quotaHandler,
+		PolicyHandler:           policyHandler,
+		OverrideHandler:         overrideHandler,
+		TierHandler:             tierHandler,
+		AccessHandler:           accessHandler,
 	}
 }
 
@@ -0,0 +1,105 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// AcquireLeaseCommand represents a command to take one of a user's concurrent request slots
+type AcquireLeaseCommand struct {
+	UserID string
+	Limit  int           // Maximum leases the user may hold at once, defaults to the configured maximum
+	TTL    time.Duration // Time after which the lease is released automatically, defaults to the configured lease TTL
+}
+
+// AcquireLeaseResponse represents the outcome of acquiring a lease
+type AcquireLeaseResponse struct {
+	Acquired   bool
+	LeaseID    string
+	Limit      int
+	InFlight   int
+	Remaining  int
+	ExpiresIn  time.Duration
+	RetryAfter time.Duration
+}
+
+// AcquireLeaseCommandHandler handles lease acquisition commands
+type AcquireLeaseCommandHandler struct {
+	logger       logger.Logger
+	repository   ports.ConcurrencyLimitRepository
+	defaultLimit int
+	defaultTTL   time.Duration
+}
+
+// NewAcquireLeaseCommandHandler creates a new AcquireLeaseCommandHandler
+func NewAcquireLeaseCommandHandler(
+	logger logger.Logger,
+	repository ports.ConcurrencyLimitRepository,
+	cfg *config.Config,
+) *AcquireLeaseCommandHandler {
+	defaultTTL := cfg.RateLimit.LeaseTTL
+	if defaultTTL <= 0 {
+		defaultTTL = domain.DefaultLeaseTTL
+	}
+	return &AcquireLeaseCommandHandler{
+		logger:       logger,
+		repository:   repository,
+		defaultLimit: cfg.RateLimit.MaxConcurrent,
+		defaultTTL:   defaultTTL,
+	}
+}
+
+// Handle processes the AcquireLeaseCommand
+func (h *AcquireLeaseCommandHandler) Handle(ctx context.Context, cmd AcquireLeaseCommand) (*AcquireLeaseResponse, error) {
+	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", cmd.Limit).Dur("ttl", cmd.TTL).Msg("Processing lease acquisition")
+
+	if cmd.UserID == "" {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	// Fall back to the configured maximum when no limit is provided
+	if cmd.Limit == 0 {
+		cmd.Limit = h.defaultLimit
+	}
+
+	if cmd.Limit <= 0 {
+		h.logger.Error().Int("limit", cmd.Limit).Msg("Invalid limit provided")
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	if cmd.TTL == 0 {
+		cmd.TTL = h.defaultTTL
+	}
+
+	if cmd.TTL < domain.MinWindow {
+		h.logger.Error().Dur("ttl", cmd.TTL).Msg("Invalid TTL provided")
+		return nil, fmt.Errorf("ttl must be at least %s", domain.MinWindow)
+	}
+
+	result, err := h.repository.Acquire(cmd.UserID, cmd.Limit, cmd.TTL)
+	if err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Failed to acquire lease")
+		return nil, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	response := &AcquireLeaseResponse{
+		Acquired:   result.Acquired,
+		LeaseID:    result.LeaseID,
+		Limit:      result.Limit,
+		InFlight:   result.InFlight,
+		Remaining:  result.Remaining(),
+		ExpiresIn:  result.ExpiresIn,
+		RetryAfter: result.RetryAfter,
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", response.Limit).Int("in_flight", response.InFlight).Bool("acquired", response.Acquired).Msg("Lease acquisition completed")
+
+	return response, nil
+}
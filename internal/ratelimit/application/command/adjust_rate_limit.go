@@ -0,0 +1,114 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// AdjustRateLimitCommand represents a command to overwrite a user's count and/or window expiry
+type AdjustRateLimitCommand struct {
+	UserID     string
+	Window     time.Duration
+	Count      *int             // New request count, nil keeps the current count
+	ResetAfter time.Duration    // New time until the window resets, zero keeps the current expiry
+	Algorithm  domain.Algorithm // Algorithm whose window is adjusted, defaults to the fixed window
+	// Further dimensions the counter is kept by, such as an IP address or route, alongside the user if any
+	Descriptors domain.Descriptors
+	Level       domain.Level // Hierarchy level whose counter is adjusted, keyed by the descriptors of that level
+}
+
+// AdjustRateLimitResponse represents the state of the window after an adjustment
+type AdjustRateLimitResponse struct {
+	Count     int
+	ResetTime time.Duration
+	Window    time.Duration
+}
+
+// AdjustRateLimitCommandHandler handles rate limit adjustment commands
+type AdjustRateLimitCommandHandler struct {
+	logger             logger.Logger
+	repositoryProvider ports.RateLimitRepositoryProvider
+}
+
+// NewAdjustRateLimitCommandHandler creates a new AdjustRateLimitCommandHandler
+func NewAdjustRateLimitCommandHandler(
+	logger logger.Logger,
+	repositoryProvider ports.RateLimitRepositoryProvider,
+) *AdjustRateLimitCommandHandler {
+	return &AdjustRateLimitCommandHandler{
+		logger:             logger,
+		repositoryProvider: repositoryProvider,
+	}
+}
+
+// Handle processes the AdjustRateLimitCommand
+func (h *AdjustRateLimitCommandHandler) Handle(ctx context.Context, cmd AdjustRateLimitCommand) (*AdjustRateLimitResponse, error) {
+	h.logger.Info().Str("user_id", cmd.UserID).Dur("window", cmd.Window).Dur("reset_after", cmd.ResetAfter).Str("algorithm", string(cmd.Algorithm)).Str("level", string(cmd.Level)).Msg("Processing rate limit adjustment")
+
+	if cmd.UserID == "" && len(cmd.Descriptors) == 0 {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
+		return nil, fmt.Errorf("user ID cannot be empty without descriptors")
+	}
+
+	key, err := adminSubjectKey(h.logger, cmd.UserID, cmd.Descriptors, cmd.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Algorithm == "" {
+		cmd.Algorithm = domain.DefaultAlgorithm
+	}
+
+	if cmd.Window == 0 {
+		cmd.Window = domain.DefaultWindow
+	}
+
+	if cmd.Window < domain.MinWindow {
+		h.logger.Error().Dur("window", cmd.Window).Msg("Invalid window provided")
+		return nil, fmt.Errorf("window must be at least %s", domain.MinWindow)
+	}
+
+	if cmd.Count == nil && cmd.ResetAfter == 0 {
+		h.logger.Error().Msg("Empty adjustment provided")
+		return nil, fmt.Errorf("count or reset after must be provided")
+	}
+
+	if cmd.Count != nil && *cmd.Count < 0 {
+		h.logger.Error().Int("count", *cmd.Count).Msg("Invalid count provided")
+		return nil, fmt.Errorf("count cannot be negative")
+	}
+
+	if cmd.ResetAfter < 0 || (cmd.ResetAfter > 0 && cmd.ResetAfter < domain.MinWindow) {
+		h.logger.Error().Dur("reset_after", cmd.ResetAfter).Msg("Invalid reset after provided")
+		return nil, fmt.Errorf("reset after must be at least %s", domain.MinWindow)
+	}
+
+	repository, err := h.repositoryProvider.AdminRepository(cmd.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := repository.Adjust(key, cmd.Window, domain.Adjustment{
+		Count:      cmd.Count,
+		ResetAfter: cmd.ResetAfter,
+	})
+	if err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Failed to adjust rate limit")
+		return nil, fmt.Errorf("failed to adjust rate limit: %w", err)
+	}
+
+	response := &AdjustRateLimitResponse{
+		Count:     state.Count,
+		ResetTime: state.ResetAfter,
+		Window:    cmd.Window,
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Dur("window", cmd.Window).Int("count", response.Count).Dur("reset_time", response.ResetTime).Msg("Rate limit adjustment completed")
+
+	return response, nil
+}
@@ -0,0 +1,58 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// AssignTierCommand represents a command to assign a user to a tier, replacing the user's current tier
+type AssignTierCommand struct {
+	UserID string
+	Tier   string // Name of the policy holding the tier's limits
+}
+
+// AssignTierCommandHandler handles tier assignment commands
+type AssignTierCommandHandler struct {
+	logger     logger.Logger
+	repository ports.TierRepository
+}
+
+// NewAssignTierCommandHandler creates a new AssignTierCommandHandler
+func NewAssignTierCommandHandler(
+	logger logger.Logger,
+	repository ports.TierRepository,
+) *AssignTierCommandHandler {
+	return &AssignTierCommandHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle processes the AssignTierCommand
+func (h *AssignTierCommandHandler) Handle(ctx context.Context, cmd AssignTierCommand) (*domain.TierAssignment, error) {
+	h.logger.Info().Str("user_id", cmd.UserID).Str("tier", cmd.Tier).Msg("Processing tier assignment")
+
+	if cmd.UserID == "" {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if cmd.Tier == "" {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid tier provided")
+		return nil, fmt.Errorf("tier cannot be empty")
+	}
+
+	assignment := &domain.TierAssignment{UserID: cmd.UserID, Tier: cmd.Tier}
+	if err := h.repository.Assign(ctx, assignment); err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Str("tier", cmd.Tier).Err(err).Msg("Failed to assign tier")
+		return nil, fmt.Errorf("failed to assign tier %q: %w", cmd.Tier, err)
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Str("tier", cmd.Tier).Msg("Tier assignment completed")
+
+	return assignment, nil
+}
@@ -3,7 +3,9 @@ package command
 import (
 	"context"
 	"fmt"
+	"time"
 	
+	"github.com/go-clean/internal/ratelimit/domain"
 	"github.com/go-clean/internal/ratelimit/ports"
 	"github.com/go-clean/platform/logger"
 )
@@ -12,6 +14,7 @@ import (
 type CheckRateLimitCommand struct {
 	UserID string
 	Limit  int
+	Window time.Duration
 }
 
 // CheckRateLimitCommandHandler handles rate limit checking commands
@@ -45,7 +48,16 @@ func (h *CheckRateLimitCommandHandler) Handle(ctx context.Context, cmd CheckRate
 		return false, fmt.Errorf("limit must be greater than 0")
 	}
 	
-	allowed := h.repository.RateLimit(cmd.UserID, cmd.Limit)
+	if cmd.Window == 0 {
+		cmd.Window = domain.DefaultWindow
+	}
+	
+	if cmd.Window < domain.MinWindow {
+		h.logger.Error().Dur("window", cmd.Window).Msg("Invalid window provided")
+		return false, fmt.Errorf("window must be at least %s", domain.MinWindow)
+	}
+	
+	allowed := h.repository.RateLimit(domain.UserKey(cmd.UserID), cmd.Limit, cmd.Window)
 	
 	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", cmd.Limit).Bool("allowed", allowed).Msg("Rate limit check completed")
 	
@@ -5,69 +5,213 @@ import (
 	"fmt"
 	"time"
 	
+	"github.com/go-clean/internal/ratelimit/application/limits"
+	"github.com/go-clean/internal/ratelimit/domain"
 	"github.com/go-clean/internal/ratelimit/ports"
 	"github.com/go-clean/platform/logger"
 )
 
 // CheckRateLimitWithDetailCommand represents a command to check rate limit with detailed response
 type CheckRateLimitWithDetailCommand struct {
-	UserID string
-	Limit  int
+	UserID    string
+	Limit     int
+	Window    time.Duration
+	Rules     []domain.Rule // Limits checked together, overriding Limit and Window when set
+	Cost      int           // Units the request consumes from every limit, defaults to 1
+	Algorithm domain.Algorithm
+	Policy    string // Name of a stored policy supplying the limit, window, algorithm and burst instead
+	// Further dimensions the request is counted by, such as its IP address or route, alongside the user if any.
+	// The route and tenant also select the policy declared for them in the policy file.
+	Descriptors domain.Descriptors
+	// Limits of the hierarchy levels the request consumes from together, instead of its own limits
+	Hierarchy map[domain.Level]domain.Rule
 }
 
 // CheckRateLimitWithDetailResponse represents the detailed response from rate limit check
 type CheckRateLimitWithDetailResponse struct {
-	Remaining int
-	ResetTime time.Duration
-	Allowed   bool
+	Limit       int
+	Remaining   int
+	ResetTime   time.Duration
+	RetryAfter  time.Duration
+	Allowed     bool
+	Window      time.Duration
+	Cost        int
+	Algorithm   domain.Algorithm
+	Policy      string
+	Overridden  bool              // Whether the user's override replaced the requested limits
+	Results     []RuleResult      // One result per checked limit, in the order they were given
+	Binding     int               // Index of the limit that constrains the request the most
+	DeniedBy    domain.Level      // Level of the hierarchy whose limit denied the request, if any
+	Access      domain.AccessList // List that decided the request without counting it against any limit, if any
+	AccessRule  int64             // ID of the access rule that decided the request
+	WouldDeny   bool              // Whether a shadow policy admitted the request although its limit was exceeded
+	Approximate bool              // Whether the counts were taken locally and may be off by the tolerated overshoot
+}
+
+// RuleResult represents the outcome of a single limit within a compound rate limit check
+type RuleResult struct {
+	Limit      int
+	Window     time.Duration
+	Remaining  int
+	ResetTime  time.Duration
+	RetryAfter time.Duration
+	Allowed    bool
+	Level      domain.Level // Level of the hierarchy the limit belongs to, if any
+	WindowID   int64        // Window the request was counted in, which a refund names; zero for algorithms without windows
 }
 
 // CheckRateLimitWithDetailCommandHandler handles rate limit checking commands with detailed response
 type CheckRateLimitWithDetailCommandHandler struct {
-	logger     logger.Logger
-	repository ports.RateLimitRepository
+	logger             logger.Logger
+	repositoryProvider ports.RateLimitRepositoryProvider
+	limits             *limits.Resolver
+	access             ports.AccessRuleRepository
+	shadow             ports.ShadowLog
 }
 
 // NewCheckRateLimitWithDetailCommandHandler creates a new CheckRateLimitWithDetailCommandHandler
 func NewCheckRateLimitWithDetailCommandHandler(
 	logger logger.Logger,
-	repository ports.RateLimitRepository,
+	repositoryProvider ports.RateLimitRepositoryProvider,
+	resolver *limits.Resolver,
+	access ports.AccessRuleRepository,
+	shadow ports.ShadowLog,
 ) *CheckRateLimitWithDetailCommandHandler {
 	return &CheckRateLimitWithDetailCommandHandler{
-		logger:     logger,
-		repository: repository,
+		logger:             logger,
+		repositoryProvider: repositoryProvider,
+		limits:             resolver,
+		access:             access,
+		shadow:             shadow,
 	}
 }
 
 // Handle processes the CheckRateLimitWithDetailCommand
 func (h *CheckRateLimitWithDetailCommandHandler) Handle(ctx context.Context, cmd CheckRateLimitWithDetailCommand) (*CheckRateLimitWithDetailResponse, error) {
-	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", cmd.Limit).Msg("Processing rate limit check with detail")
+	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", cmd.Limit).Dur("window", cmd.Window).Int("cost", cmd.Cost).Str("algorithm", string(cmd.Algorithm)).Str("policy", cmd.Policy).Msg("Processing rate limit check with detail")
 	
-	if cmd.UserID == "" {
+	if cmd.UserID == "" && len(cmd.Descriptors) == 0 {
 		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
-		return nil, fmt.Errorf("user ID cannot be empty")
+		return nil, fmt.Errorf("user ID cannot be empty without descriptors")
+	}
+	
+	key, err := limits.SubjectKey(h.logger, cmd.UserID, cmd.Descriptors)
+	if err != nil {
+		return nil, err
+	}
+	
+	if len(cmd.Hierarchy) > 0 {
+		if cmd.Policy != "" || cmd.Limit != 0 || cmd.Window != 0 || len(cmd.Rules) > 0 {
+			h.logger.Error().Str("user_id", cmd.UserID).Msg("Hierarchy combined with own limits")
+			return nil, fmt.Errorf("hierarchy cannot be combined with limit, window, limits or policy")
+		}
+		cmd.Rules, err = limits.HierarchyRules(h.logger, cmd.UserID, cmd.Descriptors, cmd.Hierarchy)
+		if err != nil {
+			return nil, err
+		}
 	}
 	
-	if cmd.Limit <= 0 {
-		h.logger.Error().Int("limit", cmd.Limit).Msg("Invalid limit provided")
-		return nil, fmt.Errorf("limit must be greater than 0")
+	if cmd.Cost == 0 {
+		cmd.Cost = 1
 	}
 	
-	remaining, resetTime, err := h.repository.RateLimitWithDetail(cmd.UserID, cmd.Limit)
+	if cmd.Cost < 0 {
+		h.logger.Error().Int("cost", cmd.Cost).Msg("Invalid cost provided")
+		return nil, fmt.Errorf("cost must be greater than 0")
+	}
+	
+	// Listed subjects are decided before any limit is loaded or counted
+	if rule := accessDecision(ctx, h.logger, h.access, cmd.UserID, cmd.Descriptors); rule != nil {
+		h.logger.Info().Str("user_id", cmd.UserID).Int64("rule_id", rule.ID).Str("access", string(rule.List)).Msg("Rate limit check decided by access list")
+		return &CheckRateLimitWithDetailResponse{Allowed: rule.List == domain.AccessAllow, Cost: cmd.Cost, Access: rule.List, AccessRule: rule.ID}, nil
+	}
+	
+	// The limits come from the request, a declared or stored policy, the user's tier and the user's override
+	resolved, err := h.limits.Resolve(ctx, limits.Request{
+		UserID:      cmd.UserID,
+		Limit:       cmd.Limit,
+		Window:      cmd.Window,
+		Rules:       cmd.Rules,
+		Cost:        cmd.Cost,
+		Algorithm:   cmd.Algorithm,
+		Policy:      cmd.Policy,
+		Descriptors: cmd.Descriptors,
+	})
+	if err != nil {
+		return nil, err
+	}
+	policy, override := resolved.Policy, resolved.Override
+	cmd.Rules, cmd.Algorithm, cmd.Policy = resolved.Rules, resolved.Algorithm, ""
+	if policy != nil {
+		cmd.Policy = policy.Name
+	}
+	
+	repository, err := h.repositoryProvider.Repository(cmd.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	
+	compound, err := repository.RateLimitAllWithDetail(key, cmd.Rules, cmd.Cost)
 	if err != nil {
 		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Failed to check rate limit with detail")
 		return nil, fmt.Errorf("failed to check rate limit: %w", err)
 	}
 	
-	allowed := remaining > 0
+	results := make([]RuleResult, len(compound.Results))
+	for i, result := range compound.Results {
+		results[i] = RuleResult{
+			Limit:      result.Limit,
+			Window:     cmd.Rules[i].Window,
+			Remaining:  result.Remaining,
+			ResetTime:  result.ResetAfter,
+			RetryAfter: result.RetryAfter,
+			Allowed:    result.Allowed,
+			Level:      cmd.Rules[i].Level,
+			WindowID:   result.WindowID,
+		}
+	}
 	
+	binding := results[compound.BindingIndex]
 	response := &CheckRateLimitWithDetailResponse{
-		Remaining: remaining,
-		ResetTime: resetTime,
-		Allowed:   allowed,
+		Limit:       binding.Limit,
+		Remaining:   binding.Remaining,
+		ResetTime:   compound.ResetAfter,
+		RetryAfter:  binding.RetryAfter,
+		Allowed:     compound.Allowed,
+		Window:      binding.Window,
+		Cost:        cmd.Cost,
+		Algorithm:   cmd.Algorithm,
+		Policy:      cmd.Policy,
+		Overridden:  override != nil,
+		Results:     results,
+		Binding:     compound.BindingIndex,
+		Approximate: compound.Approximate,
+	}
+	if !response.Allowed {
+		response.DeniedBy = binding.Level
 	}
 	
-	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", cmd.Limit).Int("remaining", remaining).Dur("reset_time", resetTime).Bool("allowed", allowed).Msg("Rate limit check with detail completed")
+	// A shadow policy is counted like any other, but only records the requests it would deny
+	if !response.Allowed && policy != nil && policy.Mode == domain.PolicyShadow {
+		h.logger.Warn().Str("user_id", cmd.UserID).Str("policy", policy.Name).Str("subject", key).Msg("Request would be denied by shadow policy")
+		if err := h.shadow.Record(ctx, policy.Name, key); err != nil {
+			h.logger.Error().Str("policy", policy.Name).Str("subject", key).Err(err).Msg("Failed to record shadow denial")
+		}
+		response.Allowed, response.WouldDeny = true, true
+	}
+	
+	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", response.Limit).Dur("window", response.Window).Int("binding_limit", response.Binding).Int("remaining", response.Remaining).Dur("reset_time", response.ResetTime).Bool("allowed", response.Allowed).Bool("would_deny", response.WouldDeny).Str("denied_by", string(response.DeniedBy)).Msg("Rate limit check with detail completed")
 	
 	return response, nil
-}
\ No newline at end of file
+}
+
+// accessDecision returns the access rule deciding the request, or nil when it is rate limited as usual.
+// Access lists are best effort like overrides: when they cannot be loaded the request is rate limited rather than failed.
+func accessDecision(ctx context.Context, logger logger.Logger, access ports.AccessRuleRepository, userId string, descriptors domain.Descriptors) *domain.AccessRule {
+	rule, err := access.Decide(ctx, domain.NewAccessSubject(userId, descriptors.Canonical()))
+	if err != nil {
+		logger.Error().Str("user_id", userId).Err(err).Msg("Failed to load access rules, rate limiting the request")
+		return nil
+	}
+	return rule
+}
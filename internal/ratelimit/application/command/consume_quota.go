@@ -0,0 +1,134 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// ConsumeQuotaCommand represents a command to count a request against a calendar-aligned quota
+type ConsumeQuotaCommand struct {
+	UserID   string
+	Limit    int
+	Period   domain.Period
+	TimeZone string // IANA time zone the period is aligned to, defaults to the configured quota time zone
+	Cost     int    // Units the request consumes, defaults to 1
+}
+
+// ConsumeQuotaResponse represents the outcome of a quota check
+type ConsumeQuotaResponse struct {
+	Allowed     bool
+	Limit       int
+	Used        int
+	Remaining   int
+	Period      domain.Period
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	ResetTime   time.Duration
+	RetryAfter  time.Duration
+	Cost        int
+}
+
+// ConsumeQuotaCommandHandler handles quota commands
+type ConsumeQuotaCommandHandler struct {
+	logger          logger.Logger
+	repository      ports.QuotaRepository
+	defaultLocation *time.Location
+}
+
+// NewConsumeQuotaCommandHandler creates a new ConsumeQuotaCommandHandler
+func NewConsumeQuotaCommandHandler(
+	logger logger.Logger,
+	repository ports.QuotaRepository,
+	cfg *config.Config,
+) (*ConsumeQuotaCommandHandler, error) {
+	location, err := time.LoadLocation(cfg.RateLimit.QuotaTimeZone)
+	if err != nil {
+		logger.Error().Str("time_zone", cfg.RateLimit.QuotaTimeZone).Err(err).Msg("Invalid quota time zone configured")
+		return nil, fmt.Errorf("invalid quota time zone: %w", err)
+	}
+
+	return &ConsumeQuotaCommandHandler{
+		logger:          logger,
+		repository:      repository,
+		defaultLocation: location,
+	}, nil
+}
+
+// Handle processes the ConsumeQuotaCommand
+func (h *ConsumeQuotaCommandHandler) Handle(ctx context.Context, cmd ConsumeQuotaCommand) (*ConsumeQuotaResponse, error) {
+	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", cmd.Limit).Str("period", string(cmd.Period)).Str("time_zone", cmd.TimeZone).Int("cost", cmd.Cost).Msg("Processing quota check")
+
+	if cmd.UserID == "" {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if cmd.Limit <= 0 {
+		h.logger.Error().Int("limit", cmd.Limit).Msg("Invalid limit provided")
+		return nil, fmt.Errorf("limit must be greater than 0")
+	}
+
+	if cmd.Period == "" {
+		cmd.Period = domain.DefaultPeriod
+	}
+
+	if !cmd.Period.IsValid() {
+		h.logger.Error().Str("period", string(cmd.Period)).Msg("Invalid period provided")
+		return nil, fmt.Errorf("unsupported quota period: %s", cmd.Period)
+	}
+
+	location := h.defaultLocation
+	if cmd.TimeZone != "" {
+		loaded, err := time.LoadLocation(cmd.TimeZone)
+		if err != nil {
+			h.logger.Error().Str("time_zone", cmd.TimeZone).Msg("Invalid time zone provided")
+			return nil, fmt.Errorf("unknown time zone: %s", cmd.TimeZone)
+		}
+		location = loaded
+	}
+
+	if cmd.Cost == 0 {
+		cmd.Cost = 1
+	}
+
+	if cmd.Cost < 0 {
+		h.logger.Error().Int("cost", cmd.Cost).Msg("Invalid cost provided")
+		return nil, fmt.Errorf("cost must be greater than 0")
+	}
+
+	// A request costing more than the limit could never be admitted
+	if cmd.Cost > cmd.Limit {
+		h.logger.Error().Int("cost", cmd.Cost).Int("limit", cmd.Limit).Msg("Cost exceeds limit")
+		return nil, fmt.Errorf("cost %d exceeds limit %d", cmd.Cost, cmd.Limit)
+	}
+
+	quota := domain.Quota{Limit: cmd.Limit, Period: cmd.Period, Location: location}
+	result, err := h.repository.Consume(cmd.UserID, quota, cmd.Cost)
+	if err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Failed to check quota")
+		return nil, fmt.Errorf("failed to check quota: %w", err)
+	}
+
+	response := &ConsumeQuotaResponse{
+		Allowed:     result.Allowed,
+		Limit:       result.Limit,
+		Used:        result.Used,
+		Remaining:   result.Remaining,
+		Period:      cmd.Period,
+		PeriodStart: result.PeriodStart,
+		PeriodEnd:   result.PeriodEnd,
+		ResetTime:   result.ResetAfter,
+		RetryAfter:  result.RetryAfter,
+		Cost:        cmd.Cost,
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", response.Limit).Int("used", response.Used).Int("remaining", response.Remaining).Bool("allowed", response.Allowed).Msg("Quota check completed")
+
+	return response, nil
+}
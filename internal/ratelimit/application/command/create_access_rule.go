@@ -0,0 +1,61 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// CreateAccessRuleCommand represents a command to put matching requests on the allow or deny list
+type CreateAccessRuleCommand struct {
+	List   domain.AccessList
+	Match  domain.AccessMatch
+	Value  string // User ID or API key, its prefix, or a CIDR, depending on Match
+	Reason string
+	Actor  string // Who made the change, recorded in the audit trail
+}
+
+// CreateAccessRuleCommandHandler handles access rule creation commands
+type CreateAccessRuleCommandHandler struct {
+	logger     logger.Logger
+	repository ports.AccessRuleRepository
+}
+
+// NewCreateAccessRuleCommandHandler creates a new CreateAccessRuleCommandHandler
+func NewCreateAccessRuleCommandHandler(
+	logger logger.Logger,
+	repository ports.AccessRuleRepository,
+) *CreateAccessRuleCommandHandler {
+	return &CreateAccessRuleCommandHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle processes the CreateAccessRuleCommand
+func (h *CreateAccessRuleCommandHandler) Handle(ctx context.Context, cmd CreateAccessRuleCommand) (*domain.AccessRule, error) {
+	h.logger.Info().Str("list", string(cmd.List)).Str("match", string(cmd.Match)).Str("value", cmd.Value).Str("actor", cmd.Actor).Msg("Processing access rule creation")
+
+	rule := &domain.AccessRule{
+		List:   cmd.List,
+		Match:  cmd.Match,
+		Value:  cmd.Value,
+		Reason: cmd.Reason,
+	}
+	if err := rule.Validate(); err != nil {
+		h.logger.Error().Str("value", cmd.Value).Err(err).Msg("Invalid access rule provided")
+		return nil, err
+	}
+
+	if err := h.repository.Create(ctx, rule, cmd.Actor); err != nil {
+		h.logger.Error().Str("value", rule.Value).Err(err).Msg("Failed to create access rule")
+		return nil, fmt.Errorf("failed to create access rule: %w", err)
+	}
+
+	h.logger.Info().Int64("rule_id", rule.ID).Str("list", string(rule.List)).Msg("Access rule creation completed")
+
+	return rule, nil
+}
@@ -0,0 +1,79 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// CreatePolicyCommand represents a command to store a new named rate limit policy
+type CreatePolicyCommand struct {
+	Name      string
+	Limit     int
+	Window    time.Duration // Defaults to domain.DefaultWindow
+	Algorithm domain.Algorithm
+	Burst     int
+	Mode      domain.PolicyMode // Defaults to domain.DefaultPolicyMode
+	Schedules []domain.Schedule // Time ranges replacing the limit, the first active one wins
+}
+
+// CreatePolicyCommandHandler handles policy creation commands
+type CreatePolicyCommandHandler struct {
+	logger     logger.Logger
+	repository ports.PolicyRepository
+}
+
+// NewCreatePolicyCommandHandler creates a new CreatePolicyCommandHandler
+func NewCreatePolicyCommandHandler(
+	logger logger.Logger,
+	repository ports.PolicyRepository,
+) *CreatePolicyCommandHandler {
+	return &CreatePolicyCommandHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle processes the CreatePolicyCommand
+func (h *CreatePolicyCommandHandler) Handle(ctx context.Context, cmd CreatePolicyCommand) (*domain.Policy, error) {
+	h.logger.Info().Str("policy", cmd.Name).Int("limit", cmd.Limit).Dur("window", cmd.Window).Str("algorithm", string(cmd.Algorithm)).Int("burst", cmd.Burst).Str("mode", string(cmd.Mode)).Int("schedules", len(cmd.Schedules)).Msg("Processing policy creation")
+
+	if cmd.Window == 0 {
+		cmd.Window = domain.DefaultWindow
+	}
+
+	if cmd.Algorithm == "" {
+		cmd.Algorithm = domain.DefaultAlgorithm
+	}
+
+	if cmd.Mode == "" {
+		cmd.Mode = domain.DefaultPolicyMode
+	}
+
+	policy := &domain.Policy{
+		Name:      cmd.Name,
+		Limit:     cmd.Limit,
+		Window:    cmd.Window,
+		Algorithm: cmd.Algorithm,
+		Burst:     cmd.Burst,
+		Mode:      cmd.Mode,
+		Schedules: cmd.Schedules,
+	}
+	if err := policy.Validate(); err != nil {
+		h.logger.Error().Str("policy", cmd.Name).Err(err).Msg("Invalid policy provided")
+		return nil, err
+	}
+
+	if err := h.repository.Create(ctx, policy); err != nil {
+		h.logger.Error().Str("policy", cmd.Name).Err(err).Msg("Failed to create policy")
+		return nil, fmt.Errorf("failed to create policy: %w", err)
+	}
+
+	h.logger.Info().Str("policy", policy.Name).Msg("Policy creation completed")
+
+	return policy, nil
+}
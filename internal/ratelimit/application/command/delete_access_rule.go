@@ -0,0 +1,46 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// DeleteAccessRuleCommand represents a command to take a rule off its list
+type DeleteAccessRuleCommand struct {
+	ID    int64
+	Actor string // Who made the change, recorded in the audit trail
+}
+
+// DeleteAccessRuleCommandHandler handles access rule deletion commands
+type DeleteAccessRuleCommandHandler struct {
+	logger     logger.Logger
+	repository ports.AccessRuleRepository
+}
+
+// NewDeleteAccessRuleCommandHandler creates a new DeleteAccessRuleCommandHandler
+func NewDeleteAccessRuleCommandHandler(
+	logger logger.Logger,
+	repository ports.AccessRuleRepository,
+) *DeleteAccessRuleCommandHandler {
+	return &DeleteAccessRuleCommandHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle processes the DeleteAccessRuleCommand
+func (h *DeleteAccessRuleCommandHandler) Handle(ctx context.Context, cmd DeleteAccessRuleCommand) error {
+	h.logger.Info().Int64("rule_id", cmd.ID).Str("actor", cmd.Actor).Msg("Processing access rule deletion")
+
+	if err := h.repository.Delete(ctx, cmd.ID, cmd.Actor); err != nil {
+		h.logger.Error().Int64("rule_id", cmd.ID).Err(err).Msg("Failed to delete access rule")
+		return fmt.Errorf("failed to delete access rule: %w", err)
+	}
+
+	h.logger.Info().Int64("rule_id", cmd.ID).Msg("Access rule deletion completed")
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// DeleteOverrideCommand represents a command to remove the override of a user
+type DeleteOverrideCommand struct {
+	UserID string
+}
+
+// DeleteOverrideCommandHandler handles override deletion commands
+type DeleteOverrideCommandHandler struct {
+	logger     logger.Logger
+	repository ports.OverrideRepository
+}
+
+// NewDeleteOverrideCommandHandler creates a new DeleteOverrideCommandHandler
+func NewDeleteOverrideCommandHandler(
+	logger logger.Logger,
+	repository ports.OverrideRepository,
+) *DeleteOverrideCommandHandler {
+	return &DeleteOverrideCommandHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle processes the DeleteOverrideCommand
+func (h *DeleteOverrideCommandHandler) Handle(ctx context.Context, cmd DeleteOverrideCommand) error {
+	h.logger.Info().Str("user_id", cmd.UserID).Msg("Processing override deletion")
+
+	if cmd.UserID == "" {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if err := h.repository.Delete(ctx, cmd.UserID); err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Failed to delete override")
+		return fmt.Errorf("failed to delete override: %w", err)
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Msg("Override deletion completed")
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// DeletePolicyCommand represents a command to remove a rate limit policy
+type DeletePolicyCommand struct {
+	Name string
+}
+
+// DeletePolicyCommandHandler handles policy deletion commands
+type DeletePolicyCommandHandler struct {
+	logger     logger.Logger
+	repository ports.PolicyRepository
+}
+
+// NewDeletePolicyCommandHandler creates a new DeletePolicyCommandHandler
+func NewDeletePolicyCommandHandler(
+	logger logger.Logger,
+	repository ports.PolicyRepository,
+) *DeletePolicyCommandHandler {
+	return &DeletePolicyCommandHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle processes the DeletePolicyCommand
+func (h *DeletePolicyCommandHandler) Handle(ctx context.Context, cmd DeletePolicyCommand) error {
+	h.logger.Info().Str("policy", cmd.Name).Msg("Processing policy deletion")
+
+	if cmd.Name == "" {
+		h.logger.Error().Msg("Invalid policy name provided")
+		return fmt.Errorf("policy name cannot be empty")
+	}
+
+	if err := h.repository.Delete(ctx, cmd.Name); err != nil {
+		h.logger.Error().Str("policy", cmd.Name).Err(err).Msg("Failed to delete policy")
+		return fmt.Errorf("failed to delete policy: %w", err)
+	}
+
+	h.logger.Info().Str("policy", cmd.Name).Msg("Policy deletion completed")
+
+	return nil
+}
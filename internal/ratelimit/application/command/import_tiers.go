@@ -0,0 +1,90 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// ImportTiersCommand represents a command to assign many users to their tiers at once
+type ImportTiersCommand struct {
+	Assignments []domain.TierAssignment
+}
+
+// ImportTiersResponse represents the outcome of a tier import
+type ImportTiersResponse struct {
+	Imported int
+	Tiers    map[string]int // Number of users assigned to each tier
+}
+
+// ImportTiersCommandHandler handles tier import commands
+type ImportTiersCommandHandler struct {
+	logger     logger.Logger
+	repository ports.TierRepository
+	policies   ports.PolicyRepository
+}
+
+// NewImportTiersCommandHandler creates a new ImportTiersCommandHandler
+func NewImportTiersCommandHandler(
+	logger logger.Logger,
+	repository ports.TierRepository,
+	policies ports.PolicyRepository,
+) *ImportTiersCommandHandler {
+	return &ImportTiersCommandHandler{
+		logger:     logger,
+		repository: repository,
+		policies:   policies,
+	}
+}
+
+// Handle processes the ImportTiersCommand. Either every assignment is stored or none is.
+func (h *ImportTiersCommandHandler) Handle(ctx context.Context, cmd ImportTiersCommand) (*ImportTiersResponse, error) {
+	h.logger.Info().Int("assignments", len(cmd.Assignments)).Msg("Processing tier import")
+
+	if len(cmd.Assignments) == 0 {
+		h.logger.Error().Msg("Empty tier import provided")
+		return nil, fmt.Errorf("no tier assignments to import")
+	}
+
+	tiers := make(map[string]int)
+	users := make(map[string]bool, len(cmd.Assignments))
+	for i, assignment := range cmd.Assignments {
+		if assignment.UserID == "" || assignment.Tier == "" {
+			h.logger.Error().Int("assignment", i).Msg("Invalid tier assignment provided")
+			return nil, fmt.Errorf("assignment %d: user ID and tier cannot be empty", i)
+		}
+		// A user listed twice would end up in whichever tier the database applies last
+		if users[assignment.UserID] {
+			h.logger.Error().Int("assignment", i).Str("user_id", assignment.UserID).Msg("Duplicate user in tier import")
+			return nil, fmt.Errorf("assignment %d: user %s is listed more than once", i, assignment.UserID)
+		}
+		users[assignment.UserID] = true
+		tiers[assignment.Tier]++
+	}
+
+	// Checking each tier up front names the unknown one, which the database rejects without saying which row failed
+	for tier := range tiers {
+		_, err := h.policies.Get(ctx, tier)
+		if errors.Is(err, domain.ErrPolicyNotFound) {
+			h.logger.Error().Str("tier", tier).Msg("Unknown tier in tier import")
+			return nil, fmt.Errorf("%w: %s", domain.ErrUnknownTier, tier)
+		}
+		if err != nil {
+			h.logger.Error().Str("tier", tier).Err(err).Msg("Failed to resolve tier")
+			return nil, fmt.Errorf("failed to resolve tier %q: %w", tier, err)
+		}
+	}
+
+	if err := h.repository.Import(ctx, cmd.Assignments); err != nil {
+		h.logger.Error().Int("assignments", len(cmd.Assignments)).Err(err).Msg("Failed to import tier assignments")
+		return nil, fmt.Errorf("failed to import tier assignments: %w", err)
+	}
+
+	h.logger.Info().Int("imported", len(cmd.Assignments)).Int("tiers", len(tiers)).Msg("Tier import completed")
+
+	return &ImportTiersResponse{Imported: len(cmd.Assignments), Tiers: tiers}, nil
+}
@@ -0,0 +1,77 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// PutOverrideCommand represents a command to set the override of a user, replacing any existing one
+type PutOverrideCommand struct {
+	UserID     string
+	Multiplier float64       // Factor applied to every requested limit, mutually exclusive with Limit
+	Limit      int           // Fixed limit replacing the requested limits, mutually exclusive with Multiplier
+	Window     time.Duration // Window of the fixed limit, defaults to domain.DefaultWindow
+	Reason     string
+	ExpiresAt  *time.Time // Nil when the override never expires
+}
+
+// PutOverrideCommandHandler handles override commands
+type PutOverrideCommandHandler struct {
+	logger     logger.Logger
+	repository ports.OverrideRepository
+	clock      ports.Clock
+}
+
+// NewPutOverrideCommandHandler creates a new PutOverrideCommandHandler
+func NewPutOverrideCommandHandler(
+	logger logger.Logger,
+	repository ports.OverrideRepository,
+	clock ports.Clock,
+) *PutOverrideCommandHandler {
+	return &PutOverrideCommandHandler{
+		logger:     logger,
+		repository: repository,
+		clock:      clock,
+	}
+}
+
+// Handle processes the PutOverrideCommand
+func (h *PutOverrideCommandHandler) Handle(ctx context.Context, cmd PutOverrideCommand) (*domain.Override, error) {
+	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", cmd.Limit).Dur("window", cmd.Window).Str("reason", cmd.Reason).Msg("Processing override update")
+
+	if cmd.Limit > 0 && cmd.Window == 0 {
+		cmd.Window = domain.DefaultWindow
+	}
+
+	override := &domain.Override{
+		UserID:     cmd.UserID,
+		Multiplier: cmd.Multiplier,
+		Limit:      cmd.Limit,
+		Window:     cmd.Window,
+		Reason:     cmd.Reason,
+		ExpiresAt:  cmd.ExpiresAt,
+	}
+	if err := override.Validate(); err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Invalid override provided")
+		return nil, err
+	}
+
+	if !override.Active(h.clock.Now()) {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Override expiring in the past provided")
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+
+	if err := h.repository.Put(ctx, override); err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Failed to store override")
+		return nil, fmt.Errorf("failed to store override: %w", err)
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Msg("Override update completed")
+
+	return override, nil
+}
@@ -0,0 +1,177 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/limits"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// RefundRateLimitCommand represents a command to give back the units of a request that was admitted but never served.
+// The limits and algorithm must match the ones the request was checked against.
+type RefundRateLimitCommand struct {
+	UserID    string
+	Limit     int
+	Window    time.Duration
+	Rules     []domain.Rule // Limits refunded together, overriding Limit and Window when set
+	Cost      int           // Units to give back to every limit, defaults to 1
+	Algorithm domain.Algorithm
+	Policy    string // Name of a stored policy supplying the limit, window, algorithm and burst instead
+	// Further dimensions the request is counted by, such as its IP address or route, alongside the user if any.
+	// The route and tenant also select the policy declared for them in the policy file.
+	Descriptors domain.Descriptors
+	// Limits of the hierarchy levels the request consumes from together, instead of its own limits
+	Hierarchy map[domain.Level]domain.Rule
+	// Window of every limit the request was counted in, as reported by the check and in the same order. Required by
+	// algorithms with windows, so that a refund arriving after a window ended is not taken from the next one.
+	WindowIDs []int64
+}
+
+// RefundRateLimitResponse represents the quota left after a refund
+type RefundRateLimitResponse struct {
+	Limit      int
+	Remaining  int
+	ResetTime  time.Duration
+	Window     time.Duration
+	Cost       int
+	Algorithm  domain.Algorithm
+	Policy     string
+	Results    []RuleResult      // One result per refunded limit, in the order they were given
+	Binding    int               // Index of the limit that constrains the next request the most
+	Access     domain.AccessList // List that decided the request, which was never counted and so is not refunded
+	AccessRule int64             // ID of the access rule that decided the request
+}
+
+// RefundRateLimitCommandHandler handles rate limit refund commands
+type RefundRateLimitCommandHandler struct {
+	logger             logger.Logger
+	repositoryProvider ports.RateLimitRepositoryProvider
+	limits             *limits.Resolver
+	access             ports.AccessRuleRepository
+}
+
+// NewRefundRateLimitCommandHandler creates a new RefundRateLimitCommandHandler
+func NewRefundRateLimitCommandHandler(
+	logger logger.Logger,
+	repositoryProvider ports.RateLimitRepositoryProvider,
+	resolver *limits.Resolver,
+	access ports.AccessRuleRepository,
+) *RefundRateLimitCommandHandler {
+	return &RefundRateLimitCommandHandler{
+		logger:             logger,
+		repositoryProvider: repositoryProvider,
+		limits:             resolver,
+		access:             access,
+	}
+}
+
+// Handle processes the RefundRateLimitCommand
+func (h *RefundRateLimitCommandHandler) Handle(ctx context.Context, cmd RefundRateLimitCommand) (*RefundRateLimitResponse, error) {
+	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", cmd.Limit).Dur("window", cmd.Window).Int("cost", cmd.Cost).Str("algorithm", string(cmd.Algorithm)).Str("policy", cmd.Policy).Msg("Processing rate limit refund")
+
+	if cmd.UserID == "" && len(cmd.Descriptors) == 0 {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
+		return nil, fmt.Errorf("user ID cannot be empty without descriptors")
+	}
+
+	key, err := limits.SubjectKey(h.logger, cmd.UserID, cmd.Descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cmd.Hierarchy) > 0 {
+		if cmd.Policy != "" || cmd.Limit != 0 || cmd.Window != 0 || len(cmd.Rules) > 0 {
+			h.logger.Error().Str("user_id", cmd.UserID).Msg("Hierarchy combined with own limits")
+			return nil, fmt.Errorf("hierarchy cannot be combined with limit, window, limits or policy")
+		}
+		cmd.Rules, err = limits.HierarchyRules(h.logger, cmd.UserID, cmd.Descriptors, cmd.Hierarchy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cmd.Cost == 0 {
+		cmd.Cost = 1
+	}
+
+	if cmd.Cost < 0 {
+		h.logger.Error().Int("cost", cmd.Cost).Msg("Invalid cost provided")
+		return nil, fmt.Errorf("cost must be greater than 0")
+	}
+
+	// Listed subjects were never counted, so there is nothing to give back
+	if rule := accessDecision(ctx, h.logger, h.access, cmd.UserID, cmd.Descriptors); rule != nil {
+		h.logger.Info().Str("user_id", cmd.UserID).Int64("rule_id", rule.ID).Str("access", string(rule.List)).Msg("Rate limit refund skipped for listed subject")
+		return &RefundRateLimitResponse{Access: rule.List, AccessRule: rule.ID}, nil
+	}
+
+	// Refunds go back to the limits the request was checked against, including the user's override
+	resolved, err := h.limits.Resolve(ctx, limits.Request{
+		UserID:      cmd.UserID,
+		Limit:       cmd.Limit,
+		Window:      cmd.Window,
+		Rules:       cmd.Rules,
+		Cost:        cmd.Cost,
+		Algorithm:   cmd.Algorithm,
+		Policy:      cmd.Policy,
+		Descriptors: cmd.Descriptors,
+	})
+	if err != nil {
+		return nil, err
+	}
+	cmd.Rules, cmd.Algorithm, cmd.Policy = resolved.Rules, resolved.Algorithm, ""
+	if resolved.Policy != nil {
+		cmd.Policy = resolved.Policy.Name
+	}
+
+	if cmd.Algorithm.Windowed() && len(cmd.WindowIDs) != len(cmd.Rules) {
+		h.logger.Error().Str("user_id", cmd.UserID).Int("window_ids", len(cmd.WindowIDs)).Int("rules", len(cmd.Rules)).Msg("Window IDs missing from refund")
+		return nil, fmt.Errorf("%w: %d given for %d limits", domain.ErrWindowsRequired, len(cmd.WindowIDs), len(cmd.Rules))
+	}
+
+	repository, err := h.repositoryProvider.Repository(cmd.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	compound, err := repository.Refund(key, cmd.Rules, cmd.WindowIDs, cmd.Cost)
+	if err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Failed to refund rate limit")
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+
+	results := make([]RuleResult, len(compound.Results))
+	for i, result := range compound.Results {
+		results[i] = RuleResult{
+			Limit:      result.Limit,
+			Window:     cmd.Rules[i].Window,
+			Remaining:  result.Remaining,
+			ResetTime:  result.ResetAfter,
+			RetryAfter: result.RetryAfter,
+			Allowed:    result.Allowed,
+			Level:      cmd.Rules[i].Level,
+			WindowID:   result.WindowID,
+		}
+	}
+
+	binding := results[compound.BindingIndex]
+	response := &RefundRateLimitResponse{
+		Limit:     binding.Limit,
+		Remaining: binding.Remaining,
+		ResetTime: compound.ResetAfter,
+		Window:    binding.Window,
+		Cost:      cmd.Cost,
+		Algorithm: cmd.Algorithm,
+		Policy:    cmd.Policy,
+		Results:   results,
+		Binding:   compound.BindingIndex,
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Int("limit", response.Limit).Dur("window", response.Window).Int("cost", response.Cost).Int("remaining", response.Remaining).Msg("Rate limit refund completed")
+
+	return response, nil
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// ReleaseLeaseCommand represents a command to give back a lease before it expires
+type ReleaseLeaseCommand struct {
+	UserID  string
+	LeaseID string
+}
+
+// ReleaseLeaseCommandHandler handles lease release commands
+type ReleaseLeaseCommandHandler struct {
+	logger     logger.Logger
+	repository ports.ConcurrencyLimitRepository
+}
+
+// NewReleaseLeaseCommandHandler creates a new ReleaseLeaseCommandHandler
+func NewReleaseLeaseCommandHandler(
+	logger logger.Logger,
+	repository ports.ConcurrencyLimitRepository,
+) *ReleaseLeaseCommandHandler {
+	return &ReleaseLeaseCommandHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle processes the ReleaseLeaseCommand, returning false if the lease was not held
+func (h *ReleaseLeaseCommandHandler) Handle(ctx context.Context, cmd ReleaseLeaseCommand) (bool, error) {
+	h.logger.Info().Str("user_id", cmd.UserID).Str("lease_id", cmd.LeaseID).Msg("Processing lease release")
+
+	if cmd.UserID == "" {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
+		return false, fmt.Errorf("user ID cannot be empty")
+	}
+
+	if cmd.LeaseID == "" {
+		h.logger.Error().Msg("Invalid lease ID provided")
+		return false, fmt.Errorf("lease ID cannot be empty")
+	}
+
+	released, err := h.repository.Release(cmd.UserID, cmd.LeaseID)
+	if err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Failed to release lease")
+		return false, fmt.Errorf("failed to release lease: %w", err)
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Str("lease_id", cmd.LeaseID).Bool("released", released).Msg("Lease release completed")
+
+	return released, nil
+}
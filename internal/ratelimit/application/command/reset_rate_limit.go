@@ -0,0 +1,110 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/limits"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// ResetRateLimitCommand represents a command to clear a user's counter for a window
+type ResetRateLimitCommand struct {
+	UserID    string
+	Window    time.Duration
+	Algorithm domain.Algorithm // Algorithm whose state is cleared, empty to clear the state of every algorithm
+	// Further dimensions the counter is kept by, such as an IP address or route, alongside the user if any
+	Descriptors domain.Descriptors
+	Level       domain.Level // Hierarchy level whose counter is cleared, keyed by the descriptors of that level
+}
+
+// ResetRateLimitCommandHandler handles rate limit reset commands
+type ResetRateLimitCommandHandler struct {
+	logger             logger.Logger
+	repositoryProvider ports.RateLimitRepositoryProvider
+}
+
+// NewResetRateLimitCommandHandler creates a new ResetRateLimitCommandHandler
+func NewResetRateLimitCommandHandler(
+	logger logger.Logger,
+	repositoryProvider ports.RateLimitRepositoryProvider,
+) *ResetRateLimitCommandHandler {
+	return &ResetRateLimitCommandHandler{
+		logger:             logger,
+		repositoryProvider: repositoryProvider,
+	}
+}
+
+// Handle processes the ResetRateLimitCommand
+func (h *ResetRateLimitCommandHandler) Handle(ctx context.Context, cmd ResetRateLimitCommand) error {
+	h.logger.Info().Str("user_id", cmd.UserID).Dur("window", cmd.Window).Str("algorithm", string(cmd.Algorithm)).Str("level", string(cmd.Level)).Msg("Processing rate limit reset")
+
+	if cmd.UserID == "" && len(cmd.Descriptors) == 0 {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
+		return fmt.Errorf("user ID cannot be empty without descriptors")
+	}
+
+	key, err := adminSubjectKey(h.logger, cmd.UserID, cmd.Descriptors, cmd.Level)
+	if err != nil {
+		return err
+	}
+
+	if cmd.Window == 0 {
+		cmd.Window = domain.DefaultWindow
+	}
+
+	if cmd.Window < domain.MinWindow {
+		h.logger.Error().Dur("window", cmd.Window).Msg("Invalid window provided")
+		return fmt.Errorf("window must be at least %s", domain.MinWindow)
+	}
+
+	// The algorithm a subject is checked with can come from its tier or a policy, so by default every one is cleared
+	algorithms := domain.Algorithms
+	if cmd.Algorithm != "" {
+		algorithms = []domain.Algorithm{cmd.Algorithm}
+	}
+
+	for _, algorithm := range algorithms {
+		repository, err := h.repositoryProvider.AdminRepository(algorithm)
+		if err != nil {
+			return err
+		}
+
+		if err := repository.Reset(key, cmd.Window); err != nil {
+			h.logger.Error().Str("user_id", cmd.UserID).Str("algorithm", string(algorithm)).Err(err).Msg("Failed to reset rate limit")
+			return fmt.Errorf("failed to reset rate limit: %w", err)
+		}
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Dur("window", cmd.Window).Str("key", key).Msg("Rate limit reset completed")
+
+	return nil
+}
+
+// adminSubjectKey returns the storage key of the counters an administrative change applies to, which are those of
+// the user and descriptors, or those of a hierarchy level when one is given
+func adminSubjectKey(logger logger.Logger, userId string, descriptors domain.Descriptors, level domain.Level) (string, error) {
+	if level == "" {
+		return limits.SubjectKey(logger, userId, descriptors)
+	}
+
+	if !level.IsValid() {
+		logger.Error().Str("level", string(level)).Msg("Invalid hierarchy level provided")
+		return "", fmt.Errorf("unsupported hierarchy level: %s", level)
+	}
+
+	if err := descriptors.Validate(); err != nil {
+		logger.Error().Str("user_id", userId).Err(err).Msg("Invalid descriptors provided")
+		return "", err
+	}
+
+	levelDescriptors, err := level.Descriptors(userId, descriptors.Canonical())
+	if err != nil {
+		logger.Error().Str("user_id", userId).Str("level", string(level)).Err(err).Msg("Missing descriptor for hierarchy level")
+		return "", err
+	}
+	return levelDescriptors.Key(), nil
+}
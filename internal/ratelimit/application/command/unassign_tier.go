@@ -0,0 +1,50 @@
+package command
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// UnassignTierCommand represents a command to remove a user's tier assignment, so the default tier applies again
+type UnassignTierCommand struct {
+	UserID string
+}
+
+// UnassignTierCommandHandler handles tier removal commands
+type UnassignTierCommandHandler struct {
+	logger     logger.Logger
+	repository ports.TierRepository
+}
+
+// NewUnassignTierCommandHandler creates a new UnassignTierCommandHandler
+func NewUnassignTierCommandHandler(
+	logger logger.Logger,
+	repository ports.TierRepository,
+) *UnassignTierCommandHandler {
+	return &UnassignTierCommandHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle processes the UnassignTierCommand
+func (h *UnassignTierCommandHandler) Handle(ctx context.Context, cmd UnassignTierCommand) error {
+	h.logger.Info().Str("user_id", cmd.UserID).Msg("Processing tier removal")
+
+	if cmd.UserID == "" {
+		h.logger.Error().Str("user_id", cmd.UserID).Msg("Invalid user ID provided")
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if err := h.repository.Unassign(ctx, cmd.UserID); err != nil {
+		h.logger.Error().Str("user_id", cmd.UserID).Err(err).Msg("Failed to remove tier assignment")
+		return fmt.Errorf("failed to remove tier assignment: %w", err)
+	}
+
+	h.logger.Info().Str("user_id", cmd.UserID).Msg("Tier removal completed")
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// UpdatePolicyCommand represents a command to replace the settings of an existing rate limit policy.
+// Counters are kept per window, so changing the window starts the policy's users on fresh counters.
+type UpdatePolicyCommand struct {
+	Name      string
+	Limit     int
+	Window    time.Duration // Defaults to domain.DefaultWindow
+	Algorithm domain.Algorithm
+	Burst     int
+	Mode      domain.PolicyMode // Defaults to domain.DefaultPolicyMode
+	Schedules []domain.Schedule // Time ranges replacing the limit, the first active one wins
+}
+
+// UpdatePolicyCommandHandler handles policy update commands
+type UpdatePolicyCommandHandler struct {
+	logger     logger.Logger
+	repository ports.PolicyRepository
+}
+
+// NewUpdatePolicyCommandHandler creates a new UpdatePolicyCommandHandler
+func NewUpdatePolicyCommandHandler(
+	logger logger.Logger,
+	repository ports.PolicyRepository,
+) *UpdatePolicyCommandHandler {
+	return &UpdatePolicyCommandHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle processes the UpdatePolicyCommand
+func (h *UpdatePolicyCommandHandler) Handle(ctx context.Context, cmd UpdatePolicyCommand) (*domain.Policy, error) {
+	h.logger.Info().Str("policy", cmd.Name).Int("limit", cmd.Limit).Dur("window", cmd.Window).Str("algorithm", string(cmd.Algorithm)).Int("burst", cmd.Burst).Str("mode", string(cmd.Mode)).Int("schedules", len(cmd.Schedules)).Msg("Processing policy update")
+
+	if cmd.Window == 0 {
+		cmd.Window = domain.DefaultWindow
+	}
+
+	if cmd.Algorithm == "" {
+		cmd.Algorithm = domain.DefaultAlgorithm
+	}
+
+	if cmd.Mode == "" {
+		cmd.Mode = domain.DefaultPolicyMode
+	}
+
+	policy := &domain.Policy{
+		Name:      cmd.Name,
+		Limit:     cmd.Limit,
+		Window:    cmd.Window,
+		Algorithm: cmd.Algorithm,
+		Burst:     cmd.Burst,
+		Mode:      cmd.Mode,
+		Schedules: cmd.Schedules,
+	}
+	if err := policy.Validate(); err != nil {
+		h.logger.Error().Str("policy", cmd.Name).Err(err).Msg("Invalid policy provided")
+		return nil, err
+	}
+
+	if err := h.repository.Update(ctx, policy); err != nil {
+		h.logger.Error().Str("policy", cmd.Name).Err(err).Msg("Failed to update policy")
+		return nil, fmt.Errorf("failed to update policy: %w", err)
+	}
+
+	h.logger.Info().Str("policy", policy.Name).Msg("Policy update completed")
+
+	return policy, nil
+}
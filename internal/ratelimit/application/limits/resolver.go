@@ -0,0 +1,275 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// Request holds the limits a request asks for, which may be none at all
+type Request struct {
+	UserID      string
+	Limit       int
+	Window      time.Duration
+	Rules       []domain.Rule // Limits checked together, overriding Limit and Window when set
+	Cost        int           // Units the request consumes from every limit
+	Algorithm   domain.Algorithm
+	Policy      string             // Name of a stored policy supplying the limits instead
+	Descriptors domain.Descriptors // The route and tenant select the policy declared for them in the policy file
+}
+
+// Resolution holds the limits a request is counted against
+type Resolution struct {
+	Rules     []domain.Rule
+	Algorithm domain.Algorithm
+	Policy    *domain.Policy   // Policy the limits were taken from, if any
+	Override  *domain.Override // Override of the user that scaled or replaced the limits, if any
+}
+
+// Resolver works out the limits a request is counted against from the limits it asks for, the policy file,
+// the stored policies, the user's tier and override, and the schedules of the policy, so that checking, refunding
+// and reporting a request all agree on them
+type Resolver struct {
+	logger        logger.Logger
+	policies      ports.PolicyRepository
+	declared      ports.PolicyFileSource
+	overrides     ports.OverrideRepository
+	tiers         ports.TierRepository
+	clock         ports.Clock
+	defaultLimit  int
+	defaultBurst  int
+	requirePolicy bool
+	defaultTier   string
+}
+
+// NewResolver creates a new Resolver
+func NewResolver(
+	logger logger.Logger,
+	policies ports.PolicyRepository,
+	declared ports.PolicyFileSource,
+	overrides ports.OverrideRepository,
+	tiers ports.TierRepository,
+	clock ports.Clock,
+	cfg *config.Config,
+) *Resolver {
+	return &Resolver{
+		logger:        logger,
+		policies:      policies,
+		declared:      declared,
+		overrides:     overrides,
+		tiers:         tiers,
+		clock:         clock,
+		defaultLimit:  cfg.RateLimit.RequestsPerMinute,
+		defaultBurst:  cfg.RateLimit.Burst,
+		requirePolicy: cfg.RateLimit.RequirePolicy,
+		defaultTier:   cfg.RateLimit.DefaultTier,
+	}
+}
+
+// Resolve returns the limits the request is counted against, validated against its cost
+func (r *Resolver) Resolve(ctx context.Context, req Request) (*Resolution, error) {
+	// A request that brings no limits of its own is checked against the policy declared for its route, tenant or tier,
+	// or else the stored policy of the user's tier
+	var policy *domain.Policy
+	name := req.Policy
+	if name == "" && req.Limit == 0 && req.Window == 0 && len(req.Rules) == 0 && req.Algorithm == "" {
+		policy, name = defaultPolicy(ctx, r.logger, r.declared, r.tiers, req.UserID, req.Descriptors, r.defaultTier)
+	}
+
+	if policy == nil {
+		var err error
+		policy, err = resolvePolicy(ctx, r.logger, r.policies, name, r.requirePolicy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Schedules and override expiry are both judged at the same instant of the clock
+	now := r.clock.Now()
+
+	resolution := &Resolution{Rules: req.Rules, Algorithm: req.Algorithm, Policy: policy}
+	if policy != nil {
+		// The policy's schedules pick its limit for the current time
+		resolution.Rules, resolution.Algorithm = []domain.Rule{policy.RuleAt(now)}, policy.Algorithm
+	}
+
+	if resolution.Algorithm == "" {
+		resolution.Algorithm = domain.DefaultAlgorithm
+	}
+
+	// A single limit is a compound check with one rule
+	if len(resolution.Rules) == 0 {
+		resolution.Rules = []domain.Rule{{Limit: req.Limit, Window: req.Window}}
+	}
+
+	if err := normalizeRules(r.logger, resolution.Rules, req.Cost, resolution.Algorithm, r.defaultLimit, r.defaultBurst); err != nil {
+		return nil, err
+	}
+
+	// The user's override scales or replaces the requested limits, so the result is validated again
+	resolution.Override = activeOverride(ctx, r.logger, r.overrides, req.UserID, now)
+	if resolution.Override != nil {
+		resolution.Rules = applyOverride(resolution.Override, resolution.Rules)
+		if err := normalizeRules(r.logger, resolution.Rules, req.Cost, resolution.Algorithm, r.defaultLimit, r.defaultBurst); err != nil {
+			return nil, err
+		}
+	}
+
+	return resolution, nil
+}
+
+// resolvePolicy loads the named policy. Without a name it returns no policy, or domain.ErrPolicyRequired
+// when requests must reference a policy instead of passing their own limits.
+func resolvePolicy(ctx context.Context, logger logger.Logger, policies ports.PolicyRepository, name string, requirePolicy bool) (*domain.Policy, error) {
+	if name == "" {
+		if requirePolicy {
+			logger.Error().Msg("Request without a policy rejected")
+			return nil, domain.ErrPolicyRequired
+		}
+		return nil, nil
+	}
+
+	policy, err := policies.Get(ctx, name)
+	if err != nil {
+		logger.Error().Str("policy", name).Err(err).Msg("Failed to resolve policy")
+		return nil, fmt.Errorf("failed to resolve policy %q: %w", name, err)
+	}
+	return policy, nil
+}
+
+// defaultPolicy picks the limits of a request that brings none of its own. It returns the policy the policy file declares
+// for the route or tenant descriptor, else the user's tier, or when the file declares none of them, the name of the user's tier
+// so that its stored policy applies. Users without a tier, or whose tier cannot be loaded, fall back to the default tier.
+func defaultPolicy(ctx context.Context, logger logger.Logger, declared ports.PolicyFileSource, tiers ports.TierRepository, userId string, descriptors domain.Descriptors, defaultTier string) (*domain.Policy, string) {
+	policies := declared.Current()
+	if policy, ok := policies.Match(descriptors[domain.DescriptorRoute], descriptors[domain.DescriptorTenant], ""); ok {
+		logger.Debug().Str("user_id", userId).Str("policy", policy.Name).Msg("Applying declared policy")
+		return policy, ""
+	}
+
+	// Requests counted without a user, e.g. per IP address, are in the default tier
+	tier := defaultTier
+	if userId != "" {
+		assignment, err := tiers.Get(ctx, userId)
+		switch {
+		case err == nil:
+			tier = assignment.Tier
+		case !errors.Is(err, domain.ErrTierNotAssigned):
+			logger.Error().Str("user_id", userId).Str("default_tier", defaultTier).Err(err).Msg("Failed to load tier, falling back to the default tier")
+		}
+	}
+
+	if policy, ok := policies.Match("", "", tier); ok {
+		logger.Debug().Str("user_id", userId).Str("policy", policy.Name).Msg("Applying declared policy")
+		return policy, ""
+	}
+	if tier != "" {
+		logger.Debug().Str("user_id", userId).Str("tier", tier).Msg("Applying tier policy")
+	}
+	return nil, tier
+}
+
+// activeOverride returns the user's override unexpired at now, or nil when the user has none or the request has no user.
+// Overrides are best effort: when they cannot be loaded the requested limits are enforced rather than failing the request.
+func activeOverride(ctx context.Context, logger logger.Logger, overrides ports.OverrideRepository, userId string, now time.Time) *domain.Override {
+	if userId == "" {
+		return nil
+	}
+
+	override, err := overrides.Get(ctx, userId)
+	if errors.Is(err, domain.ErrOverrideNotFound) {
+		return nil
+	}
+	if err != nil {
+		logger.Error().Str("user_id", userId).Err(err).Msg("Failed to load override, enforcing the requested limits")
+		return nil
+	}
+	if !override.Active(now) {
+		return nil
+	}
+
+	logger.Debug().Str("user_id", userId).Str("reason", override.Reason).Msg("Applying rate limit override")
+	return override
+}
+
+// applyOverride applies the user's override to the limits of a request. In a hierarchical check only the user level is
+// the user's own, so the tenant and endpoint levels are left as they are.
+func applyOverride(override *domain.Override, rules []domain.Rule) []domain.Rule {
+	hierarchical := false
+	for _, rule := range rules {
+		if rule.Level != "" {
+			hierarchical = true
+		}
+	}
+	if !hierarchical {
+		return override.Apply(rules)
+	}
+
+	applied := make([]domain.Rule, 0, len(rules))
+	for _, rule := range rules {
+		if rule.Level != domain.LevelUser {
+			applied = append(applied, rule)
+			continue
+		}
+		for _, replaced := range override.Apply([]domain.Rule{rule}) {
+			replaced.Subject, replaced.Level = rule.Subject, rule.Level
+			applied = append(applied, replaced)
+		}
+	}
+	return applied
+}
+
+// ruleCounter identifies the counter a rule is tracked in
+type ruleCounter struct {
+	subject string
+	window  time.Duration
+}
+
+// normalizeRules fills in the default limit and window of every rule and validates them against the cost of a request
+// under the algorithm, whose burst may admit less at once than the limit
+func normalizeRules(logger logger.Logger, rules []domain.Rule, cost int, algorithm domain.Algorithm, defaultLimit int, defaultBurst int) error {
+	counters := make(map[ruleCounter]bool, len(rules))
+	for i := range rules {
+		rule := &rules[i]
+
+		// Fall back to the configured requests per minute when no limit is provided
+		if rule.Limit == 0 {
+			rule.Limit = defaultLimit
+		}
+
+		if rule.Limit <= 0 {
+			logger.Error().Int("limit", rule.Limit).Msg("Invalid limit provided")
+			return fmt.Errorf("limit must be greater than 0")
+		}
+
+		if rule.Window == 0 {
+			rule.Window = domain.DefaultWindow
+		}
+
+		if rule.Window < domain.MinWindow {
+			logger.Error().Dur("window", rule.Window).Msg("Invalid window provided")
+			return fmt.Errorf("window must be at least %s", domain.MinWindow)
+		}
+
+		// A request costing more than the limit, or the burst of the algorithm, could never be admitted
+		if capacity := rule.Capacity(algorithm, defaultBurst); cost > capacity {
+			logger.Error().Int("cost", cost).Int("limit", rule.Limit).Int("capacity", capacity).Str("algorithm", string(algorithm)).Msg("Cost exceeds capacity")
+			return fmt.Errorf("%w: cost %d exceeds %d, the most the %s limit of %d admits at once", domain.ErrCostExceedsCapacity, cost, capacity, algorithm, rule.Limit)
+		}
+
+		// Limits are tracked per subject and window, so two limits on the same window would share a counter
+		counter := ruleCounter{subject: rule.Subject, window: rule.Window}
+		if counters[counter] {
+			logger.Error().Dur("window", rule.Window).Msg("Duplicate window provided")
+			return fmt.Errorf("only one limit per window is allowed, got several for %s", rule.Window)
+		}
+		counters[counter] = true
+	}
+	return nil
+}
@@ -0,0 +1,177 @@
+package limits
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+type fakePolicies map[string]domain.Policy
+
+func (f fakePolicies) Create(ctx context.Context, policy *domain.Policy) error { return nil }
+func (f fakePolicies) List(ctx context.Context) ([]domain.Policy, error)       { return nil, nil }
+func (f fakePolicies) Update(ctx context.Context, policy *domain.Policy) error { return nil }
+func (f fakePolicies) Delete(ctx context.Context, name string) error           { return nil }
+
+func (f fakePolicies) Get(ctx context.Context, name string) (*domain.Policy, error) {
+	policy, ok := f[name]
+	if !ok {
+		return nil, domain.ErrPolicyNotFound
+	}
+	return &policy, nil
+}
+
+type fakePolicyFile struct{ set domain.PolicySet }
+
+func (f fakePolicyFile) Current() *domain.PolicySet { return &f.set }
+
+type fakeOverrides map[string]domain.Override
+
+func (f fakeOverrides) Put(ctx context.Context, override *domain.Override) error { return nil }
+func (f fakeOverrides) List(ctx context.Context) ([]domain.Override, error)      { return nil, nil }
+func (f fakeOverrides) Delete(ctx context.Context, userId string) error          { return nil }
+
+func (f fakeOverrides) Get(ctx context.Context, userId string) (*domain.Override, error) {
+	override, ok := f[userId]
+	if !ok {
+		return nil, domain.ErrOverrideNotFound
+	}
+	return &override, nil
+}
+
+type fakeTiers map[string]string
+
+func (f fakeTiers) Assign(ctx context.Context, assignment *domain.TierAssignment) error { return nil }
+func (f fakeTiers) Unassign(ctx context.Context, userId string) error                   { return nil }
+func (f fakeTiers) Import(ctx context.Context, assignments []domain.TierAssignment) error {
+	return nil
+}
+
+func (f fakeTiers) Get(ctx context.Context, userId string) (*domain.TierAssignment, error) {
+	tier, ok := f[userId]
+	if !ok {
+		return nil, domain.ErrTierNotAssigned
+	}
+	return &domain.TierAssignment{UserID: userId, Tier: tier}, nil
+}
+
+type fakeClock struct{ now time.Time }
+
+func (f fakeClock) Now() time.Time { return f.now }
+
+// newTestResolver creates a resolver defaulting to 100 requests per minute with a burst of 10
+func newTestResolver(policies fakePolicies, declared domain.PolicySet, overrides fakeOverrides, tiers fakeTiers, now time.Time) *Resolver {
+	cfg := &config.Config{RateLimit: config.RateLimitConfig{RequestsPerMinute: 100, Burst: 10}}
+	return NewResolver(logger.NewWithLevel("disabled"), policies, fakePolicyFile{set: declared}, overrides, tiers, fakeClock{now: now}, cfg)
+}
+
+func TestResolverAppliesTheSamePolicySources(t *testing.T) {
+	now := time.Date(2026, time.October, 14, 12, 0, 0, 0, time.UTC)
+	free := domain.Policy{Name: "free", Limit: 10, Window: time.Minute, Algorithm: domain.AlgorithmFixedWindow, Mode: domain.PolicyEnforce}
+	declaredPro := domain.Policy{Name: "pro", Limit: 1000, Window: time.Hour, Algorithm: domain.AlgorithmSlidingWindowCounter, Mode: domain.PolicyEnforce}
+
+	resolver := newTestResolver(
+		fakePolicies{"free": free},
+		domain.PolicySet{Tiers: map[string]domain.Policy{"pro": declaredPro}},
+		fakeOverrides{"doubled": {UserID: "doubled", Multiplier: 2}},
+		fakeTiers{"alice": "free", "bob": "pro", "doubled": "free"},
+		now,
+	)
+
+	tests := []struct {
+		name       string
+		req        Request
+		wantRule   domain.Rule
+		algorithm  domain.Algorithm
+		policy     string
+		overridden bool
+	}{
+		{"stored policy of the tier", Request{UserID: "alice", Cost: 1}, domain.Rule{Limit: 10, Window: time.Minute}, domain.AlgorithmFixedWindow, "free", false},
+		{"declared policy of the tier", Request{UserID: "bob", Cost: 1}, domain.Rule{Limit: 1000, Window: time.Hour}, domain.AlgorithmSlidingWindowCounter, "pro", false},
+		{"named policy", Request{UserID: "bob", Policy: "free", Cost: 1}, domain.Rule{Limit: 10, Window: time.Minute}, domain.AlgorithmFixedWindow, "free", false},
+		{"own limit", Request{UserID: "alice", Limit: 5, Window: time.Second, Cost: 1}, domain.Rule{Limit: 5, Window: time.Second}, domain.DefaultAlgorithm, "", false},
+		{"defaults without a tier", Request{UserID: "carol", Cost: 1}, domain.Rule{Limit: 100, Window: domain.DefaultWindow}, domain.DefaultAlgorithm, "", false},
+		{"override on the tier policy", Request{UserID: "doubled", Cost: 1}, domain.Rule{Limit: 20, Window: time.Minute}, domain.AlgorithmFixedWindow, "free", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolved, err := resolver.Resolve(context.Background(), tt.req)
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if len(resolved.Rules) != 1 || resolved.Rules[0] != tt.wantRule {
+				t.Errorf("rules = %+v, want %+v", resolved.Rules, tt.wantRule)
+			}
+			if resolved.Algorithm != tt.algorithm {
+				t.Errorf("algorithm = %s, want %s", resolved.Algorithm, tt.algorithm)
+			}
+			var policy string
+			if resolved.Policy != nil {
+				policy = resolved.Policy.Name
+			}
+			if policy != tt.policy {
+				t.Errorf("policy = %q, want %q", policy, tt.policy)
+			}
+			if (resolved.Override != nil) != tt.overridden {
+				t.Errorf("overridden = %t, want %t", resolved.Override != nil, tt.overridden)
+			}
+		})
+	}
+}
+
+func TestResolverRejectsCostAboveTheBurst(t *testing.T) {
+	resolver := newTestResolver(fakePolicies{}, domain.PolicySet{}, fakeOverrides{}, fakeTiers{}, time.Now())
+
+	_, err := resolver.Resolve(context.Background(), Request{UserID: "alice", Limit: 100, Cost: 11, Algorithm: domain.AlgorithmTokenBucket})
+	if !errors.Is(err, domain.ErrCostExceedsCapacity) {
+		t.Errorf("Resolve() error = %v, want %v", err, domain.ErrCostExceedsCapacity)
+	}
+}
+
+func TestResolverJudgesSchedulesAndOverridesByTheClock(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("time zone America/New_York not available: %v", err)
+	}
+	nightly := domain.Policy{
+		Name:      "nightly",
+		Limit:     100,
+		Window:    time.Minute,
+		Algorithm: domain.AlgorithmFixedWindow,
+		Mode:      domain.PolicyEnforce,
+		Schedules: []domain.Schedule{{Start: 22 * time.Hour, End: 6 * time.Hour, Location: newYork, Limit: 10}},
+	}
+	expiresAt := time.Date(2026, time.October, 17, 6, 0, 0, 0, time.UTC)
+	overrides := fakeOverrides{"alice": {UserID: "alice", Multiplier: 2, ExpiresAt: &expiresAt}}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want int
+	}{
+		{"daytime with the override", time.Date(2026, time.October, 16, 12, 0, 0, 0, newYork), 200},
+		{"night in New York with the override", time.Date(2026, time.October, 17, 3, 0, 0, 0, time.UTC), 20},
+		{"night after the override expired", time.Date(2026, time.October, 17, 8, 0, 0, 0, time.UTC), 10},
+		{"daytime after the override expired", time.Date(2026, time.October, 17, 12, 0, 0, 0, time.UTC), 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolver(fakePolicies{"nightly": nightly}, domain.PolicySet{}, overrides, fakeTiers{}, tt.now)
+
+			resolved, err := resolver.Resolve(context.Background(), Request{UserID: "alice", Policy: "nightly", Cost: 1})
+			if err != nil {
+				t.Fatalf("Resolve() error = %v", err)
+			}
+			if got := resolved.Rules[0].Limit; got != tt.want {
+				t.Errorf("limit at %s = %d, want %d", tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package limits
+
+import (
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// SubjectKey validates the descriptors of a request and returns the storage key of its counters, which identifies the user
+// together with the canonical value of every descriptor
+func SubjectKey(logger logger.Logger, userId string, descriptors domain.Descriptors) (string, error) {
+	if err := descriptors.Validate(); err != nil {
+		logger.Error().Str("user_id", userId).Err(err).Msg("Invalid descriptors provided")
+		return "", err
+	}
+
+	if _, ok := descriptors[domain.DescriptorUser]; ok {
+		logger.Error().Str("user_id", userId).Msg("User provided as a descriptor")
+		return "", fmt.Errorf("the user is given by the user ID, not as a descriptor")
+	}
+
+	canonical := descriptors.Canonical()
+	if userId != "" {
+		canonical[domain.DescriptorUser] = userId
+	}
+	return canonical.Key(), nil
+}
+
+// HierarchyRules builds the rules of a hierarchical check from the broadest level to the narrowest,
+// each counted under the descriptors of its level
+func HierarchyRules(logger logger.Logger, userId string, descriptors domain.Descriptors, hierarchy map[domain.Level]domain.Rule) ([]domain.Rule, error) {
+	for level := range hierarchy {
+		if !level.IsValid() {
+			logger.Error().Str("level", string(level)).Msg("Invalid hierarchy level provided")
+			return nil, fmt.Errorf("unsupported hierarchy level: %s", level)
+		}
+	}
+
+	// Levels are keyed by the tenant, user, route and method only, so any other descriptor would be silently ignored
+	for name := range descriptors {
+		if name != domain.DescriptorTenant && name != domain.DescriptorRoute && name != domain.DescriptorMethod {
+			logger.Error().Str("descriptor", string(name)).Msg("Descriptor not supported by hierarchy")
+			return nil, fmt.Errorf("descriptor %s cannot be combined with hierarchy", name)
+		}
+	}
+
+	canonical := descriptors.Canonical()
+	rules := make([]domain.Rule, 0, len(hierarchy))
+	for _, level := range domain.Levels {
+		rule, ok := hierarchy[level]
+		if !ok {
+			continue
+		}
+
+		levelDescriptors, err := level.Descriptors(userId, canonical)
+		if err != nil {
+			logger.Error().Str("user_id", userId).Str("level", string(level)).Err(err).Msg("Missing descriptor for hierarchy level")
+			return nil, err
+		}
+		rule.Subject, rule.Level = levelDescriptors.Key(), level
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// GetOverrideQuery represents a query for the override of a user
+type GetOverrideQuery struct {
+	UserID string
+}
+
+// GetOverrideQueryHandler handles override queries
+type GetOverrideQueryHandler struct {
+	logger     logger.Logger
+	repository ports.OverrideRepository
+}
+
+// NewGetOverrideQueryHandler creates a new override query handler
+func NewGetOverrideQueryHandler(
+	logger logger.Logger,
+	repository ports.OverrideRepository,
+) *GetOverrideQueryHandler {
+	return &GetOverrideQueryHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle executes the override query, returning the override even when it expired
+func (h *GetOverrideQueryHandler) Handle(ctx context.Context, query GetOverrideQuery) (*domain.Override, error) {
+	h.logger.Debug().Str("user_id", query.UserID).Msg("Processing override query")
+
+	if query.UserID == "" {
+		h.logger.Error().Str("user_id", query.UserID).Msg("Invalid user ID provided")
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	override, err := h.repository.Get(ctx, query.UserID)
+	if err != nil {
+		h.logger.Error().Str("user_id", query.UserID).Err(err).Msg("Failed to get override")
+		return nil, fmt.Errorf("failed to get override: %w", err)
+	}
+
+	return override, nil
+}
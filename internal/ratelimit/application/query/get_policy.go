@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// GetPolicyQuery represents a query for a single rate limit policy
+type GetPolicyQuery struct {
+	Name string
+}
+
+// GetPolicyQueryHandler handles policy queries
+type GetPolicyQueryHandler struct {
+	logger     logger.Logger
+	repository ports.PolicyRepository
+}
+
+// NewGetPolicyQueryHandler creates a new policy query handler
+func NewGetPolicyQueryHandler(
+	logger logger.Logger,
+	repository ports.PolicyRepository,
+) *GetPolicyQueryHandler {
+	return &GetPolicyQueryHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle executes the policy query
+func (h *GetPolicyQueryHandler) Handle(ctx context.Context, query GetPolicyQuery) (*domain.Policy, error) {
+	h.logger.Debug().Str("policy", query.Name).Msg("Processing policy query")
+
+	if query.Name == "" {
+		h.logger.Error().Msg("Invalid policy name provided")
+		return nil, fmt.Errorf("policy name cannot be empty")
+	}
+
+	policy, err := h.repository.Get(ctx, query.Name)
+	if err != nil {
+		h.logger.Error().Str("policy", query.Name).Err(err).Msg("Failed to get policy")
+		return nil, fmt.Errorf("failed to get policy: %w", err)
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,143 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/limits"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// GetRateLimitStatusQuery represents a query for a user's current quota
+type GetRateLimitStatusQuery struct {
+	UserID    string
+	Limit     int
+	Window    time.Duration
+	Rules     []domain.Rule // Limits reported together, overriding Limit and Window when set
+	Algorithm domain.Algorithm
+	Policy    string // Name of a stored policy supplying the limit, window, algorithm and burst instead
+	// Further dimensions the requests are counted by, such as their IP address or route, alongside the user if any.
+	// The route and tenant also select the policy declared for them in the policy file.
+	Descriptors domain.Descriptors
+	// Limits of the hierarchy levels the requests consume from together, instead of their own limits
+	Hierarchy map[domain.Level]domain.Rule
+}
+
+// GetRateLimitStatusResponse represents a user's current quota
+type GetRateLimitStatusResponse struct {
+	Limit      int
+	Used       int
+	Remaining  int
+	ResetTime  time.Duration
+	RetryAfter time.Duration
+	Allowed    bool // Whether a request made now would be admitted
+	Window     time.Duration
+	Algorithm  domain.Algorithm
+	Policy     string
+	Overridden bool         // Whether the user's override replaced the requested limit
+	Level      domain.Level // Level of the hierarchy the reported limit belongs to, if any
+}
+
+// GetRateLimitStatusQueryHandler handles rate limit status queries without consuming any quota
+type GetRateLimitStatusQueryHandler struct {
+	logger             logger.Logger
+	repositoryProvider ports.RateLimitRepositoryProvider
+	limits             *limits.Resolver
+}
+
+// NewGetRateLimitStatusQueryHandler creates a new rate limit status query handler
+func NewGetRateLimitStatusQueryHandler(
+	logger logger.Logger,
+	repositoryProvider ports.RateLimitRepositoryProvider,
+	resolver *limits.Resolver,
+) *GetRateLimitStatusQueryHandler {
+	return &GetRateLimitStatusQueryHandler{
+		logger:             logger,
+		repositoryProvider: repositoryProvider,
+		limits:             resolver,
+	}
+}
+
+// Handle executes the rate limit status query
+func (h *GetRateLimitStatusQueryHandler) Handle(ctx context.Context, query GetRateLimitStatusQuery) (*GetRateLimitStatusResponse, error) {
+	h.logger.Info().Str("user_id", query.UserID).Int("limit", query.Limit).Dur("window", query.Window).Str("algorithm", string(query.Algorithm)).Str("policy", query.Policy).Msg("Processing rate limit status query")
+
+	if query.UserID == "" && len(query.Descriptors) == 0 {
+		h.logger.Error().Str("user_id", query.UserID).Msg("Invalid user ID provided")
+		return nil, fmt.Errorf("user ID cannot be empty without descriptors")
+	}
+
+	key, err := limits.SubjectKey(h.logger, query.UserID, query.Descriptors)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(query.Hierarchy) > 0 {
+		if query.Policy != "" || query.Limit != 0 || query.Window != 0 || len(query.Rules) > 0 {
+			h.logger.Error().Str("user_id", query.UserID).Msg("Hierarchy combined with own limits")
+			return nil, fmt.Errorf("hierarchy cannot be combined with limit, window, limits or policy")
+		}
+		query.Rules, err = limits.HierarchyRules(h.logger, query.UserID, query.Descriptors, query.Hierarchy)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Report the limits a single request is checked against, resolved the same way as the check
+	resolved, err := h.limits.Resolve(ctx, limits.Request{
+		UserID:      query.UserID,
+		Limit:       query.Limit,
+		Window:      query.Window,
+		Rules:       query.Rules,
+		Cost:        1,
+		Algorithm:   query.Algorithm,
+		Policy:      query.Policy,
+		Descriptors: query.Descriptors,
+	})
+	if err != nil {
+		return nil, err
+	}
+	query.Rules, query.Algorithm, query.Policy = resolved.Rules, resolved.Algorithm, ""
+	if resolved.Policy != nil {
+		query.Policy = resolved.Policy.Name
+	}
+
+	repository, err := h.repositoryProvider.Repository(query.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.RateLimitResult, len(query.Rules))
+	for i, rule := range query.Rules {
+		result, err := repository.Peek(key, rule)
+		if err != nil {
+			h.logger.Error().Str("user_id", query.UserID).Dur("window", rule.Window).Err(err).Msg("Failed to peek rate limit")
+			return nil, fmt.Errorf("failed to get rate limit status: %w", err)
+		}
+		results[i] = *result
+	}
+
+	// The limit that constrains the next request the most is the one reported
+	compound := domain.NewCompoundRateLimitResult(results)
+	binding, rule := compound.Binding(), query.Rules[compound.BindingIndex]
+	response := &GetRateLimitStatusResponse{
+		Limit:      binding.Limit,
+		Used:       binding.Limit - binding.Remaining,
+		Remaining:  binding.Remaining,
+		ResetTime:  binding.ResetAfter,
+		RetryAfter: binding.RetryAfter,
+		Allowed:    compound.Allowed,
+		Window:     rule.Window,
+		Algorithm:  query.Algorithm,
+		Policy:     query.Policy,
+		Overridden: resolved.Override != nil,
+		Level:      rule.Level,
+	}
+
+	h.logger.Info().Str("user_id", query.UserID).Int("limit", response.Limit).Int("used", response.Used).Int("remaining", response.Remaining).Dur("reset_time", response.ResetTime).Msg("Rate limit status query completed")
+
+	return response, nil
+}
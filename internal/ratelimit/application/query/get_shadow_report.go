@@ -0,0 +1,68 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+const (
+	// DefaultShadowReportLimit is the number of subjects reported when the query does not set a limit
+	DefaultShadowReportLimit = 100
+
+	// MaxShadowReportLimit caps the number of subjects reported by a single query
+	MaxShadowReportLimit = 1000
+)
+
+// GetShadowReportQuery represents a query for the subjects a shadow policy would have denied
+type GetShadowReportQuery struct {
+	Policy string
+	Limit  int
+}
+
+// GetShadowReportQueryHandler handles shadow policy report queries
+type GetShadowReportQueryHandler struct {
+	logger    logger.Logger
+	shadowLog ports.ShadowLog
+}
+
+// NewGetShadowReportQueryHandler creates a new shadow report query handler
+func NewGetShadowReportQueryHandler(
+	logger logger.Logger,
+	shadowLog ports.ShadowLog,
+) *GetShadowReportQueryHandler {
+	return &GetShadowReportQueryHandler{
+		logger:    logger,
+		shadowLog: shadowLog,
+	}
+}
+
+// Handle returns the subjects the policy would have denied within the retention period, most recently denied first
+func (h *GetShadowReportQueryHandler) Handle(ctx context.Context, query GetShadowReportQuery) ([]domain.ShadowDenial, error) {
+	h.logger.Debug().Str("policy", query.Policy).Int("limit", query.Limit).Msg("Processing shadow report query")
+
+	if query.Policy == "" {
+		h.logger.Error().Msg("Invalid policy provided")
+		return nil, fmt.Errorf("policy cannot be empty")
+	}
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultShadowReportLimit
+	}
+	if query.Limit > MaxShadowReportLimit {
+		query.Limit = MaxShadowReportLimit
+	}
+
+	denials, err := h.shadowLog.Report(ctx, query.Policy, query.Limit)
+	if err != nil {
+		h.logger.Error().Str("policy", query.Policy).Err(err).Msg("Failed to load shadow report")
+		return nil, fmt.Errorf("failed to load shadow report: %w", err)
+	}
+
+	h.logger.Debug().Str("policy", query.Policy).Int("subjects", len(denials)).Msg("Shadow report query completed")
+
+	return denials, nil
+}
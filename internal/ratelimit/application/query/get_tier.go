@@ -0,0 +1,50 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// GetTierQuery represents a query for the tier a user is assigned to
+type GetTierQuery struct {
+	UserID string
+}
+
+// GetTierQueryHandler handles tier assignment queries
+type GetTierQueryHandler struct {
+	logger     logger.Logger
+	repository ports.TierRepository
+}
+
+// NewGetTierQueryHandler creates a new tier assignment query handler
+func NewGetTierQueryHandler(
+	logger logger.Logger,
+	repository ports.TierRepository,
+) *GetTierQueryHandler {
+	return &GetTierQueryHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle executes the tier assignment query
+func (h *GetTierQueryHandler) Handle(ctx context.Context, query GetTierQuery) (*domain.TierAssignment, error) {
+	h.logger.Debug().Str("user_id", query.UserID).Msg("Processing tier assignment query")
+
+	if query.UserID == "" {
+		h.logger.Error().Str("user_id", query.UserID).Msg("Invalid user ID provided")
+		return nil, fmt.Errorf("user ID cannot be empty")
+	}
+
+	assignment, err := h.repository.Get(ctx, query.UserID)
+	if err != nil {
+		h.logger.Error().Str("user_id", query.UserID).Err(err).Msg("Failed to get tier assignment")
+		return nil, fmt.Errorf("failed to get tier assignment: %w", err)
+	}
+
+	return assignment, nil
+}
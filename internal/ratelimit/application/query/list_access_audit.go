@@ -0,0 +1,62 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+const (
+	// DefaultAuditLimit is the number of audit entries returned when the query does not set a limit
+	DefaultAuditLimit = 100
+
+	// MaxAuditLimit caps the number of audit entries returned by a single query
+	MaxAuditLimit = 1000
+)
+
+// ListAccessAuditQuery represents a query for the most recent changes to the allow and deny lists
+type ListAccessAuditQuery struct {
+	Limit int
+}
+
+// ListAccessAuditQueryHandler handles queries for the access rule audit trail
+type ListAccessAuditQueryHandler struct {
+	logger     logger.Logger
+	repository ports.AccessRuleRepository
+}
+
+// NewListAccessAuditQueryHandler creates a new access rule audit query handler
+func NewListAccessAuditQueryHandler(
+	logger logger.Logger,
+	repository ports.AccessRuleRepository,
+) *ListAccessAuditQueryHandler {
+	return &ListAccessAuditQueryHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle returns the most recent audit entries, newest first
+func (h *ListAccessAuditQueryHandler) Handle(ctx context.Context, query ListAccessAuditQuery) ([]domain.AccessAuditEntry, error) {
+	h.logger.Debug().Int("limit", query.Limit).Msg("Processing access rule audit query")
+
+	if query.Limit <= 0 {
+		query.Limit = DefaultAuditLimit
+	}
+	if query.Limit > MaxAuditLimit {
+		query.Limit = MaxAuditLimit
+	}
+
+	entries, err := h.repository.Audit(ctx, query.Limit)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list access rule audit entries")
+		return nil, fmt.Errorf("failed to list access rule audit entries: %w", err)
+	}
+
+	h.logger.Debug().Int("entries", len(entries)).Msg("Access rule audit query completed")
+
+	return entries, nil
+}
@@ -0,0 +1,42 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// ListAccessRulesQueryHandler handles queries for the allow and deny lists
+type ListAccessRulesQueryHandler struct {
+	logger     logger.Logger
+	repository ports.AccessRuleRepository
+}
+
+// NewListAccessRulesQueryHandler creates a new access rule listing query handler
+func NewListAccessRulesQueryHandler(
+	logger logger.Logger,
+	repository ports.AccessRuleRepository,
+) *ListAccessRulesQueryHandler {
+	return &ListAccessRulesQueryHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle returns every access rule ordered by ID
+func (h *ListAccessRulesQueryHandler) Handle(ctx context.Context) ([]domain.AccessRule, error) {
+	h.logger.Debug().Msg("Processing access rule listing query")
+
+	rules, err := h.repository.List(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list access rules")
+		return nil, fmt.Errorf("failed to list access rules: %w", err)
+	}
+
+	h.logger.Debug().Int("rules", len(rules)).Msg("Access rule listing query completed")
+
+	return rules, nil
+}
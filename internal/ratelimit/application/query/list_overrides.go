@@ -0,0 +1,42 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// ListOverridesQueryHandler handles queries for every rate limit override
+type ListOverridesQueryHandler struct {
+	logger     logger.Logger
+	repository ports.OverrideRepository
+}
+
+// NewListOverridesQueryHandler creates a new override listing query handler
+func NewListOverridesQueryHandler(
+	logger logger.Logger,
+	repository ports.OverrideRepository,
+) *ListOverridesQueryHandler {
+	return &ListOverridesQueryHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle returns every override ordered by user ID, including expired ones
+func (h *ListOverridesQueryHandler) Handle(ctx context.Context) ([]domain.Override, error) {
+	h.logger.Debug().Msg("Processing override listing query")
+
+	overrides, err := h.repository.List(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list overrides")
+		return nil, fmt.Errorf("failed to list overrides: %w", err)
+	}
+
+	h.logger.Debug().Int("overrides", len(overrides)).Msg("Override listing query completed")
+
+	return overrides, nil
+}
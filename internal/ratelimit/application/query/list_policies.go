@@ -0,0 +1,42 @@
+package query
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// ListPoliciesQueryHandler handles queries for every rate limit policy
+type ListPoliciesQueryHandler struct {
+	logger     logger.Logger
+	repository ports.PolicyRepository
+}
+
+// NewListPoliciesQueryHandler creates a new policy listing query handler
+func NewListPoliciesQueryHandler(
+	logger logger.Logger,
+	repository ports.PolicyRepository,
+) *ListPoliciesQueryHandler {
+	return &ListPoliciesQueryHandler{
+		logger:     logger,
+		repository: repository,
+	}
+}
+
+// Handle returns every policy ordered by name
+func (h *ListPoliciesQueryHandler) Handle(ctx context.Context) ([]domain.Policy, error) {
+	h.logger.Debug().Msg("Processing policy listing query")
+
+	policies, err := h.repository.List(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list policies")
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	h.logger.Debug().Int("policies", len(policies)).Msg("Policy listing query completed")
+
+	return policies, nil
+}
@@ -0,0 +1,162 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrAccessRuleNotFound is returned when no access rule exists with the requested ID
+	ErrAccessRuleNotFound = errors.New("access rule not found")
+
+	// ErrAccessRuleExists is returned when creating an access rule that is already on the list
+	ErrAccessRuleExists = errors.New("access rule already exists")
+)
+
+// AccessList is the list an access rule puts its subjects on
+type AccessList string
+
+const (
+	// AccessAllow exempts matching requests from every rate limit
+	AccessAllow AccessList = "allow"
+	// AccessDeny rejects matching requests outright
+	AccessDeny AccessList = "deny"
+)
+
+// IsValid returns true if the list is supported
+func (l AccessList) IsValid() bool {
+	return l == AccessAllow || l == AccessDeny
+}
+
+// AccessMatch is how an access rule matches a request
+type AccessMatch string
+
+const (
+	// MatchID matches a user ID or API key exactly
+	MatchID AccessMatch = "id"
+	// MatchPrefix matches user IDs and API keys starting with the value, e.g. "svc-" for service accounts
+	MatchPrefix AccessMatch = "prefix"
+	// MatchCIDR matches IP addresses within a network, e.g. "10.0.0.0/8"
+	MatchCIDR AccessMatch = "cidr"
+)
+
+// IsValid returns true if the match type is supported
+func (m AccessMatch) IsValid() bool {
+	return m == MatchID || m == MatchPrefix || m == MatchCIDR
+}
+
+// AccessRule puts the requests it matches on the allow or deny list
+type AccessRule struct {
+	ID        int64
+	List      AccessList
+	Match     AccessMatch
+	Value     string
+	Reason    string
+	CreatedBy string
+	CreatedAt time.Time
+}
+
+// Validate checks the rule and brings a CIDR value into its canonical form, so that the same network
+// cannot be listed twice under different spellings
+func (r *AccessRule) Validate() error {
+	if !r.List.IsValid() {
+		return fmt.Errorf("list must be allow or deny, got %q", r.List)
+	}
+
+	if !r.Match.IsValid() {
+		return fmt.Errorf("match must be id, prefix or cidr, got %q", r.Match)
+	}
+
+	if r.Value == "" {
+		return fmt.Errorf("value cannot be empty")
+	}
+
+	if r.Match == MatchCIDR {
+		network, err := netip.ParsePrefix(r.Value)
+		if err != nil {
+			return fmt.Errorf("value must be a CIDR such as 10.0.0.0/8: %w", err)
+		}
+		r.Value = network.Masked().String()
+	}
+
+	return nil
+}
+
+// Matches returns true if the rule applies to the subject
+func (r *AccessRule) Matches(subject AccessSubject) bool {
+	switch r.Match {
+	case MatchID:
+		return (subject.UserID != "" && subject.UserID == r.Value) || (subject.APIKey != "" && subject.APIKey == r.Value)
+	case MatchPrefix:
+		return (subject.UserID != "" && strings.HasPrefix(subject.UserID, r.Value)) ||
+			(subject.APIKey != "" && strings.HasPrefix(subject.APIKey, r.Value))
+	case MatchCIDR:
+		if !subject.IP.IsValid() {
+			return false
+		}
+		network, err := netip.ParsePrefix(r.Value)
+		return err == nil && network.Contains(subject.IP.Unmap())
+	}
+	return false
+}
+
+// AccessSubject holds the identities of a request that access rules are matched against
+type AccessSubject struct {
+	UserID string
+	APIKey string
+	IP     netip.Addr
+}
+
+// NewAccessSubject collects the identities of a request from its user and descriptors
+func NewAccessSubject(userId string, descriptors Descriptors) AccessSubject {
+	subject := AccessSubject{UserID: userId, APIKey: descriptors[DescriptorAPIKey]}
+	if ip, ok := descriptors[DescriptorIP]; ok {
+		subject.IP, _ = netip.ParseAddr(ip)
+	}
+	return subject
+}
+
+// Decide returns the rule deciding the access of the subject, or nil when no rule matches and the request is rate
+// limited as usual. The deny list wins over the allow list, so a blocked user cannot be exempted by a broader rule.
+func Decide(rules []AccessRule, subject AccessSubject) *AccessRule {
+	var allowed *AccessRule
+	for i := range rules {
+		rule := &rules[i]
+		if !rule.Matches(subject) {
+			continue
+		}
+		if rule.List == AccessDeny {
+			return rule
+		}
+		if allowed == nil {
+			allowed = rule
+		}
+	}
+	return allowed
+}
+
+// AccessAuditEntry records a change made to the access lists
+type AccessAuditEntry struct {
+	ID     int64
+	RuleID int64
+	Action AccessAction
+	List   AccessList
+	Match  AccessMatch
+	Value  string
+	Reason string
+	Actor  string
+	At     time.Time
+}
+
+// AccessAction is the kind of change an audit entry records
+type AccessAction string
+
+const (
+	// AccessRuleCreated records a rule added to a list
+	AccessRuleCreated AccessAction = "created"
+	// AccessRuleDeleted records a rule removed from a list
+	AccessRuleDeleted AccessAction = "deleted"
+)
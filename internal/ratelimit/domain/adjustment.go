@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrAdjustUnsupported is returned when adjusting the window of an algorithm that keeps no count of requests
+	ErrAdjustUnsupported = errors.New("adjusting is only supported by the fixed window algorithm")
+
+	// ErrResetAfterBeyondWindow is returned when an adjustment would move the reset past the end of the window.
+	// Windows are aligned to multiples of their size, so the reset can only be brought forward.
+	ErrResetAfterBeyondWindow = errors.New("reset after cannot be past the end of the window")
+)
+
+// Adjustment describes an administrative change to a user's current window
+type Adjustment struct {
+	Count      *int          // New request count, nil keeps the current count
+	ResetAfter time.Duration // New time until the window resets, zero keeps the current expiry
+}
+
+// WindowState represents the stored state of a user's current window
+type WindowState struct {
+	Count      int
+	ResetAfter time.Duration
+}
@@ -0,0 +1,52 @@
+package domain
+
+import "fmt"
+
+// Algorithm represents a rate limiting algorithm
+type Algorithm string
+
+const (
+	AlgorithmFixedWindow          Algorithm = "fixed_window"
+	AlgorithmTokenBucket          Algorithm = "token_bucket"
+	AlgorithmSlidingWindowLog     Algorithm = "sliding_window_log"
+	AlgorithmSlidingWindowCounter Algorithm = "sliding_window_counter"
+	AlgorithmGCRA                 Algorithm = "gcra"
+)
+
+// DefaultAlgorithm is used when a request does not select an algorithm
+const DefaultAlgorithm = AlgorithmFixedWindow
+
+// Algorithms lists every supported algorithm
+var Algorithms = []Algorithm{AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingWindowLog, AlgorithmSlidingWindowCounter, AlgorithmGCRA}
+
+// ParseAlgorithm converts a string into an Algorithm, falling back to DefaultAlgorithm when empty
+func ParseAlgorithm(value string) (Algorithm, error) {
+	if value == "" {
+		return DefaultAlgorithm, nil
+	}
+
+	algorithm := Algorithm(value)
+	if !algorithm.IsValid() {
+		return "", fmt.Errorf("unsupported rate limit algorithm: %s", value)
+	}
+	return algorithm, nil
+}
+
+// Windowed returns true if the algorithm counts requests into windows, so that a refund must name the window
+// the request was counted in
+func (a Algorithm) Windowed() bool {
+	switch a {
+	case AlgorithmFixedWindow, AlgorithmSlidingWindowLog, AlgorithmSlidingWindowCounter:
+		return true
+	}
+	return false
+}
+
+// IsValid returns true if the algorithm is supported
+func (a Algorithm) IsValid() bool {
+	switch a {
+	case AlgorithmFixedWindow, AlgorithmTokenBucket, AlgorithmSlidingWindowLog, AlgorithmSlidingWindowCounter, AlgorithmGCRA:
+		return true
+	}
+	return false
+}
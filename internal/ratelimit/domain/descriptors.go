@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"fmt"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// Descriptor names a dimension a request is counted by, alongside or instead of the user
+type Descriptor string
+
+const (
+	// DescriptorUser counts requests per user
+	DescriptorUser Descriptor = "user"
+	// DescriptorAPIKey counts requests per API key
+	DescriptorAPIKey Descriptor = "api_key"
+	// DescriptorIP counts requests per client IP address
+	DescriptorIP Descriptor = "ip"
+	// DescriptorRoute counts requests per route
+	DescriptorRoute Descriptor = "route"
+	// DescriptorMethod counts requests per HTTP method
+	DescriptorMethod Descriptor = "method"
+	// DescriptorTenant counts requests per tenant
+	DescriptorTenant Descriptor = "tenant"
+)
+
+// IsValid returns true if the descriptor is supported
+func (d Descriptor) IsValid() bool {
+	switch d {
+	case DescriptorUser, DescriptorAPIKey, DescriptorIP, DescriptorRoute, DescriptorMethod, DescriptorTenant:
+		return true
+	}
+	return false
+}
+
+// Descriptors holds the values of the dimensions a request is counted by.
+// Requests share a counter exactly when they have the same descriptors with the same values.
+type Descriptors map[Descriptor]string
+
+// Validate checks that every descriptor is supported and has a value
+func (d Descriptors) Validate() error {
+	for name, value := range d {
+		if !name.IsValid() {
+			return fmt.Errorf("unsupported descriptor: %s", name)
+		}
+		if value == "" {
+			return fmt.Errorf("descriptor %s cannot be empty", name)
+		}
+		if name == DescriptorIP {
+			if _, err := netip.ParseAddr(value); err != nil {
+				return fmt.Errorf("descriptor ip must be an IP address: %s", value)
+			}
+		}
+	}
+	return nil
+}
+
+// Canonical returns the descriptors with every value in a single spelling, so that requests naming the same
+// client, method or route differently share a counter. It expects valid descriptors.
+func (d Descriptors) Canonical() Descriptors {
+	canonical := make(Descriptors, len(d))
+	for name, value := range d {
+		switch name {
+		case DescriptorIP:
+			// IPv4 addresses written as IPv6 are the same client
+			if addr, err := netip.ParseAddr(value); err == nil {
+				value = addr.Unmap().String()
+			}
+		case DescriptorMethod:
+			value = strings.ToUpper(value)
+		}
+		canonical[name] = value
+	}
+	return canonical
+}
+
+// Key returns the storage key identifying the counters of the descriptors.
+// A user alone is keyed by its ID, as before descriptors existed, and any other combination by its name=value
+// pairs sorted by name. Values are escaped, so that no value can forge a separator and collide with another key.
+func (d Descriptors) Key() string {
+	if len(d) == 1 {
+		if user, ok := d[DescriptorUser]; ok {
+			return escapeKeyPart(user)
+		}
+	}
+
+	names := make([]string, 0, len(d))
+	for name := range d {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = name + "=" + escapeKeyPart(d[Descriptor(name)])
+	}
+	return strings.Join(parts, ":")
+}
+
+// UserKey returns the storage key of a user's counters
+func UserKey(userId string) string {
+	return Descriptors{DescriptorUser: userId}.Key()
+}
+
+// keyEscaper percent-encodes the separators of a storage key, and the escape character itself
+var keyEscaper = strings.NewReplacer("%", "%25", ":", "%3A", "=", "%3D")
+
+// escapeKeyPart escapes a descriptor value for use in a storage key
+func escapeKeyPart(value string) string {
+	return keyEscaper.Replace(value)
+}
@@ -0,0 +1,55 @@
+package domain
+
+import "fmt"
+
+// Level is a level of the rate limit hierarchy. A hierarchical check consumes from the limit of every level at once,
+// so that the users of a tenant share its limit on top of their own, and each endpoint is limited within the user's.
+type Level string
+
+const (
+	// LevelTenant is counted per tenant, across all of its users
+	LevelTenant Level = "tenant"
+	// LevelUser is counted per user, sharing its counters with checks that name only the user
+	LevelUser Level = "user"
+	// LevelEndpoint is counted per user and route, and per method when given
+	LevelEndpoint Level = "endpoint"
+)
+
+// Levels lists the levels of the hierarchy from the broadest to the narrowest
+var Levels = []Level{LevelTenant, LevelUser, LevelEndpoint}
+
+// IsValid returns true if the level is part of the hierarchy
+func (l Level) IsValid() bool {
+	switch l {
+	case LevelTenant, LevelUser, LevelEndpoint:
+		return true
+	}
+	return false
+}
+
+// Descriptors returns the descriptors identifying the level's counter for a request, or an error when the request
+// lacks one of them. The descriptors must not contain the user, which is given separately.
+func (l Level) Descriptors(userId string, descriptors Descriptors) (Descriptors, error) {
+	level := Descriptors{}
+	required := map[Level][]Descriptor{
+		LevelTenant:   {DescriptorTenant},
+		LevelUser:     {DescriptorUser},
+		LevelEndpoint: {DescriptorUser, DescriptorRoute},
+	}[l]
+
+	for _, name := range required {
+		value := descriptors[name]
+		if name == DescriptorUser {
+			value = userId
+		}
+		if value == "" {
+			return nil, fmt.Errorf("the %s level requires the %s descriptor", l, name)
+		}
+		level[name] = value
+	}
+
+	if method, ok := descriptors[DescriptorMethod]; ok && l == LevelEndpoint {
+		level[DescriptorMethod] = method
+	}
+	return level, nil
+}
@@ -0,0 +1,21 @@
+package domain
+
+import "time"
+
+// DefaultLeaseTTL is how long a lease is held when a request does not specify it
+const DefaultLeaseTTL = 30 * time.Second
+
+// LeaseResult represents the outcome of acquiring a concurrency lease
+type LeaseResult struct {
+	Acquired   bool
+	LeaseID    string // Empty when the lease was not acquired
+	Limit      int
+	InFlight   int // Leases held by the user, including the acquired one
+	ExpiresIn  time.Duration
+	RetryAfter time.Duration // Time until the earliest held lease expires, zero when acquired
+}
+
+// Remaining returns the number of leases the user can still acquire
+func (r *LeaseResult) Remaining() int {
+	return max(r.Limit-r.InFlight, 0)
+}
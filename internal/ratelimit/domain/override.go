@@ -0,0 +1,76 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrOverrideNotFound is returned when a user has no stored override
+var ErrOverrideNotFound = errors.New("rate limit override not found")
+
+// Override changes the limits applied to a single user, either by scaling the requested limits or by replacing them with a fixed limit
+type Override struct {
+	UserID     string
+	Multiplier float64       // Factor applied to every requested limit, zero when the override sets a fixed limit
+	Limit      int           // Fixed limit replacing the requested limits, zero when the override scales them
+	Window     time.Duration // Window of the fixed limit
+	Reason     string
+	ExpiresAt  *time.Time // Nil when the override never expires
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Validate checks that the override either scales or replaces the limits, but not both
+func (o *Override) Validate() error {
+	if o.UserID == "" {
+		return fmt.Errorf("user ID cannot be empty")
+	}
+
+	if o.Multiplier < 0 || math.IsNaN(o.Multiplier) || math.IsInf(o.Multiplier, 0) {
+		return fmt.Errorf("multiplier must be greater than 0")
+	}
+
+	if o.Limit < 0 {
+		return fmt.Errorf("limit must be greater than 0")
+	}
+
+	if (o.Multiplier > 0) == (o.Limit > 0) {
+		return fmt.Errorf("exactly one of multiplier and limit must be set")
+	}
+
+	if o.Limit > 0 && o.Window < MinWindow {
+		return fmt.Errorf("window must be at least %s", MinWindow)
+	}
+
+	return nil
+}
+
+// Active returns true if the override has not expired at now
+func (o *Override) Active(now time.Time) bool {
+	return o.ExpiresAt == nil || now.Before(*o.ExpiresAt)
+}
+
+// Apply returns the rules to enforce for the user instead of the requested ones.
+// Scaled limits and bursts are rounded down but never below one request.
+func (o *Override) Apply(rules []Rule) []Rule {
+	if o.Limit > 0 {
+		return []Rule{{Limit: o.Limit, Window: o.Window}}
+	}
+
+	scaled := make([]Rule, len(rules))
+	for i, rule := range rules {
+		scaled[i] = rule
+		scaled[i].Limit = o.scale(rule.Limit)
+		if rule.Burst > 0 {
+			scaled[i].Burst = o.scale(rule.Burst)
+		}
+	}
+	return scaled
+}
+
+// scale multiplies a limit by the override's multiplier
+func (o *Override) scale(limit int) int {
+	return max(int(math.Floor(float64(limit)*o.Multiplier)), 1)
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+var (
+	// ErrPolicyNotFound is returned when no policy exists with the requested name
+	ErrPolicyNotFound = errors.New("rate limit policy not found")
+
+	// ErrPolicyExists is returned when creating a policy whose name is already taken
+	ErrPolicyExists = errors.New("rate limit policy already exists")
+
+	// ErrPolicyInUse is returned when deleting a policy that users are assigned to as their tier
+	ErrPolicyInUse = errors.New("rate limit policy is assigned to users as their tier")
+
+	// ErrPolicyRequired is returned when a check passes a raw limit while policies are enforced
+	ErrPolicyRequired = errors.New("a rate limit policy is required")
+)
+
+// policyNamePattern restricts policy names to identifiers that are safe in URLs and logs
+var policyNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// PolicyMode is how a policy treats requests exceeding its limit
+type PolicyMode string
+
+const (
+	// PolicyEnforce denies requests exceeding the limit
+	PolicyEnforce PolicyMode = "enforce"
+	// PolicyShadow counts requests as usual but only records the ones it would deny, so a stricter limit can be tried out first
+	PolicyShadow PolicyMode = "shadow"
+)
+
+// DefaultPolicyMode is the mode of policies that do not set one
+const DefaultPolicyMode = PolicyEnforce
+
+// IsValid returns true if the mode is supported
+func (m PolicyMode) IsValid() bool {
+	return m == PolicyEnforce || m == PolicyShadow
+}
+
+// Policy is a named rate limit managed by administrators, so that callers reference it instead of sending their own limit
+type Policy struct {
+	Name      string
+	Limit     int
+	Window    time.Duration
+	Algorithm Algorithm
+	Burst     int // Requests tolerated back to back by the token bucket and GCRA algorithms, zero uses the configured burst
+	Mode      PolicyMode
+	Schedules []Schedule // Checked in order, the first active one replaces the limit
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Validate checks that the policy can be enforced
+func (p *Policy) Validate() error {
+	if !policyNamePattern.MatchString(p.Name) {
+		return fmt.Errorf("policy name must be 1 to 64 lowercase letters, digits, '.', '_' or '-' starting with a letter or digit")
+	}
+
+	return p.validateLimit()
+}
+
+// validateLimit checks the limit, window, algorithm and burst of the policy
+func (p *Policy) validateLimit() error {
+	if p.Limit <= 0 {
+		return fmt.Errorf("limit must be greater than 0")
+	}
+
+	if p.Window < MinWindow {
+		return fmt.Errorf("window must be at least %s", MinWindow)
+	}
+
+	if !p.Algorithm.IsValid() {
+		return fmt.Errorf("unsupported rate limit algorithm: %s", p.Algorithm)
+	}
+
+	if p.Burst < 0 {
+		return fmt.Errorf("burst cannot be negative")
+	}
+
+	if !p.Mode.IsValid() {
+		return fmt.Errorf("mode must be enforce or shadow, got %q", p.Mode)
+	}
+
+	for i, schedule := range p.Schedules {
+		if err := schedule.Validate(); err != nil {
+			return fmt.Errorf("schedule %d: %w", i+1, err)
+		}
+	}
+
+	return nil
+}
+
+// Rule returns the rule the policy enforces outside of its schedules
+func (p *Policy) Rule() Rule {
+	return Rule{Limit: p.Limit, Window: p.Window, Burst: p.Burst}
+}
+
+// RuleAt returns the rule the policy enforces at the given time, taken from the first active schedule if any
+func (p *Policy) RuleAt(now time.Time) Rule {
+	rule := p.Rule()
+	for _, schedule := range p.Schedules {
+		if !schedule.Active(now) {
+			continue
+		}
+		rule.Limit = schedule.Limit
+		if schedule.Window != 0 {
+			rule.Window = schedule.Window
+		}
+		if schedule.Burst != 0 {
+			rule.Burst = schedule.Burst
+		}
+		break
+	}
+	return rule
+}
@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PolicySet holds the policies declared in the policy file, keyed by route, tenant and tier.
+// Keys are lowercase, since the file is read case-insensitively.
+type PolicySet struct {
+	Routes  map[string]Policy
+	Tenants map[string]Policy
+	Tiers   map[string]Policy
+}
+
+// Validate checks that every declared policy can be enforced
+func (s *PolicySet) Validate() error {
+	for _, policies := range []map[string]Policy{s.Routes, s.Tenants, s.Tiers} {
+		for key, policy := range policies {
+			if key == "" {
+				return fmt.Errorf("%s: key cannot be empty", policy.Name)
+			}
+			if err := policy.validateLimit(); err != nil {
+				return fmt.Errorf("%s: %w", policy.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Match returns the policy declared for the route, else for the tenant, else for the tier, the most specific one winning.
+// Empty arguments are skipped. It returns false when the set declares none of them.
+func (s *PolicySet) Match(route, tenant, tier string) (*Policy, bool) {
+	candidates := []struct {
+		policies map[string]Policy
+		key      string
+	}{
+		{s.Routes, route},
+		{s.Tenants, tenant},
+		{s.Tiers, tier},
+	}
+	for _, candidate := range candidates {
+		if candidate.key == "" {
+			continue
+		}
+		if policy, ok := candidate.policies[strings.ToLower(candidate.key)]; ok {
+			return &policy, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Period represents a calendar period a quota resets on
+type Period string
+
+const (
+	PeriodDay   Period = "day"
+	PeriodWeek  Period = "week"
+	PeriodMonth Period = "month"
+	PeriodYear  Period = "year"
+)
+
+// DefaultPeriod is used when a quota does not select a period
+const DefaultPeriod = PeriodMonth
+
+// ParsePeriod converts a string into a Period, falling back to DefaultPeriod when empty
+func ParsePeriod(value string) (Period, error) {
+	if value == "" {
+		return DefaultPeriod, nil
+	}
+
+	period := Period(value)
+	if !period.IsValid() {
+		return "", fmt.Errorf("unsupported quota period: %s", value)
+	}
+	return period, nil
+}
+
+// IsValid returns true if the period is supported
+func (p Period) IsValid() bool {
+	switch p {
+	case PeriodDay, PeriodWeek, PeriodMonth, PeriodYear:
+		return true
+	}
+	return false
+}
+
+// Bounds returns the start and end of the calendar period containing now, as observed in the location.
+// Days start at midnight, weeks on Monday, months on the 1st and years on January 1st.
+func (p Period) Bounds(now time.Time, location *time.Location) (time.Time, time.Time) {
+	local := now.In(location)
+	year, month, day := local.Date()
+
+	switch p {
+	case PeriodDay:
+		start := time.Date(year, month, day, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 0, 1)
+	case PeriodWeek:
+		// Go weeks start on Sunday, calendar weeks on Monday
+		offset := (int(local.Weekday()) + 6) % 7
+		start := time.Date(year, month, day-offset, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 0, 7)
+	case PeriodYear:
+		start := time.Date(year, time.January, 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(1, 0, 0)
+	default:
+		start := time.Date(year, month, 1, 0, 0, 0, 0, location)
+		return start, start.AddDate(0, 1, 0)
+	}
+}
+
+// Quota describes a maximum number of requests allowed within a calendar period
+type Quota struct {
+	Limit    int
+	Period   Period
+	Location *time.Location // Time zone the period boundaries are aligned to
+}
+
+// QuotaResult represents the outcome of a quota check
+type QuotaResult struct {
+	Allowed     bool
+	Limit       int
+	Used        int
+	Remaining   int
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	ResetAfter  time.Duration
+	RetryAfter  time.Duration // Zero when the request is allowed
+}
+
+// QuotaUsage is the number of units a user used during one calendar period
+type QuotaUsage struct {
+	UserID      string
+	Period      Period
+	PeriodStart time.Time
+	Used        int
+}
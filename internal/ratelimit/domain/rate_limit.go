@@ -4,6 +4,12 @@ import (
 	"time"
 )
 
+// DefaultWindow is the rate limit window used when a request does not specify one
+const DefaultWindow = time.Minute
+
+// MinWindow is the smallest supported rate limit window
+const MinWindow = time.Millisecond
+
 // RateLimit represents a rate limit entity
 type RateLimit struct {
 	UserID    string
@@ -44,4 +50,13 @@ func (rl *RateLimit) IsAllowed() bool {
 func (rl *RateLimit) Reset() {
 	rl.Remaining = rl.Limit
 	rl.ResetTime = time.Now().Add(rl.Window)
-}
\ No newline at end of file
+}
+// RateLimitResult represents the outcome of a rate limit check
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+	RetryAfter time.Duration // Zero when the request is allowed
+	WindowID   int64         // Window the request is counted in, which a refund names; zero for algorithms without windows
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrCostExceedsCapacity is returned when a request costs more than a limit could ever admit at once
+	ErrCostExceedsCapacity = errors.New("cost exceeds the capacity of the limit")
+
+	// ErrWindowsRequired is returned when a refund to an algorithm counting into windows does not name the window
+	// of every limit, as reported by the check
+	ErrWindowsRequired = errors.New("the window IDs reported by the check are required")
+)
+
+// Rule describes a maximum number of requests allowed within a window
+type Rule struct {
+	Limit  int
+	Window time.Duration
+	Burst  int // Requests tolerated back to back by the token bucket and GCRA algorithms, zero uses the configured burst
+
+	// Subject is the storage key of the rule's counter when it differs from the key of the check, so that a single
+	// check can consume from counters shared by different subjects, such as a tenant and one of its users
+	Subject string
+	Level   Level // Level of the hierarchy the rule limits, if any
+}
+
+// Capacity returns the most units a single request can consume under the rule with the algorithm. The token bucket
+// and GCRA algorithms admit at most their burst at once, which is the rule's own burst, or else defaultBurst,
+// or else the limit. The other algorithms admit up to the limit.
+func (r Rule) Capacity(algorithm Algorithm, defaultBurst int) int {
+	if algorithm != AlgorithmTokenBucket && algorithm != AlgorithmGCRA {
+		return r.Limit
+	}
+	if r.Burst > 0 {
+		return r.Burst
+	}
+	if defaultBurst <= 0 {
+		return r.Limit
+	}
+	return defaultBurst
+}
+
+// CompoundRateLimitResult represents the outcome of checking several rules at once.
+// A request is only counted against the rules when every rule allows it.
+type CompoundRateLimitResult struct {
+	Allowed      bool
+	Results      []RateLimitResult // One result per rule, in the order the rules were given
+	BindingIndex int               // Index of the rule that constrains the request the most
+	ResetAfter   time.Duration     // Earliest reset across all rules
+	Approximate  bool              // Whether the request was counted locally, without confirming the counts with Redis
+}
+
+// NewCompoundRateLimitResult combines per-rule results. When the request is denied the binding rule
+// is the denying rule that blocks the longest, otherwise it is the rule with the fewest remaining requests.
+func NewCompoundRateLimitResult(results []RateLimitResult) *CompoundRateLimitResult {
+	compound := &CompoundRateLimitResult{
+		Allowed: true,
+		Results: results,
+	}
+
+	for i, result := range results {
+		if !result.Allowed {
+			compound.Allowed = false
+		}
+		if i == 0 || result.ResetAfter < compound.ResetAfter {
+			compound.ResetAfter = result.ResetAfter
+		}
+	}
+
+	for i, result := range results {
+		binding := results[compound.BindingIndex]
+		if compound.Allowed {
+			if result.Remaining < binding.Remaining ||
+				(result.Remaining == binding.Remaining && result.ResetAfter < binding.ResetAfter) {
+				compound.BindingIndex = i
+			}
+		} else if !result.Allowed && (binding.Allowed || result.RetryAfter > binding.RetryAfter) {
+			compound.BindingIndex = i
+		}
+	}
+
+	return compound
+}
+
+// Binding returns the result of the rule that constrains the request the most
+func (c *CompoundRateLimitResult) Binding() *RateLimitResult {
+	return &c.Results[c.BindingIndex]
+}
@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRuleCapacity(t *testing.T) {
+	tests := []struct {
+		name         string
+		rule         Rule
+		algorithm    Algorithm
+		defaultBurst int
+		want         int
+	}{
+		{"fixed window admits the limit", Rule{Limit: 100, Window: time.Minute, Burst: 5}, AlgorithmFixedWindow, 10, 100},
+		{"sliding window log admits the limit", Rule{Limit: 100, Window: time.Minute}, AlgorithmSlidingWindowLog, 10, 100},
+		{"token bucket admits the rule's burst", Rule{Limit: 100, Window: time.Minute, Burst: 5}, AlgorithmTokenBucket, 10, 5},
+		{"token bucket falls back to the configured burst", Rule{Limit: 100, Window: time.Minute}, AlgorithmTokenBucket, 10, 10},
+		{"gcra falls back to the configured burst", Rule{Limit: 100, Window: time.Minute}, AlgorithmGCRA, 10, 10},
+		{"gcra without any burst admits the limit", Rule{Limit: 100, Window: time.Minute}, AlgorithmGCRA, 0, 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.rule.Capacity(tt.algorithm, tt.defaultBurst); got != tt.want {
+				t.Errorf("Capacity() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
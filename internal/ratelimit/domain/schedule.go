@@ -0,0 +1,158 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// day is the length of a calendar day, ignoring daylight saving transitions
+const day = 24 * time.Hour
+
+// weekdays maps the abbreviations accepted in schedules to their weekday
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// Schedule replaces the limit of a policy during a recurring time range, e.g. every night from 22:00 to 06:00 in New York
+type Schedule struct {
+	Days     []time.Weekday // Days the range starts on, every day when empty
+	Start    time.Duration  // Time of day the range starts at, as an offset from midnight
+	End      time.Duration  // Time of day the range ends at, on the next day when not after Start
+	Location *time.Location // Time zone Days, Start and End are given in
+	Limit    int
+	Window   time.Duration // Zero keeps the window of the policy
+	Burst    int           // Zero keeps the burst of the policy
+}
+
+// ScheduleSpec holds a schedule as administrators write it, with its days, times and time zone as text
+type ScheduleSpec struct {
+	Days     []string // Three-letter weekdays such as "mon"
+	Start    string   // Time of day as HH:MM
+	End      string   // Time of day as HH:MM
+	TimeZone string   // IANA time zone name, UTC when empty
+	Limit    int
+	Window   time.Duration
+	Burst    int
+}
+
+// Parse validates the spec and converts it into a schedule
+func (s ScheduleSpec) Parse() (Schedule, error) {
+	schedule := Schedule{Limit: s.Limit, Window: s.Window, Burst: s.Burst}
+
+	for _, name := range s.Days {
+		weekday, ok := weekdays[strings.ToLower(name)]
+		if !ok {
+			return Schedule{}, fmt.Errorf("schedule day must be one of sun, mon, tue, wed, thu, fri or sat, got %q", name)
+		}
+		schedule.Days = append(schedule.Days, weekday)
+	}
+
+	var err error
+	if schedule.Start, err = parseTimeOfDay(s.Start); err != nil {
+		return Schedule{}, fmt.Errorf("schedule start: %w", err)
+	}
+	if schedule.End, err = parseTimeOfDay(s.End); err != nil {
+		return Schedule{}, fmt.Errorf("schedule end: %w", err)
+	}
+
+	if schedule.Location, err = time.LoadLocation(s.TimeZone); err != nil {
+		return Schedule{}, fmt.Errorf("schedule time zone: %w", err)
+	}
+
+	return schedule, schedule.Validate()
+}
+
+// Spec converts the schedule back into the form administrators write it in
+func (s Schedule) Spec() ScheduleSpec {
+	spec := ScheduleSpec{
+		Start:    formatTimeOfDay(s.Start),
+		End:      formatTimeOfDay(s.End),
+		TimeZone: s.Location.String(),
+		Limit:    s.Limit,
+		Window:   s.Window,
+		Burst:    s.Burst,
+	}
+	for _, weekday := range s.Days {
+		spec.Days = append(spec.Days, strings.ToLower(weekday.String()[:3]))
+	}
+	return spec
+}
+
+// Validate checks that the schedule can be enforced
+func (s Schedule) Validate() error {
+	if s.Limit <= 0 {
+		return fmt.Errorf("schedule limit must be greater than 0")
+	}
+
+	if s.Window != 0 && s.Window < MinWindow {
+		return fmt.Errorf("schedule window must be at least %s", MinWindow)
+	}
+
+	if s.Burst < 0 {
+		return fmt.Errorf("schedule burst cannot be negative")
+	}
+
+	if s.Start < 0 || s.Start >= day || s.End < 0 || s.End >= day {
+		return fmt.Errorf("schedule start and end must be times of day")
+	}
+
+	if s.Location == nil {
+		return fmt.Errorf("schedule time zone is required")
+	}
+
+	return nil
+}
+
+// Active returns true if the time falls within the range. A range ending at or before its start runs past midnight,
+// and belongs to the day it starts on; one ending exactly at its start lasts a whole day.
+func (s Schedule) Active(now time.Time) bool {
+	local := now.In(s.Location)
+	offset := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute + time.Duration(local.Second())*time.Second
+	weekday := local.Weekday()
+
+	if s.Start < s.End {
+		return offset >= s.Start && offset < s.End && s.startsOn(weekday)
+	}
+	if offset >= s.Start {
+		return s.startsOn(weekday)
+	}
+	if offset < s.End {
+		// The morning part of a range that started the day before
+		return s.startsOn((weekday + 6) % 7)
+	}
+	return false
+}
+
+// startsOn returns true if the range starts on the weekday
+func (s Schedule) startsOn(weekday time.Weekday) bool {
+	if len(s.Days) == 0 {
+		return true
+	}
+	for _, d := range s.Days {
+		if d == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// parseTimeOfDay parses an HH:MM time of day into its offset from midnight
+func parseTimeOfDay(value string) (time.Duration, error) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("must be a time of day such as 22:00, got %q", value)
+	}
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, nil
+}
+
+// formatTimeOfDay formats an offset from midnight as an HH:MM time of day
+func formatTimeOfDay(offset time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(offset/time.Hour), int(offset%time.Hour/time.Minute))
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("time zone %s not available: %v", name, err)
+	}
+	return location
+}
+
+func TestScheduleActive(t *testing.T) {
+	newYork := mustLoadLocation(t, "America/New_York")
+
+	// Friday nights in New York, running past midnight into Saturday morning
+	fridayNight := Schedule{Days: []time.Weekday{time.Friday}, Start: 22 * time.Hour, End: 6 * time.Hour, Location: newYork}
+	// Office hours in UTC on every day
+	officeHours := Schedule{Start: 9 * time.Hour, End: 17 * time.Hour, Location: time.UTC}
+	// The whole of Sunday in UTC, since a range ending at its start lasts a day
+	sunday := Schedule{Days: []time.Weekday{time.Sunday}, Location: time.UTC}
+
+	tests := []struct {
+		name     string
+		schedule Schedule
+		now      time.Time
+		want     bool
+	}{
+		{"before a range past midnight starts", fridayNight, time.Date(2026, time.October, 16, 21, 59, 0, 0, newYork), false},
+		{"at the start of a range past midnight", fridayNight, time.Date(2026, time.October, 16, 22, 0, 0, 0, newYork), true},
+		{"after midnight belongs to the day the range started", fridayNight, time.Date(2026, time.October, 17, 5, 59, 0, 0, newYork), true},
+		{"at the end of a range past midnight", fridayNight, time.Date(2026, time.October, 17, 6, 0, 0, 0, newYork), false},
+		{"on a day the range does not start on", fridayNight, time.Date(2026, time.October, 17, 23, 0, 0, 0, newYork), false},
+		{"morning after a day the range does not start on", fridayNight, time.Date(2026, time.October, 16, 3, 0, 0, 0, newYork), false},
+		{"observed in the schedule's time zone", fridayNight, time.Date(2026, time.October, 17, 3, 0, 0, 0, time.UTC), true},
+		{"not in the caller's time zone", fridayNight, time.Date(2026, time.October, 16, 23, 0, 0, 0, time.UTC), false},
+		{"before a range within a day", officeHours, time.Date(2026, time.October, 16, 8, 59, 59, 0, time.UTC), false},
+		{"within a range within a day", officeHours, time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC), true},
+		{"at the end of a range within a day", officeHours, time.Date(2026, time.October, 16, 17, 0, 0, 0, time.UTC), false},
+		{"converted into the schedule's time zone", officeHours, time.Date(2026, time.October, 16, 12, 0, 0, 0, newYork), true},
+		{"whole day range at midnight", sunday, time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC), true},
+		{"whole day range before midnight", sunday, time.Date(2026, time.October, 18, 23, 59, 59, 0, time.UTC), true},
+		{"whole day range on the next day", sunday, time.Date(2026, time.October, 19, 0, 0, 0, 0, time.UTC), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.schedule.Active(tt.now); got != tt.want {
+				t.Errorf("Active(%s) = %t, want %t", tt.now, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleSpecParse(t *testing.T) {
+	schedule, err := ScheduleSpec{Days: []string{"Fri"}, Start: "22:00", End: "06:30", TimeZone: "America/New_York", Limit: 10}.Parse()
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(schedule.Days) != 1 || schedule.Days[0] != time.Friday {
+		t.Errorf("days = %v, want [Friday]", schedule.Days)
+	}
+	if schedule.Start != 22*time.Hour || schedule.End != 6*time.Hour+30*time.Minute {
+		t.Errorf("start, end = %s, %s, want 22h0m0s, 6h30m0s", schedule.Start, schedule.End)
+	}
+	if schedule.Location.String() != "America/New_York" {
+		t.Errorf("location = %s, want America/New_York", schedule.Location)
+	}
+
+	invalid := []ScheduleSpec{
+		{Days: []string{"someday"}, Start: "22:00", End: "06:00", Limit: 10},
+		{Start: "24:00", End: "06:00", Limit: 10},
+		{Start: "22:00", End: "06:00", TimeZone: "Nowhere/Else", Limit: 10},
+		{Start: "22:00", End: "06:00"},
+	}
+	for _, spec := range invalid {
+		if _, err := spec.Parse(); err == nil {
+			t.Errorf("Parse(%+v) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestPolicyRuleAt(t *testing.T) {
+	policy := Policy{
+		Limit:  100,
+		Window: time.Minute,
+		Burst:  5,
+		Schedules: []Schedule{
+			// Nights keep the window and burst of the policy
+			{Start: 22 * time.Hour, End: 6 * time.Hour, Location: time.UTC, Limit: 10},
+			// Overlaps the night schedule, which is listed first and so wins
+			{Start: 23 * time.Hour, End: 23*time.Hour + 30*time.Minute, Location: time.UTC, Limit: 1, Window: time.Hour, Burst: 1},
+			{Start: 12 * time.Hour, End: 13 * time.Hour, Location: time.UTC, Limit: 50, Window: time.Hour, Burst: 2},
+		},
+	}
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want Rule
+	}{
+		{"outside every schedule", time.Date(2026, time.October, 16, 9, 0, 0, 0, time.UTC), Rule{Limit: 100, Window: time.Minute, Burst: 5}},
+		{"night schedule keeps window and burst", time.Date(2026, time.October, 16, 22, 30, 0, 0, time.UTC), Rule{Limit: 10, Window: time.Minute, Burst: 5}},
+		{"night schedule after midnight", time.Date(2026, time.October, 17, 1, 0, 0, 0, time.UTC), Rule{Limit: 10, Window: time.Minute, Burst: 5}},
+		{"first active schedule wins", time.Date(2026, time.October, 16, 23, 15, 0, 0, time.UTC), Rule{Limit: 10, Window: time.Minute, Burst: 5}},
+		{"schedule replacing window and burst", time.Date(2026, time.October, 16, 12, 30, 0, 0, time.UTC), Rule{Limit: 50, Window: time.Hour, Burst: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.RuleAt(tt.now); got != tt.want {
+				t.Errorf("RuleAt(%s) = %+v, want %+v", tt.now, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,10 @@
+package domain
+
+import "time"
+
+// ShadowDenial summarizes the requests of a subject that a shadow policy would have denied
+type ShadowDenial struct {
+	Subject      string // Storage key of the counters, the user ID for requests counted per user
+	Denials      int64
+	LastDeniedAt time.Time
+}
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrTierNotAssigned is returned when a user has not been assigned to a tier
+	ErrTierNotAssigned = errors.New("user is not assigned to a tier")
+
+	// ErrUnknownTier is returned when assigning users to a tier that has no policy
+	ErrUnknownTier = errors.New("unknown tier")
+)
+
+// TierAssignment assigns a user to a tier such as free, pro or enterprise.
+// The limits of a tier are those of the rate limit policy with the same name.
+type TierAssignment struct {
+	UserID     string
+	Tier       string
+	AssignedAt time.Time
+}
@@ -0,0 +1,183 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+const (
+	// accessRulesMinBackoff is the wait before retrying the first failed load of the access rules
+	accessRulesMinBackoff = time.Second
+	// accessRulesMaxBackoff caps the wait between retries while the access rules cannot be loaded
+	accessRulesMaxBackoff = time.Minute
+)
+
+// errAccessRulesUnavailable is returned when the access rules have never been loaded and are not being retried yet
+var errAccessRulesUnavailable = errors.New("access rules not loaded")
+
+// CachedAccessRuleRepository implements the AccessRuleRepository interface by caching every rule of a
+// PostgresAccessRuleRepository in memory, so rate limit checks are decided without reaching PostgreSQL.
+// Prefix and CIDR rules can match any subject, so the lists are cached whole rather than per subject.
+// Changes made through this instance apply immediately, changes made through other instances within the TTL.
+// When the rules cannot be reloaded the last rules loaded keep applying, and reloads are retried with an
+// exponential backoff, so an outage of PostgreSQL neither reaches it on every check nor lifts the deny list.
+type CachedAccessRuleRepository struct {
+	logger logger.Logger
+	store  ports.AccessRuleRepository
+	ttl    time.Duration
+
+	mu        sync.Mutex
+	rules     []domain.AccessRule
+	loaded    bool      // Whether rules holds a set loaded from PostgreSQL
+	loadedAt  time.Time // When rules were last loaded
+	expiresAt time.Time // When rules are due to be reloaded
+	reloading bool      // Whether a check is reloading the rules, so that others keep using the current ones
+	failures  int       // Consecutive failed reloads
+	retryAt   time.Time // When the next reload may be tried after a failure
+}
+
+// NewCachedAccessRuleRepository creates a new in-memory cache in front of the PostgreSQL access rule repository
+func NewCachedAccessRuleRepository(
+	logger logger.Logger,
+	store *PostgresAccessRuleRepository,
+	cfg *config.Config,
+) *CachedAccessRuleRepository {
+	return &CachedAccessRuleRepository{
+		logger: logger,
+		store:  store,
+		ttl:    cfg.RateLimit.CacheTTL,
+	}
+}
+
+// Create adds the rule and reloads the cached rules
+func (r *CachedAccessRuleRepository) Create(ctx context.Context, rule *domain.AccessRule, actor string) error {
+	if err := r.store.Create(ctx, rule, actor); err != nil {
+		return err
+	}
+
+	r.reload(ctx)
+	return nil
+}
+
+// Decide matches the subject against the cached rules, loading them from PostgreSQL when missing or stale
+func (r *CachedAccessRuleRepository) Decide(ctx context.Context, subject domain.AccessSubject) (*domain.AccessRule, error) {
+	rules, err := r.current(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rule := domain.Decide(rules, subject)
+	if rule == nil {
+		return nil, nil
+	}
+	decided := *rule
+	return &decided, nil
+}
+
+// List loads every rule from PostgreSQL, so administrators always see the stored lists
+func (r *CachedAccessRuleRepository) List(ctx context.Context) ([]domain.AccessRule, error) {
+	return r.store.List(ctx)
+}
+
+// Delete removes the rule and reloads the cached rules
+func (r *CachedAccessRuleRepository) Delete(ctx context.Context, id int64, actor string) error {
+	if err := r.store.Delete(ctx, id, actor); err != nil {
+		return err
+	}
+
+	r.reload(ctx)
+	return nil
+}
+
+// Audit loads the most recent audit entries from PostgreSQL
+func (r *CachedAccessRuleRepository) Audit(ctx context.Context, limit int) ([]domain.AccessAuditEntry, error) {
+	return r.store.Audit(ctx, limit)
+}
+
+// current returns the cached rules, reloading them when they are due. Only one check reloads at a time, and none while
+// backing off after a failure; the others use the rules already loaded. The rules are only missing when they were never
+// loaded, in which case an error is returned.
+func (r *CachedAccessRuleRepository) current(ctx context.Context) ([]domain.AccessRule, error) {
+	now := time.Now()
+
+	r.mu.Lock()
+	if (r.loaded && now.Before(r.expiresAt)) || r.reloading || now.Before(r.retryAt) {
+		rules, loaded := r.rules, r.loaded
+		r.mu.Unlock()
+		if !loaded {
+			return nil, errAccessRulesUnavailable
+		}
+		return rules, nil
+	}
+	r.reloading = true
+	r.mu.Unlock()
+
+	rules, err := r.store.List(ctx)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.reloading = false
+	if err != nil {
+		r.failed(err, now)
+		if !r.loaded {
+			return nil, err
+		}
+		return r.rules, nil
+	}
+	r.replace(rules, now)
+	return rules, nil
+}
+
+// reload replaces the cached rules after a change. When they cannot be loaded the rules loaded before keep applying,
+// but are due to be reloaded at once, after the backoff, since the change itself is already stored.
+func (r *CachedAccessRuleRepository) reload(ctx context.Context) {
+	rules, err := r.store.List(ctx)
+	now := time.Now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err != nil {
+		r.expiresAt = now
+		r.failed(err, now)
+		return
+	}
+	r.replace(rules, now)
+}
+
+// replace caches the rules for one TTL and ends any backoff. The caller must hold the lock.
+func (r *CachedAccessRuleRepository) replace(rules []domain.AccessRule, now time.Time) {
+	if r.failures > 0 {
+		r.logger.Info().Int("failures", r.failures).Dur("stale_for", now.Sub(r.loadedAt)).Msg("Access rules reloaded")
+	}
+
+	r.rules, r.loaded = rules, true
+	r.loadedAt, r.expiresAt = now, now.Add(r.ttl)
+	r.failures, r.retryAt = 0, time.Time{}
+}
+
+// failed backs off further reloads after a failure, doubling the wait with every consecutive failure.
+// The caller must hold the lock.
+func (r *CachedAccessRuleRepository) failed(err error, now time.Time) {
+	backoff := accessRulesMinBackoff
+	for i := 0; i < r.failures && backoff < accessRulesMaxBackoff; i++ {
+		backoff *= 2
+	}
+	backoff = min(backoff, accessRulesMaxBackoff)
+	r.failures++
+	r.retryAt = now.Add(backoff)
+
+	if !r.loaded {
+		r.logger.Error().Int("failures", r.failures).Dur("retry_in", backoff).Err(err).Msg("Failed to load access rules, requests are rate limited without them")
+		return
+	}
+	r.logger.Warn().Int("failures", r.failures).Dur("stale_for", now.Sub(r.loadedAt)).Dur("retry_in", backoff).Err(err).Msg("Failed to reload access rules, the last rules loaded still apply")
+}
@@ -0,0 +1,140 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// fakeAccessRuleStore serves rules from memory, counting the loads and failing them while err is set
+type fakeAccessRuleStore struct {
+	rules []domain.AccessRule
+	err   error
+	loads int
+}
+
+func (s *fakeAccessRuleStore) Create(ctx context.Context, rule *domain.AccessRule, actor string) error {
+	s.rules = append(s.rules, *rule)
+	return nil
+}
+
+func (s *fakeAccessRuleStore) Decide(ctx context.Context, subject domain.AccessSubject) (*domain.AccessRule, error) {
+	return domain.Decide(s.rules, subject), nil
+}
+
+func (s *fakeAccessRuleStore) List(ctx context.Context) ([]domain.AccessRule, error) {
+	s.loads++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return append([]domain.AccessRule(nil), s.rules...), nil
+}
+
+func (s *fakeAccessRuleStore) Delete(ctx context.Context, id int64, actor string) error { return nil }
+
+func (s *fakeAccessRuleStore) Audit(ctx context.Context, limit int) ([]domain.AccessAuditEntry, error) {
+	return nil, nil
+}
+
+// expire makes the cached rules, and any backoff, due as if time had passed
+func (r *CachedAccessRuleRepository) expire() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.expiresAt, r.retryAt = time.Time{}, time.Time{}
+}
+
+func TestCachedAccessRuleRepositoryKeepsLastRulesWhileStoreIsDown(t *testing.T) {
+	deny := domain.AccessRule{ID: 1, List: domain.AccessDeny, Match: domain.MatchID, Value: "mallory"}
+	store := &fakeAccessRuleStore{rules: []domain.AccessRule{deny}}
+	r := &CachedAccessRuleRepository{logger: logger.NewWithLevel("disabled"), store: store, ttl: time.Minute}
+	mallory := domain.NewAccessSubject("mallory", nil)
+	ctx := context.Background()
+
+	if rule, err := r.Decide(ctx, mallory); err != nil || rule == nil || rule.ID != 1 {
+		t.Fatalf("Decide() = %v, %v, want the deny rule", rule, err)
+	}
+
+	// The store goes down once the rules are due to be reloaded
+	store.err = errors.New("connection refused")
+	r.expire()
+
+	for i := 0; i < 5; i++ {
+		rule, err := r.Decide(ctx, mallory)
+		if err != nil || rule == nil || rule.ID != 1 {
+			t.Fatalf("Decide() during the outage = %v, %v, want the last deny rule", rule, err)
+		}
+	}
+	if store.loads != 2 {
+		t.Errorf("store loaded %d times, want 2 since retries back off", store.loads)
+	}
+	if r.failures != 1 || !r.retryAt.After(time.Now()) {
+		t.Errorf("failures = %d, retry at %s, want 1 and a retry in the future", r.failures, r.retryAt)
+	}
+
+	// Every further failure doubles the backoff
+	first := time.Until(r.retryAt)
+	r.expire()
+	r.Decide(ctx, mallory)
+	if second := time.Until(r.retryAt); second <= first {
+		t.Errorf("backoff after two failures = %s, want more than %s", second, first)
+	}
+
+	// Once the store is back the rules are reloaded and the backoff ends
+	store.err = nil
+	store.rules = nil
+	r.expire()
+	if rule, err := r.Decide(ctx, mallory); err != nil || rule != nil {
+		t.Fatalf("Decide() after recovery = %v, %v, want no rule", rule, err)
+	}
+	if r.failures != 0 {
+		t.Errorf("failures after recovery = %d, want 0", r.failures)
+	}
+}
+
+func TestCachedAccessRuleRepositoryFailsUntilFirstLoad(t *testing.T) {
+	store := &fakeAccessRuleStore{err: errors.New("connection refused")}
+	r := &CachedAccessRuleRepository{logger: logger.NewWithLevel("disabled"), store: store, ttl: time.Minute}
+	subject := domain.NewAccessSubject("alice", nil)
+	ctx := context.Background()
+
+	if _, err := r.Decide(ctx, subject); err == nil {
+		t.Fatal("Decide() succeeded without any rules loaded, want an error")
+	}
+	// Backing off, the store is not reached again
+	if _, err := r.Decide(ctx, subject); !errors.Is(err, errAccessRulesUnavailable) {
+		t.Errorf("Decide() while backing off error = %v, want %v", err, errAccessRulesUnavailable)
+	}
+	if store.loads != 1 {
+		t.Errorf("store loaded %d times, want 1", store.loads)
+	}
+}
+
+func TestCachedAccessRuleRepositoryReloadAfterFailedChange(t *testing.T) {
+	store := &fakeAccessRuleStore{}
+	r := &CachedAccessRuleRepository{logger: logger.NewWithLevel("disabled"), store: store, ttl: time.Minute}
+	ctx := context.Background()
+
+	if _, err := r.Decide(ctx, domain.NewAccessSubject("mallory", nil)); err != nil {
+		t.Fatalf("Decide() error = %v", err)
+	}
+
+	// The change is stored but the reload after it fails, so the rules are due as soon as the backoff ends
+	store.err = errors.New("connection refused")
+	deny := domain.AccessRule{ID: 1, List: domain.AccessDeny, Match: domain.MatchID, Value: "mallory"}
+	if err := r.Create(ctx, &deny, "admin"); err != nil {
+		t.Fatalf("Create() error = %v", err)
+	}
+	if r.expiresAt.After(time.Now()) {
+		t.Errorf("rules expire at %s after a failed reload, want them due", r.expiresAt)
+	}
+
+	store.err = nil
+	r.expire()
+	if rule, err := r.Decide(ctx, domain.NewAccessSubject("mallory", nil)); err != nil || rule == nil {
+		t.Errorf("Decide() after the backoff = %v, %v, want the new deny rule", rule, err)
+	}
+}
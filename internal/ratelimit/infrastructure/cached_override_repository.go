@@ -0,0 +1,82 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// CachedOverrideRepository implements the OverrideRepository interface by caching the overrides of a
+// PostgresOverrideRepository in memory. Users without an override are cached too, since most users have none.
+// Changes made through this instance apply immediately, changes made through other instances within the TTL.
+type CachedOverrideRepository struct {
+	logger logger.Logger
+	store  *PostgresOverrideRepository
+	cache  *ttlCache[*domain.Override] // Nil values record users without an override
+}
+
+// NewCachedOverrideRepository creates a new in-memory cache in front of the PostgreSQL override repository
+func NewCachedOverrideRepository(
+	logger logger.Logger,
+	store *PostgresOverrideRepository,
+	cfg *config.Config,
+) *CachedOverrideRepository {
+	return &CachedOverrideRepository{
+		logger: logger,
+		store:  store,
+		cache:  newTTLCache[*domain.Override](cfg.RateLimit.CacheTTL),
+	}
+}
+
+// Put stores the override and caches it
+func (r *CachedOverrideRepository) Put(ctx context.Context, override *domain.Override) error {
+	if err := r.store.Put(ctx, override); err != nil {
+		return err
+	}
+
+	stored := *override
+	r.cache.set(override.UserID, &stored)
+	return nil
+}
+
+// Get returns the user's override from the cache, loading it from PostgreSQL when missing or stale
+func (r *CachedOverrideRepository) Get(ctx context.Context, userId string) (*domain.Override, error) {
+	if cached, ok := r.cache.get(userId); ok {
+		if cached == nil {
+			return nil, domain.ErrOverrideNotFound
+		}
+		override := *cached
+		return &override, nil
+	}
+
+	override, err := r.store.Get(ctx, userId)
+	if errors.Is(err, domain.ErrOverrideNotFound) {
+		r.cache.set(userId, nil)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored := *override
+	r.cache.set(userId, &stored)
+	return override, nil
+}
+
+// List returns every override from PostgreSQL, bypassing the cache
+func (r *CachedOverrideRepository) List(ctx context.Context) ([]domain.Override, error) {
+	return r.store.List(ctx)
+}
+
+// Delete removes the override and caches that the user has none
+func (r *CachedOverrideRepository) Delete(ctx context.Context, userId string) error {
+	if err := r.store.Delete(ctx, userId); err != nil {
+		return err
+	}
+
+	r.cache.set(userId, nil)
+	return nil
+}
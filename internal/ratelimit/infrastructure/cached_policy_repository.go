@@ -0,0 +1,93 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// CachedPolicyRepository implements the PolicyRepository interface by caching the policies of a
+// PostgresPolicyRepository in memory, since every check that references a policy or a tier reads one.
+// Changes made through this instance apply immediately, changes made through other instances within the TTL.
+type CachedPolicyRepository struct {
+	logger logger.Logger
+	store  *PostgresPolicyRepository
+	cache  *ttlCache[*domain.Policy] // Nil values record names without a policy
+}
+
+// NewCachedPolicyRepository creates a new in-memory cache in front of the PostgreSQL policy repository
+func NewCachedPolicyRepository(
+	logger logger.Logger,
+	store *PostgresPolicyRepository,
+	cfg *config.Config,
+) *CachedPolicyRepository {
+	return &CachedPolicyRepository{
+		logger: logger,
+		store:  store,
+		cache:  newTTLCache[*domain.Policy](cfg.RateLimit.CacheTTL),
+	}
+}
+
+// Create stores the policy and caches it
+func (r *CachedPolicyRepository) Create(ctx context.Context, policy *domain.Policy) error {
+	if err := r.store.Create(ctx, policy); err != nil {
+		return err
+	}
+
+	stored := *policy
+	r.cache.set(policy.Name, &stored)
+	return nil
+}
+
+// Get returns the policy from the cache, loading it from PostgreSQL when missing or stale
+func (r *CachedPolicyRepository) Get(ctx context.Context, name string) (*domain.Policy, error) {
+	if cached, ok := r.cache.get(name); ok {
+		if cached == nil {
+			return nil, domain.ErrPolicyNotFound
+		}
+		policy := *cached
+		return &policy, nil
+	}
+
+	policy, err := r.store.Get(ctx, name)
+	if errors.Is(err, domain.ErrPolicyNotFound) {
+		r.cache.set(name, nil)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored := *policy
+	r.cache.set(name, &stored)
+	return policy, nil
+}
+
+// List returns every policy from PostgreSQL, bypassing the cache
+func (r *CachedPolicyRepository) List(ctx context.Context) ([]domain.Policy, error) {
+	return r.store.List(ctx)
+}
+
+// Update stores the new settings of the policy and caches them
+func (r *CachedPolicyRepository) Update(ctx context.Context, policy *domain.Policy) error {
+	if err := r.store.Update(ctx, policy); err != nil {
+		return err
+	}
+
+	stored := *policy
+	r.cache.set(policy.Name, &stored)
+	return nil
+}
+
+// Delete removes the policy and caches that it no longer exists
+func (r *CachedPolicyRepository) Delete(ctx context.Context, name string) error {
+	if err := r.store.Delete(ctx, name); err != nil {
+		return err
+	}
+
+	r.cache.set(name, nil)
+	return nil
+}
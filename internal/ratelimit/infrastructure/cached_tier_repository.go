@@ -0,0 +1,90 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// CachedTierRepository implements the TierRepository interface by caching the assignments of a
+// PostgresTierRepository in memory. Users without a tier are cached too, so they do not reach PostgreSQL on every check.
+// Changes made through this instance apply immediately, changes made through other instances within the TTL.
+type CachedTierRepository struct {
+	logger logger.Logger
+	store  *PostgresTierRepository
+	cache  *ttlCache[*domain.TierAssignment] // Nil values record users without a tier
+}
+
+// NewCachedTierRepository creates a new in-memory cache in front of the PostgreSQL tier repository
+func NewCachedTierRepository(
+	logger logger.Logger,
+	store *PostgresTierRepository,
+	cfg *config.Config,
+) *CachedTierRepository {
+	return &CachedTierRepository{
+		logger: logger,
+		store:  store,
+		cache:  newTTLCache[*domain.TierAssignment](cfg.RateLimit.CacheTTL),
+	}
+}
+
+// Assign stores the user's tier and caches it
+func (r *CachedTierRepository) Assign(ctx context.Context, assignment *domain.TierAssignment) error {
+	if err := r.store.Assign(ctx, assignment); err != nil {
+		return err
+	}
+
+	stored := *assignment
+	r.cache.set(assignment.UserID, &stored)
+	return nil
+}
+
+// Get returns the user's tier assignment from the cache, loading it from PostgreSQL when missing or stale
+func (r *CachedTierRepository) Get(ctx context.Context, userId string) (*domain.TierAssignment, error) {
+	if cached, ok := r.cache.get(userId); ok {
+		if cached == nil {
+			return nil, domain.ErrTierNotAssigned
+		}
+		assignment := *cached
+		return &assignment, nil
+	}
+
+	assignment, err := r.store.Get(ctx, userId)
+	if errors.Is(err, domain.ErrTierNotAssigned) {
+		r.cache.set(userId, nil)
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored := *assignment
+	r.cache.set(userId, &stored)
+	return assignment, nil
+}
+
+// Unassign removes the user's tier assignment and caches that the user has none
+func (r *CachedTierRepository) Unassign(ctx context.Context, userId string) error {
+	if err := r.store.Unassign(ctx, userId); err != nil {
+		return err
+	}
+
+	r.cache.set(userId, nil)
+	return nil
+}
+
+// Import stores every assignment and caches them
+func (r *CachedTierRepository) Import(ctx context.Context, assignments []domain.TierAssignment) error {
+	if err := r.store.Import(ctx, assignments); err != nil {
+		return err
+	}
+
+	for i := range assignments {
+		stored := assignments[i]
+		r.cache.set(stored.UserID, &stored)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package infrastructure
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/go-clean/platform/logger"
+)
+
+// errCircuitOpen is returned instead of calling Redis while the circuit breaker is open
+var errCircuitOpen = errors.New("redis circuit breaker is open")
+
+// circuitState is the state of a circuit breaker
+type circuitState int
+
+const (
+	// circuitClosed lets every call through, counting consecutive failures
+	circuitClosed circuitState = iota
+	// circuitOpen fails every call fast until the open timeout has passed
+	circuitOpen
+	// circuitHalfOpen lets one probe call through at a time to find out whether the backend has recovered
+	circuitHalfOpen
+)
+
+// String returns the name of the state, for logging
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// circuitBreaker stops calling a failing backend. It opens after failureThreshold consecutive failures, and after
+// openTimeout lets a probe through. The breaker closes again after successThreshold consecutive successful probes,
+// and reopens as soon as a probe fails. Every state the breaker enters starts a new generation, and the outcome of
+// a call is only counted in the generation it was allowed in, so a slow call let through while closed cannot be
+// taken for the probe once the breaker is half-open.
+type circuitBreaker struct {
+	logger           logger.Logger
+	failureThreshold int
+	successThreshold int
+	openTimeout      time.Duration
+
+	mu         sync.Mutex
+	state      circuitState
+	generation int64 // Number of state transitions so far
+	failures   int   // Consecutive failures while closed
+	successes  int   // Consecutive successful probes while half-open
+	probing    bool
+	openedAt   time.Time
+}
+
+// newCircuitBreaker creates a closed circuit breaker
+func newCircuitBreaker(logger logger.Logger, failureThreshold int, successThreshold int, openTimeout time.Duration) *circuitBreaker {
+	return &circuitBreaker{
+		logger:           logger,
+		failureThreshold: failureThreshold,
+		successThreshold: successThreshold,
+		openTimeout:      openTimeout,
+	}
+}
+
+// allow returns true if a call may go through, together with the generation it is allowed in.
+// Every allowed call must be followed by a call to record with that generation.
+func (b *circuitBreaker) allow() (int64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < b.openTimeout {
+			return b.generation, false
+		}
+		b.transition(circuitHalfOpen)
+		b.probing = true
+		return b.generation, true
+	case circuitHalfOpen:
+		if b.probing {
+			return b.generation, false
+		}
+		b.probing = true
+		return b.generation, true
+	}
+	return b.generation, true
+}
+
+// record reports the outcome of a call allowed in generation. Outcomes of calls allowed before the breaker last
+// changed state are ignored.
+func (b *circuitBreaker) record(generation int64, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		b.logger.Debug().Int64("generation", generation).Int64("current", b.generation).Err(err).Msg("Ignoring outcome of a Redis call from an earlier circuit breaker state")
+		return
+	}
+
+	switch b.state {
+	case circuitClosed:
+		if err == nil {
+			b.failures = 0
+			return
+		}
+		b.failures++
+		if b.failures >= b.failureThreshold {
+			b.logger.Warn().Int("failures", b.failures).Err(err).Dur("open_timeout", b.openTimeout).Msg("Redis circuit breaker opened")
+			b.transition(circuitOpen)
+		}
+	case circuitHalfOpen:
+		b.probing = false
+		if err != nil {
+			b.logger.Warn().Err(err).Msg("Redis probe failed, circuit breaker reopened")
+			b.transition(circuitOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.successThreshold {
+			b.logger.Info().Msg("Redis recovered, circuit breaker closed")
+			b.transition(circuitClosed)
+		}
+	}
+}
+
+// transition moves the breaker into state, resetting the counts of the state it leaves
+func (b *circuitBreaker) transition(state circuitState) {
+	b.logger.Debug().Str("from", b.state.String()).Str("to", state.String()).Msg("Redis circuit breaker state changed")
+	b.state = state
+	b.generation++
+	b.failures = 0
+	b.successes = 0
+	b.probing = false
+	if state == circuitOpen {
+		b.openedAt = time.Now()
+	}
+}
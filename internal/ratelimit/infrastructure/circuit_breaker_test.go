@@ -0,0 +1,173 @@
+package infrastructure
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-clean/platform/logger"
+)
+
+var errRedisDown = errors.New("connection refused")
+
+// elapse moves the breaker past its open timeout as if the time had passed
+func (b *circuitBreaker) elapse() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.openedAt = b.openedAt.Add(-b.openTimeout)
+}
+
+// call runs an allowed call with the outcome err, reporting whether it was allowed
+func (b *circuitBreaker) call(err error) bool {
+	generation, ok := b.allow()
+	if !ok {
+		return false
+	}
+	b.record(generation, err)
+	return true
+}
+
+// allowed reports whether a call may go through, for calls whose outcome is not recorded
+func (b *circuitBreaker) allowed() bool {
+	_, ok := b.allow()
+	return ok
+}
+
+func newTestCircuitBreaker(failureThreshold int, successThreshold int) *circuitBreaker {
+	return newCircuitBreaker(logger.NewWithLevel("disabled"), failureThreshold, successThreshold, time.Minute)
+}
+
+func TestCircuitBreakerOpensAfterConsecutiveFailures(t *testing.T) {
+	b := newTestCircuitBreaker(3, 1)
+
+	// A success in between starts the count of failures over
+	for _, err := range []error{errRedisDown, errRedisDown, nil, errRedisDown, errRedisDown} {
+		if !b.call(err) {
+			t.Fatal("call rejected while closed")
+		}
+	}
+	if b.state != circuitClosed {
+		t.Fatalf("state after two consecutive failures = %s, want closed", b.state)
+	}
+
+	b.call(errRedisDown)
+	if b.state != circuitOpen {
+		t.Fatalf("state after three consecutive failures = %s, want open", b.state)
+	}
+	if b.allowed() {
+		t.Error("allow() = true while open, want false")
+	}
+}
+
+func TestCircuitBreakerClosesAfterSuccessfulProbes(t *testing.T) {
+	b := newTestCircuitBreaker(1, 2)
+	b.call(errRedisDown)
+	b.elapse()
+
+	// One probe at a time, until enough of them succeed in a row
+	for probe := 1; probe <= 2; probe++ {
+		generation, ok := b.allow()
+		if !ok {
+			t.Fatalf("probe %d rejected after the open timeout", probe)
+		}
+		if b.state != circuitHalfOpen {
+			t.Fatalf("state during probe %d = %s, want half-open", probe, b.state)
+		}
+		if b.allowed() {
+			t.Fatalf("second call allowed during probe %d, want only the probe", probe)
+		}
+		b.record(generation, nil)
+	}
+
+	if b.state != circuitClosed {
+		t.Fatalf("state after two successful probes = %s, want closed", b.state)
+	}
+	for i := 0; i < 3; i++ {
+		if !b.call(nil) {
+			t.Fatal("allow() = false after closing, want true")
+		}
+	}
+}
+
+func TestCircuitBreakerReopensWhenProbeFails(t *testing.T) {
+	b := newTestCircuitBreaker(1, 2)
+	b.call(errRedisDown)
+	b.elapse()
+
+	if !b.call(nil) {
+		t.Fatal("probe rejected after the open timeout")
+	}
+	// A failure reopens the breaker even after successful probes, and the open timeout starts again
+	if !b.call(errRedisDown) {
+		t.Fatal("second probe rejected")
+	}
+	if b.state != circuitOpen {
+		t.Fatalf("state after a failed probe = %s, want open", b.state)
+	}
+	if b.allowed() {
+		t.Error("allow() = true right after reopening, want false")
+	}
+
+	b.elapse()
+	if !b.allowed() {
+		t.Error("probe rejected after the open timeout passed again")
+	}
+}
+
+func TestCircuitBreakerLetsOneConcurrentProbeThrough(t *testing.T) {
+	b := newTestCircuitBreaker(1, 1)
+	b.call(errRedisDown)
+	b.elapse()
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if b.allowed() {
+				allowed.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := allowed.Load(); got != 1 {
+		t.Errorf("%d concurrent calls allowed while half-open, want a single probe", got)
+	}
+}
+
+func TestCircuitBreakerIgnoresCallsFromEarlierStates(t *testing.T) {
+	b := newTestCircuitBreaker(1, 1)
+
+	// Two calls are let through while closed, and the first one to fail opens the breaker
+	slowSuccess, _ := b.allow()
+	slowFailure, _ := b.allow()
+	b.call(errRedisDown)
+	b.elapse()
+
+	probe, ok := b.allow()
+	if !ok {
+		t.Fatal("probe rejected after the open timeout")
+	}
+
+	// The slow calls finish during the probe, and neither is taken for its outcome
+	b.record(slowSuccess, nil)
+	if b.state != circuitHalfOpen {
+		t.Fatalf("state after a stale success = %s, want half-open", b.state)
+	}
+	b.record(slowFailure, errRedisDown)
+	if b.state != circuitHalfOpen {
+		t.Fatalf("state after a stale failure = %s, want half-open", b.state)
+	}
+	if b.allowed() {
+		t.Fatal("second call allowed while the probe is still running, want only the probe")
+	}
+
+	b.record(probe, nil)
+	if b.state != circuitClosed {
+		t.Fatalf("state after the probe succeeded = %s, want closed", b.state)
+	}
+}
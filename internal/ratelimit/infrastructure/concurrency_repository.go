@@ -0,0 +1,137 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// acquireLeaseScript adds a lease to a sorted set scored by its expiry, only if fewer than limit unexpired leases are held.
+// Expired leases are pruned first, so a client that crashes while holding a lease only blocks its slot until the lease expires.
+// KEYS[1] - lease set key
+// ARGV[1] - limit
+// ARGV[2] - lease time to live in microseconds
+// ARGV[3] - lease ID
+// Returns {acquired, leases in flight, microseconds until the lease expires, microseconds until retry}
+var acquireLeaseScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local ttl = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+local count = redis.call('ZCARD', KEYS[1])
+if count >= limit then
+	local earliest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+	return {0, count, 0, tonumber(earliest[2]) - now}
+end
+
+redis.call('ZADD', KEYS[1], now + ttl, ARGV[3])
+-- The set lives as long as its latest lease
+local latest = redis.call('ZRANGE', KEYS[1], -1, -1, 'WITHSCORES')
+redis.call('PEXPIRE', KEYS[1], math.ceil((tonumber(latest[2]) - now) / 1000))
+return {1, count + 1, ttl, 0}
+`)
+
+// releaseLeaseScript removes a lease that has not expired yet.
+// KEYS[1] - lease set key
+// ARGV[1] - lease ID
+// Returns 1 if the lease was held, 0 otherwise
+var releaseLeaseScript = redis.NewScript(`
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now)
+return redis.call('ZREM', KEYS[1], ARGV[1])
+`)
+
+// RedisConcurrencyLimitRepository implements the ConcurrencyLimitRepository interface using a Redis sorted set
+// holding every lease of a user scored by its expiry time
+type RedisConcurrencyLimitRepository struct {
+	logger      logger.Logger
+	redisClient *redis.Client
+}
+
+// NewRedisConcurrencyLimitRepository creates a new Redis-based concurrency limit repository
+func NewRedisConcurrencyLimitRepository(
+	logger logger.Logger,
+	redisClient *redis.Client,
+) *RedisConcurrencyLimitRepository {
+	return &RedisConcurrencyLimitRepository{
+		logger:      logger,
+		redisClient: redisClient,
+	}
+}
+
+// Acquire takes a lease if the user holds fewer than limit unexpired leases
+func (r *RedisConcurrencyLimitRepository) Acquire(userId string, limit int, ttl time.Duration) (*domain.LeaseResult, error) {
+	ctx := context.Background()
+	key := leaseKey(userId)
+
+	leaseId, err := newLeaseID()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to generate lease ID")
+		return nil, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("limit", limit).Dur("ttl", ttl).Str("key", key).Msg("Acquiring lease")
+
+	values, err := acquireLeaseScript.Run(ctx, r.redisClient, []string{key}, limit, ttl.Microseconds(), leaseId).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute acquire lease script")
+		return nil, fmt.Errorf("failed to acquire lease: %w", err)
+	}
+	if len(values) != 4 {
+		return nil, fmt.Errorf("failed to acquire lease: unexpected script reply length %d", len(values))
+	}
+
+	result := &domain.LeaseResult{
+		Acquired:   values[0] == 1,
+		Limit:      limit,
+		InFlight:   int(values[1]),
+		ExpiresIn:  time.Duration(values[2]) * time.Microsecond,
+		RetryAfter: time.Duration(values[3]) * time.Microsecond,
+	}
+	if result.Acquired {
+		result.LeaseID = leaseId
+	}
+
+	r.logger.Debug().Str("user_id", userId).Str("lease_id", result.LeaseID).Int("in_flight", result.InFlight).Dur("retry_after", result.RetryAfter).Bool("acquired", result.Acquired).Msg("Acquire lease result")
+
+	return result, nil
+}
+
+// Release removes the lease from the user's set
+func (r *RedisConcurrencyLimitRepository) Release(userId string, leaseId string) (bool, error) {
+	ctx := context.Background()
+	key := leaseKey(userId)
+
+	r.logger.Debug().Str("user_id", userId).Str("lease_id", leaseId).Str("key", key).Msg("Releasing lease")
+
+	released, err := releaseLeaseScript.Run(ctx, r.redisClient, []string{key}, leaseId).Int()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute release lease script")
+		return false, fmt.Errorf("failed to release lease: %w", err)
+	}
+
+	r.logger.Debug().Str("user_id", userId).Str("lease_id", leaseId).Bool("released", released == 1).Msg("Release lease result")
+
+	return released == 1, nil
+}
+
+// newLeaseID generates a random lease ID that cannot be guessed by other clients
+func newLeaseID() (string, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,148 @@
+package infrastructure
+
+import (
+	"container/list"
+	"sync"
+	"sync/atomic"
+)
+
+// counterCacheShards is the number of independently locked shards the local counters are spread over,
+// so that requests for different users rarely wait on the same lock
+const counterCacheShards = 16
+
+// counterCacheItem is an element of a shard's recency list
+type counterCacheItem struct {
+	key   string
+	entry *CacheEntry
+}
+
+// counterCacheShard holds a slice of the counters in least recently used order
+type counterCacheShard struct {
+	mu         sync.Mutex
+	items      map[string]*list.Element
+	order      *list.List // Most recently used at the front
+	maxEntries int
+}
+
+// counterCache holds the local rate limit counters of the hybrid repository. It is bounded in size, evicting the
+// least recently used counter of a shard once the shard is full, and treats counters whose window has reset as missing.
+type counterCache struct {
+	shards [counterCacheShards]*counterCacheShard
+}
+
+// newCounterCache creates an empty cache holding at most about maxEntries counters, split evenly across its shards
+func newCounterCache(maxEntries int) *counterCache {
+	perShard := max((maxEntries+counterCacheShards-1)/counterCacheShards, 1)
+
+	c := &counterCache{}
+	for i := range c.shards {
+		c.shards[i] = &counterCacheShard{
+			items:      make(map[string]*list.Element),
+			order:      list.New(),
+			maxEntries: perShard,
+		}
+	}
+	return c
+}
+
+// shard returns the shard holding the key, chosen by its FNV-1a hash
+func (c *counterCache) shard(key string) *counterCacheShard {
+	hash := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		hash ^= uint32(key[i])
+		hash *= 16777619
+	}
+	return c.shards[hash%counterCacheShards]
+}
+
+// get returns the counter for the key and marks it as recently used. A counter whose window has reset
+// is dropped and reported as missing.
+func (c *counterCache) get(key string, now int64) (*CacheEntry, bool) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	element, ok := s.items[key]
+	if !ok {
+		return nil, false
+	}
+
+	item := element.Value.(*counterCacheItem)
+	if now > atomic.LoadInt64(&item.entry.ResetTime) {
+		s.order.Remove(element)
+		delete(s.items, key)
+		return nil, false
+	}
+
+	s.order.MoveToFront(element)
+	return item.entry, true
+}
+
+// set stores the counter for the key, evicting the least recently used counter when the shard is full
+func (c *counterCache) set(key string, entry *CacheEntry) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.items[key]; ok {
+		element.Value.(*counterCacheItem).entry = entry
+		s.order.MoveToFront(element)
+		return
+	}
+
+	if s.order.Len() >= s.maxEntries {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*counterCacheItem).key)
+	}
+	s.items[key] = s.order.PushFront(&counterCacheItem{key: key, entry: entry})
+}
+
+// delete drops the counter for the key
+func (c *counterCache) delete(key string) {
+	s := c.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if element, ok := s.items[key]; ok {
+		s.order.Remove(element)
+		delete(s.items, key)
+	}
+}
+
+// each calls fn with every counter whose window has not reset. Counters are collected shard by shard,
+// so fn may call back into the cache.
+func (c *counterCache) each(now int64, fn func(key string, entry *CacheEntry)) {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		items := make([]counterCacheItem, 0, len(s.items))
+		for _, element := range s.items {
+			item := element.Value.(*counterCacheItem)
+			if now <= atomic.LoadInt64(&item.entry.ResetTime) {
+				items = append(items, *item)
+			}
+		}
+		s.mu.Unlock()
+
+		for _, item := range items {
+			fn(item.key, item.entry)
+		}
+	}
+}
+
+// removeExpired drops every counter whose window has reset, returning how many were dropped and how many remain
+func (c *counterCache) removeExpired(now int64) (removed int, remaining int) {
+	for _, s := range c.shards {
+		s.mu.Lock()
+		for key, element := range s.items {
+			if now > atomic.LoadInt64(&element.Value.(*counterCacheItem).entry.ResetTime) {
+				s.order.Remove(element)
+				delete(s.items, key)
+				removed++
+			}
+		}
+		remaining += len(s.items)
+		s.mu.Unlock()
+	}
+	return removed, remaining
+}
@@ -0,0 +1,114 @@
+package infrastructure
+
+import (
+	"fmt"
+	"testing"
+)
+
+// sameShardKeys returns n keys that the cache places in the same shard
+func sameShardKeys(c *counterCache, n int) []string {
+	var keys []string
+	var shard *counterCacheShard
+	for i := 0; len(keys) < n; i++ {
+		key := fmt.Sprintf("rate_limit:user%d:60000", i)
+		if shard == nil {
+			shard = c.shard(key)
+		}
+		if c.shard(key) == shard {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func TestCounterCacheEvictsLeastRecentlyUsedPerShard(t *testing.T) {
+	// Two counters per shard
+	c := newCounterCache(2 * counterCacheShards)
+	keys := sameShardKeys(c, 3)
+
+	c.set(keys[0], &CacheEntry{ResetTime: 100})
+	c.set(keys[1], &CacheEntry{ResetTime: 100})
+
+	// Reading the first counter makes the second the least recently used
+	if _, ok := c.get(keys[0], 0); !ok {
+		t.Fatalf("get(%s) missed before eviction", keys[0])
+	}
+	c.set(keys[2], &CacheEntry{ResetTime: 100})
+
+	if _, ok := c.get(keys[1], 0); ok {
+		t.Errorf("get(%s) hit, want it evicted as the least recently used", keys[1])
+	}
+	for _, key := range []string{keys[0], keys[2]} {
+		if _, ok := c.get(key, 0); !ok {
+			t.Errorf("get(%s) missed, want it kept", key)
+		}
+	}
+
+	// Other shards still have room
+	var other string
+	for i := 0; other == ""; i++ {
+		if key := fmt.Sprintf("other%d", i); c.shard(key) != c.shard(keys[0]) {
+			other = key
+		}
+	}
+	c.set(other, &CacheEntry{ResetTime: 100})
+	if _, ok := c.get(keys[0], 0); !ok {
+		t.Errorf("get(%s) missed after filling another shard", keys[0])
+	}
+}
+
+func TestCounterCacheReplacesExistingKeyWithoutEvicting(t *testing.T) {
+	c := newCounterCache(counterCacheShards)
+	keys := sameShardKeys(c, 1)
+
+	c.set(keys[0], &CacheEntry{Count: 1, ResetTime: 100})
+	c.set(keys[0], &CacheEntry{Count: 2, ResetTime: 100})
+
+	entry, ok := c.get(keys[0], 0)
+	if !ok || entry.Count != 2 {
+		t.Errorf("get(%s) = %+v, %t, want the replacing entry", keys[0], entry, ok)
+	}
+}
+
+func TestCounterCacheDropsExpiredEntryOnGet(t *testing.T) {
+	c := newCounterCache(100)
+	c.set("expiring", &CacheEntry{ResetTime: 100})
+
+	if _, ok := c.get("expiring", 100); !ok {
+		t.Fatal("get at the reset time missed, want the entry until its window has passed")
+	}
+	if _, ok := c.get("expiring", 101); ok {
+		t.Fatal("get after the reset time hit, want the entry dropped")
+	}
+
+	// Dropped for good, even when read at an earlier time
+	if _, ok := c.get("expiring", 0); ok {
+		t.Error("get after the entry was dropped hit")
+	}
+}
+
+func TestCounterCacheRemoveExpired(t *testing.T) {
+	c := newCounterCache(100)
+	for i := 0; i < 10; i++ {
+		c.set(fmt.Sprintf("expired%d", i), &CacheEntry{ResetTime: 50})
+	}
+	for i := 0; i < 5; i++ {
+		c.set(fmt.Sprintf("live%d", i), &CacheEntry{ResetTime: 200})
+	}
+
+	removed, remaining := c.removeExpired(100)
+	if removed != 10 || remaining != 5 {
+		t.Errorf("removeExpired() = %d, %d, want 10, 5", removed, remaining)
+	}
+
+	visited := 0
+	c.each(100, func(key string, entry *CacheEntry) { visited++ })
+	if visited != 5 {
+		t.Errorf("each visited %d entries, want 5", visited)
+	}
+
+	removed, remaining = c.removeExpired(100)
+	if removed != 0 || remaining != 5 {
+		t.Errorf("second removeExpired() = %d, %d, want 0, 5", removed, remaining)
+	}
+}
@@ -0,0 +1,98 @@
+package infrastructure
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// FilePolicySource implements the PolicyFileSource interface using the policy file watched by platform/config.
+// Every valid version of the file replaces the previous one at once, so checks never see a partially applied file.
+type FilePolicySource struct {
+	logger  logger.Logger
+	current atomic.Pointer[domain.PolicySet]
+}
+
+// NewFilePolicySource loads the configured policy file and keeps following its changes
+func NewFilePolicySource(logger logger.Logger, cfg *config.Config) (*FilePolicySource, error) {
+	source := &FilePolicySource{logger: logger}
+	source.current.Store(&domain.PolicySet{})
+
+	if err := config.WatchPolicyFile(logger, cfg.RateLimit.PolicyFile, source.apply); err != nil {
+		return nil, err
+	}
+	return source, nil
+}
+
+// Current returns the last valid version of the policy file
+func (s *FilePolicySource) Current() *domain.PolicySet {
+	return s.current.Load()
+}
+
+// apply converts and validates a version of the policy file, replacing the current one only when it is valid
+func (s *FilePolicySource) apply(file *config.PolicyFile) error {
+	routes, err := filePolicies("route", file.Routes)
+	if err != nil {
+		return err
+	}
+	tenants, err := filePolicies("tenant", file.Tenants)
+	if err != nil {
+		return err
+	}
+	tiers, err := filePolicies("tier", file.Tiers)
+	if err != nil {
+		return err
+	}
+
+	set := &domain.PolicySet{Routes: routes, Tenants: tenants, Tiers: tiers}
+	if err := set.Validate(); err != nil {
+		return err
+	}
+
+	s.current.Store(set)
+	return nil
+}
+
+// filePolicies converts the limits declared in one section of the policy file, naming each policy after its section and key
+func filePolicies(section string, limits map[string]config.PolicyLimit) (map[string]domain.Policy, error) {
+	policies := make(map[string]domain.Policy, len(limits))
+	for key, limit := range limits {
+		policy := domain.Policy{
+			Name:      section + ":" + key,
+			Limit:     limit.Limit,
+			Window:    limit.Window,
+			Algorithm: domain.Algorithm(limit.Algorithm),
+			Burst:     limit.Burst,
+			Mode:      domain.PolicyMode(limit.Mode),
+		}
+		if policy.Window == 0 {
+			policy.Window = domain.DefaultWindow
+		}
+		if policy.Algorithm == "" {
+			policy.Algorithm = domain.DefaultAlgorithm
+		}
+		if policy.Mode == "" {
+			policy.Mode = domain.DefaultPolicyMode
+		}
+		for i, declared := range limit.Schedules {
+			schedule, err := domain.ScheduleSpec{
+				Days:     declared.Days,
+				Start:    declared.Start,
+				End:      declared.End,
+				TimeZone: declared.TimeZone,
+				Limit:    declared.Limit,
+				Window:   declared.Window,
+				Burst:    declared.Burst,
+			}.Parse()
+			if err != nil {
+				return nil, fmt.Errorf("policy %s schedule %d: %w", policy.Name, i+1, err)
+			}
+			policy.Schedules = append(policy.Schedules, schedule)
+		}
+		policies[key] = policy
+	}
+	return policies, nil
+}
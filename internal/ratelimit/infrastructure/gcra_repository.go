@@ -0,0 +1,308 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// gcraScript implements the generic cell rate algorithm. The only state kept per rule is the
+// theoretical arrival time (TAT) of the next request, in microseconds of Redis server time.
+// A TAT is only advanced when every rule admits the request.
+// KEYS[i] - TAT key of rule i
+// ARGV[1] - cost of the request, in emission intervals
+// ARGV[2i] - emission interval in microseconds (window / limit) of rule i, which may be a fraction of a microsecond
+// ARGV[2i+1] - burst tolerance in microseconds (emission interval * burst) of rule i
+// Returns {allowed, remaining, microseconds until reset, microseconds until retry} per rule
+var gcraScript = redis.NewScript(`
+local requested = tonumber(ARGV[1])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local cells = {}
+local all_allowed = true
+for i, key in ipairs(KEYS) do
+	local emission = tonumber(ARGV[i * 2])
+	local tolerance = tonumber(ARGV[i * 2 + 1])
+
+	local tat = tonumber(redis.call('GET', key))
+	if tat == nil or tat < now then
+		tat = now
+	end
+
+	local new_tat = tat + emission * requested
+	local allow_at = new_tat - tolerance
+	if now < allow_at then
+		all_allowed = false
+	end
+	cells[i] = {emission = emission, tolerance = tolerance, tat = tat, new_tat = new_tat, allow_at = allow_at}
+end
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local cell = cells[i]
+	if now < cell.allow_at then
+		table.insert(results, 0)
+		table.insert(results, math.max(math.floor((now - cell.tat + cell.tolerance) / cell.emission), 0))
+		table.insert(results, cell.tat - now)
+		table.insert(results, math.ceil(cell.allow_at - now))
+	elseif all_allowed then
+		redis.call('SET', key, cell.new_tat, 'PX', math.ceil((cell.new_tat - now) / 1000))
+		table.insert(results, 1)
+		table.insert(results, math.floor((now - cell.allow_at) / cell.emission))
+		table.insert(results, cell.new_tat - now)
+		table.insert(results, 0)
+	else
+		table.insert(results, 1)
+		table.insert(results, math.floor((now - cell.tat + cell.tolerance) / cell.emission))
+		table.insert(results, cell.tat - now)
+		table.insert(results, 0)
+	end
+end
+
+return results
+`)
+
+// gcraPeekScript reads the theoretical arrival time of a rule to report whether a request would be admitted now.
+// KEYS[1] - TAT key
+// ARGV[1] - emission interval in microseconds, which may be a fraction of a microsecond
+// ARGV[2] - burst tolerance in microseconds
+// Returns {allowed, remaining, microseconds until reset, microseconds until retry}
+var gcraPeekScript = redis.NewScript(`
+local emission = tonumber(ARGV[1])
+local tolerance = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local tat = tonumber(redis.call('GET', KEYS[1]))
+if tat == nil or tat < now then
+	tat = now
+end
+
+local remaining = math.max(math.floor((now - tat + tolerance) / emission), 0)
+local allow_at = tat + emission - tolerance
+if now < allow_at then
+	return {0, remaining, tat - now, math.ceil(allow_at - now)}
+end
+return {1, remaining, tat - now, 0}
+`)
+
+// gcraRefundScript moves the theoretical arrival time of every rule back by the refunded emission intervals,
+// never earlier than now so that unused capacity cannot be banked.
+// KEYS[i] - TAT key of rule i
+// ARGV[1] - units to refund, in emission intervals
+// ARGV[2i] - emission interval in microseconds of rule i, which may be a fraction of a microsecond
+// ARGV[2i+1] - burst tolerance in microseconds of rule i
+// Returns {allowed, remaining, microseconds until reset, microseconds until retry} per rule
+var gcraRefundScript = redis.NewScript(`
+local refunded = tonumber(ARGV[1])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local emission = tonumber(ARGV[i * 2])
+	local tolerance = tonumber(ARGV[i * 2 + 1])
+
+	local tat = tonumber(redis.call('GET', key))
+	if tat == nil or tat <= now then
+		tat = now
+	else
+		tat = math.max(tat - emission * refunded, now)
+		if tat > now then
+			redis.call('SET', key, tat, 'PX', math.ceil((tat - now) / 1000))
+		else
+			redis.call('DEL', key)
+		end
+	end
+
+	local remaining = math.max(math.floor((now - tat + tolerance) / emission), 0)
+	local allow_at = tat + emission - tolerance
+	if now < allow_at then
+		table.insert(results, 0)
+		table.insert(results, remaining)
+		table.insert(results, tat - now)
+		table.insert(results, math.ceil(allow_at - now))
+	else
+		table.insert(results, 1)
+		table.insert(results, remaining)
+		table.insert(results, tat - now)
+		table.insert(results, 0)
+	end
+end
+
+return results
+`)
+
+// GCRARateLimitRepository implements the RateLimitRepository interface using the generic cell rate algorithm
+type GCRARateLimitRepository struct {
+	logger      logger.Logger
+	redisClient *redis.Client
+	burst       int
+}
+
+// NewGCRARateLimitRepository creates a new Redis-based GCRA rate limit repository
+func NewGCRARateLimitRepository(
+	logger logger.Logger,
+	redisClient *redis.Client,
+	cfg *config.Config,
+) *GCRARateLimitRepository {
+	return &GCRARateLimitRepository{
+		logger:      logger,
+		redisClient: redisClient,
+		burst:       cfg.RateLimit.Burst,
+	}
+}
+
+// RateLimit checks if a user is allowed to make a request based on the rate limit
+func (r *GCRARateLimitRepository) RateLimit(userId string, limit int, window time.Duration) bool {
+	result, err := r.RateLimitWithDetail(userId, limit, window)
+	if err != nil {
+		return false // Fail closed - deny request on error
+	}
+	return result.Allowed
+}
+
+// RateLimitWithDetail admits requests at a steady rate of limit per window, allowing up to the configured
+// burst to arrive back to back. Denied requests carry the exact time after which a retry will be admitted.
+func (r *GCRARateLimitRepository) RateLimitWithDetail(userId string, limit int, window time.Duration) (*domain.RateLimitResult, error) {
+	compound, err := r.RateLimitAllWithDetail(userId, []domain.Rule{{Limit: limit, Window: window}}, 1)
+	if err != nil {
+		return nil, err
+	}
+	return compound.Binding(), nil
+}
+
+// RateLimitAllWithDetail admits a request costing cost emission intervals against every rule, only advancing the arrival times when all rules admit it
+func (r *GCRARateLimitRepository) RateLimitAllWithDetail(userId string, rules []domain.Rule, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	keys := ruleKeys("gcra", userId, rules)
+
+	args := []interface{}{cost}
+	for _, rule := range rules {
+		emission, tolerance := r.intervals(rule)
+		args = append(args, emission, tolerance)
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Checking GCRA rate limit")
+
+	values, err := gcraScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute GCRA script")
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, rules, time.Microsecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	for i, rule := range rules {
+		results[i].Limit = r.burstFor(rule)
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
+
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Bool("allowed", compound.Allowed).Msg("GCRA check result")
+
+	return compound, nil
+}
+
+// burstFor returns the number of requests a rule tolerates back to back, which is the rule's own burst, the configured burst or the rule's limit when neither is set
+func (r *GCRARateLimitRepository) burstFor(rule domain.Rule) int {
+	return rule.Capacity(domain.AlgorithmGCRA, r.burst)
+}
+
+// intervals returns the emission interval and burst tolerance of a rule in microseconds. The emission interval is
+// worked out in nanoseconds and is at least one, so that a rule admitting more than one request per microsecond
+// still spaces its requests apart instead of having no interval at all.
+func (r *GCRARateLimitRepository) intervals(rule domain.Rule) (float64, float64) {
+	emission := float64(max(rule.Window.Nanoseconds()/int64(rule.Limit), 1)) / float64(time.Microsecond)
+	return emission, emission * float64(r.burstFor(rule))
+}
+
+// Peek reports the requests that could be made back to back under the rule right now without admitting one
+func (r *GCRARateLimitRepository) Peek(userId string, rule domain.Rule) (*domain.RateLimitResult, error) {
+	ctx := context.Background()
+	key := ruleKeys("gcra", userId, []domain.Rule{rule})[0]
+	emission, tolerance := r.intervals(rule)
+
+	r.logger.Debug().Str("user_id", userId).Int("limit", rule.Limit).Dur("window", rule.Window).Int("burst", rule.Burst).Str("key", key).Msg("Peeking GCRA rate limit")
+
+	values, err := gcraPeekScript.Run(ctx, r.redisClient, []string{key}, emission, tolerance).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute GCRA peek script")
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, []domain.Rule{rule}, time.Microsecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
+	results[0].Limit = r.burstFor(rule)
+
+	r.logger.Debug().Str("user_id", userId).Int("remaining", results[0].Remaining).Dur("reset_after", results[0].ResetAfter).Bool("allowed", results[0].Allowed).Msg("GCRA peek result")
+
+	return &results[0], nil
+}
+
+// Refund moves the arrival time of every rule back by cost emission intervals. The arrival time has no windows, so the
+// window IDs are ignored.
+func (r *GCRARateLimitRepository) Refund(userId string, rules []domain.Rule, windowIDs []int64, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	keys := ruleKeys("gcra", userId, rules)
+
+	args := []interface{}{cost}
+	for _, rule := range rules {
+		emission, tolerance := r.intervals(rule)
+		args = append(args, emission, tolerance)
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Refunding GCRA rate limit")
+
+	values, err := gcraRefundScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute GCRA refund script")
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, rules, time.Microsecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+	for i, rule := range rules {
+		results[i].Limit = r.burstFor(rule)
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
+
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Msg("GCRA refund result")
+
+	return compound, nil
+}
+
+// Reset deletes the user's theoretical arrival time for the window so the next request may use the whole burst
+func (r *GCRARateLimitRepository) Reset(userId string, window time.Duration) error {
+	ctx := context.Background()
+	key := rateLimitKey("gcra", userId, window)
+
+	r.logger.Debug().Str("user_id", userId).Dur("window", window).Str("key", key).Msg("Resetting GCRA rate limit")
+
+	if err := r.redisClient.Del(ctx, key).Err(); err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to delete GCRA key")
+		return fmt.Errorf("failed to reset rate limit: %w", err)
+	}
+
+	return nil
+}
+
+// Adjust is not supported, since GCRA keeps an arrival time rather than a count of requests
+func (r *GCRARateLimitRepository) Adjust(userId string, window time.Duration, adjustment domain.Adjustment) (*domain.WindowState, error) {
+	return nil, fmt.Errorf("%w: %s", domain.ErrAdjustUnsupported, domain.AlgorithmGCRA)
+}
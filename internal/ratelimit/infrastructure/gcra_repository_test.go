@@ -0,0 +1,64 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+func TestGCRARateLimitRepository(t *testing.T) {
+	redis := newTestRedis(t)
+	repository := &GCRARateLimitRepository{logger: logger.NewWithLevel("disabled"), redisClient: redis.client, burst: 3}
+
+	// A request every second and one every 600ms, both with three back to back
+	slow := domain.Rule{Limit: 10, Window: 10 * time.Second}
+	fast := domain.Rule{Limit: 100, Window: time.Minute}
+
+	runRateLimitSteps(t, redis, repository, []rateLimitStep{
+		{name: "admits the first request", call: check([]domain.Rule{slow}, 1), allowed: true, remaining: []int{2}},
+		{name: "admits the cost back to back", call: check([]domain.Rule{slow}, 2), allowed: true, remaining: []int{0}},
+		{name: "denies until the next emission", call: check([]domain.Rule{slow}, 1), remaining: []int{0}, retryAfter: time.Second},
+		{name: "peek reports the exhausted burst", call: peek(slow), remaining: []int{0}, retryAfter: time.Second},
+		{name: "peek reports the next emission", advance: time.Second, call: peek(slow), allowed: true, remaining: []int{1}},
+		{name: "peek admits nothing", call: peek(slow), allowed: true, remaining: []int{1}},
+		{name: "denies every rule when one is exhausted", call: check([]domain.Rule{slow, fast}, 2), remaining: []int{1, 3}, retryAfter: time.Second},
+		{name: "advances nothing for the other rule", call: peek(fast), allowed: true, remaining: []int{3}},
+		{name: "admits against every rule", call: check([]domain.Rule{slow, fast}, 1), allowed: true, remaining: []int{0, 2}},
+		{name: "refund moves the arrival time back", call: refund([]domain.Rule{slow, fast}, nil, 1), allowed: true, remaining: []int{1, 3}},
+		{name: "refund banks no unused capacity", call: refund([]domain.Rule{slow}, nil, 5), allowed: true, remaining: []int{3}},
+		{name: "admits the whole burst", call: check([]domain.Rule{slow}, 3), allowed: true, remaining: []int{0}},
+		{name: "reset restores the burst", call: reset(slow), allowed: true, remaining: []int{3}},
+	})
+}
+
+func TestGCRARateLimitRepositoryRuleBurst(t *testing.T) {
+	redis := newTestRedis(t)
+	repository := &GCRARateLimitRepository{logger: logger.NewWithLevel("disabled"), redisClient: redis.client, burst: 3}
+
+	// The burst of the rule replaces the burst of the repository
+	rule := domain.Rule{Limit: 10, Window: 10 * time.Second, Burst: 5}
+
+	runRateLimitSteps(t, redis, repository, []rateLimitStep{
+		{name: "peek reports the burst of the rule", call: peek(rule), allowed: true, remaining: []int{5}},
+		{name: "admits past the burst of the repository", call: check([]domain.Rule{rule}, 4), allowed: true, remaining: []int{1}},
+		{name: "peek reports the room left in the burst of the rule", call: peek(rule), allowed: true, remaining: []int{1}},
+	})
+}
+
+func TestGCRARateLimitRepositoryAboveOneRequestPerMicrosecond(t *testing.T) {
+	redis := newTestRedis(t)
+	repository := &GCRARateLimitRepository{logger: logger.NewWithLevel("disabled"), redisClient: redis.client, burst: 4}
+
+	// Two requests per microsecond, four back to back
+	rule := domain.Rule{Limit: 2000, Window: time.Millisecond}
+
+	runRateLimitSteps(t, redis, repository, []rateLimitStep{
+		{name: "admits the burst", call: check([]domain.Rule{rule}, 4), allowed: true, remaining: []int{0}},
+		{name: "denies past the burst", call: check([]domain.Rule{rule}, 1), remaining: []int{0}, retryAfter: time.Microsecond},
+		{name: "refund moves the arrival time back", call: refund([]domain.Rule{rule}, nil, 2), allowed: true, remaining: []int{2}},
+		{name: "peek reports the room left", call: peek(rule), allowed: true, remaining: []int{2}},
+		{name: "admits again once emitted", advance: time.Microsecond, call: check([]domain.Rule{rule}, 4), allowed: true, remaining: []int{0}},
+	})
+}
@@ -1,11 +1,15 @@
 package infrastructure
 
 import (
+	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
 
-
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/config"
 	"github.com/go-clean/platform/logger"
 )
 
@@ -14,76 +18,200 @@ type CacheEntry struct {
 	Count     int64 // Use int64 for atomic operations
 	Limit     int
 	ResetTime int64 // Use int64 for atomic time operations (Unix nano)
+	Leased    int64 // Tokens leased from Redis and not yet used, only with token leasing
+	Pending   int64 // Requests counted locally and not yet flushed to Redis, only with write-behind
+
+	flushMu sync.Mutex // Held while pending requests are flushed, so that a reset or adjustment waits for the flush
 }
 
-// HybridRateLimitRepository implements rate limiting with local cache and Redis fallback
+// HybridRateLimitRepository implements rate limiting with local cache and Redis fallback. Local entries are keyed like
+// the Redis counters, by the start of their window, and the clock must be the one the Redis repository aligns windows by.
 type HybridRateLimitRepository struct {
 	logger          logger.Logger
 	redisRepository *RedisRateLimitRepository
-	localCache      sync.Map // Use sync.Map for lock-free reads
-	windowSize      time.Duration
+	clock           ports.Clock
+	localCache      *counterCache
+	breaker         *circuitBreaker
+	leaseFraction   float64 // Share of the tokens left in a window leased at a time, 0 when token leasing is off
+	writeBehind     bool
+	maxOvershoot    int64 // Requests counted locally per counter before checking with Redis, with write-behind
+	stop            chan struct{}
+	stopOnce        sync.Once
+	workers         sync.WaitGroup
 }
 
-// NewHybridRateLimitRepository creates a new hybrid rate limit repository
+// NewHybridRateLimitRepository creates a new hybrid rate limit repository and starts the janitor that removes
+// expired entries from its local cache, and the flusher when counts are written behind. The returned cleanup function
+// stops both, flushes pending counts and returns unused leased tokens.
 func NewHybridRateLimitRepository(
 	logger logger.Logger,
 	redisRepository *RedisRateLimitRepository,
-) *HybridRateLimitRepository {
-	return &HybridRateLimitRepository{
+	clock ports.Clock,
+	cfg *config.Config,
+) (*HybridRateLimitRepository, func(), error) {
+	var leaseFraction float64
+	if cfg.RateLimit.TokenLeaseEnabled {
+		leaseFraction = cfg.RateLimit.TokenLeaseFraction
+		if leaseFraction <= 0 || leaseFraction > 1 {
+			return nil, nil, fmt.Errorf("token lease fraction must be greater than 0 and at most 1, got %g", leaseFraction)
+		}
+		logger.Info().Str("lease_fraction", fmt.Sprintf("%g", leaseFraction)).Msg("Token leasing enabled")
+	}
+
+	if cfg.RateLimit.CircuitBreakerFailureThreshold <= 0 || cfg.RateLimit.CircuitBreakerSuccessThreshold <= 0 {
+		return nil, nil, fmt.Errorf("circuit breaker failure and success thresholds must be greater than 0")
+	}
+	if cfg.RateLimit.CircuitBreakerOpenTimeout <= 0 {
+		return nil, nil, fmt.Errorf("circuit breaker open timeout must be greater than 0, got %s", cfg.RateLimit.CircuitBreakerOpenTimeout)
+	}
+
+	writeBehind := cfg.RateLimit.WriteBehindEnabled
+	if writeBehind {
+		if leaseFraction > 0 {
+			return nil, nil, fmt.Errorf("token leasing and write-behind cannot be enabled together")
+		}
+		if cfg.RateLimit.WriteBehindFlushInterval <= 0 {
+			return nil, nil, fmt.Errorf("write-behind flush interval must be greater than 0, got %s", cfg.RateLimit.WriteBehindFlushInterval)
+		}
+		if cfg.RateLimit.WriteBehindMaxOvershoot <= 0 {
+			return nil, nil, fmt.Errorf("write-behind max overshoot must be greater than 0, got %d", cfg.RateLimit.WriteBehindMaxOvershoot)
+		}
+	}
+
+	breaker := newCircuitBreaker(
+		logger,
+		cfg.RateLimit.CircuitBreakerFailureThreshold,
+		cfg.RateLimit.CircuitBreakerSuccessThreshold,
+		cfg.RateLimit.CircuitBreakerOpenTimeout,
+	)
+
+	h := &HybridRateLimitRepository{
 		logger:          logger,
 		redisRepository: redisRepository,
-		localCache:      sync.Map{},
-		windowSize:      time.Minute, // Default 1-minute window
+		clock:           clock,
+		localCache:      newCounterCache(cfg.RateLimit.LocalCacheMaxEntries),
+		breaker:         breaker,
+		leaseFraction:   leaseFraction,
+		writeBehind:     writeBehind,
+		maxOvershoot:    int64(cfg.RateLimit.WriteBehindMaxOvershoot),
+		stop:            make(chan struct{}),
+	}
+
+	interval := cfg.RateLimit.LocalCacheCleanupInterval
+	if interval <= 0 {
+		// Entries are still evicted once the cache is full, and dropped when read after their window
+		logger.Warn().Dur("cleanup_interval", interval).Msg("Local cache janitor disabled")
+	} else {
+		logger.Info().Int("max_entries", cfg.RateLimit.LocalCacheMaxEntries).Dur("cleanup_interval", interval).Msg("Starting local cache janitor")
+		h.runEvery(interval, h.CleanupExpiredEntries)
+	}
+
+	if writeBehind {
+		logger.Info().Dur("flush_interval", cfg.RateLimit.WriteBehindFlushInterval).Int("max_overshoot", cfg.RateLimit.WriteBehindMaxOvershoot).Msg("Starting write-behind flusher")
+		h.runEvery(cfg.RateLimit.WriteBehindFlushInterval, h.flushPending)
 	}
+
+	return h, h.Close, nil
+}
+
+// runEvery calls fn every interval in the background until the repository is closed
+func (h *HybridRateLimitRepository) runEvery(interval time.Duration, fn func()) {
+	h.workers.Add(1)
+	go func() {
+		defer h.workers.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-h.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Close stops the background work and waits for it to exit, then writes pending counts and gives unused leased tokens
+// back to Redis so that other instances can use them for the rest of the window
+func (h *HybridRateLimitRepository) Close() {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+		h.workers.Wait()
+		h.logger.Info().Msg("Hybrid repository background work stopped")
+
+		if h.writeBehind {
+			h.flushPending()
+		}
+		if h.leaseFraction > 0 {
+			h.returnLeased()
+		}
+	})
+}
+
+// returnLeased gives the unused tokens of every lease back to Redis
+func (h *HybridRateLimitRepository) returnLeased() {
+	var keys []string
+	var tokens []int64
+	h.localCache.each(h.clock.Now().UnixNano(), func(key string, entry *CacheEntry) {
+		if unused := atomic.SwapInt64(&entry.Leased, 0); unused > 0 {
+			keys = append(keys, key)
+			tokens = append(tokens, unused)
+		}
+	})
+	if len(keys) == 0 {
+		return
+	}
+
+	if err := h.callRedis(func() error { return h.redisRepository.ReturnLeased(keys, tokens) }); err != nil {
+		// The tokens go back when their windows end
+		h.logger.Error().Int("leases", len(keys)).Err(err).Msg("Failed to return leased tokens")
+		return
+	}
+	h.logger.Info().Int("leases", len(keys)).Msg("Returned unused leased tokens")
 }
 
 // RateLimit checks rate limit using local cache first, then Redis for atomic updates
-func (h *HybridRateLimitRepository) RateLimit(userId string, limit int) bool {
-	h.logger.Debug().Str("user_id", userId).Int("limit", limit).Msg("Checking hybrid rate limit")
+func (h *HybridRateLimitRepository) RateLimit(userId string, limit int, window time.Duration) bool {
+	h.logger.Debug().Str("user_id", userId).Int("limit", limit).Dur("window", window).Msg("Checking hybrid rate limit")
+	start := windowStart(window, h.clock.Now())
+	cacheKey := windowKey("rate_limit", userId, window, start)
 
 	// First check local cache
-	if !h.checkLocalCache(userId, limit) {
+	if !h.checkLocalCache(cacheKey, limit) {
 		h.logger.Debug().Str("user_id", userId).Msg("Rate limit exceeded in local cache")
 		return false
 	}
 
 	// Local cache allows, now call Redis for atomic update with detail
-	currentCount, ttl, err := h.redisRepository.RateLimitWithDetail(userId, limit)
+	var result *domain.RateLimitResult
+	err := h.callRedis(func() (err error) {
+		result, err = h.redisRepository.RateLimitWithDetail(userId, limit, window)
+		return err
+	})
 	if err != nil {
-		h.logger.Error().Str("user_id", userId).Err(err).Msg("Redis rate limit with detail failed, falling back to local cache")
-		// Fallback: increment local cache and check
-		h.incrementLocalCache(userId, limit)
-		// Check local cache again after increment
-		value, exists := h.localCache.Load(userId)
-		if !exists {
-			return true // Should not happen after increment, but safe fallback
-		}
-		entry := value.(*CacheEntry)
-		count := atomic.LoadInt64(&entry.Count)
-		return count < int64(limit)
+		h.logRedisFailure(userId, err)
+		// Fallback: count the request in the local cache alone
+		return h.localAllWithDetail([]string{cacheKey}, []int64{start}, []domain.Rule{{Limit: limit, Window: window}}, 1).Allowed
 	}
 
 	// Update local cache with Redis values
-	h.updateLocalCacheWithRedisValues(userId, limit, currentCount, ttl)
+	currentCount := limit - result.Remaining
+	if !result.Allowed {
+		currentCount = limit
+	}
+	h.updateLocalCacheWithRedisValues(windowKey("rate_limit", userId, window, result.WindowID), limit, currentCount, result.ResetAfter)
 
-	return currentCount <= limit
+	return result.Allowed
 }
 
 // checkLocalCache checks if the request is allowed based on local cache
-func (h *HybridRateLimitRepository) checkLocalCache(userId string, limit int) bool {
-	value, exists := h.localCache.Load(userId)
+func (h *HybridRateLimitRepository) checkLocalCache(cacheKey string, limit int) bool {
+	entry, exists := h.localCache.get(cacheKey, h.clock.Now().UnixNano())
 	if !exists {
-		// No local cache entry, allow and let Redis handle the actual check
-		return true
-	}
-
-	entry := value.(*CacheEntry)
-	now := time.Now().UnixNano()
-	resetTime := atomic.LoadInt64(&entry.ResetTime)
-
-	// Check if the window has expired
-	if now > resetTime {
-		// Window expired, allow and let Redis handle reset
+		// No local cache entry or its window expired, allow and let Redis handle the actual check
 		return true
 	}
 
@@ -94,12 +222,12 @@ func (h *HybridRateLimitRepository) checkLocalCache(userId string, limit int) bo
 
 // incrementLocalCache increments the local cache counter for each request
 // updateLocalCacheWithRedisValues updates the local cache with values from Redis
-func (h *HybridRateLimitRepository) updateLocalCacheWithRedisValues(userId string, limit int, currentCount int, ttl time.Duration) {
-	now := time.Now().UnixNano()
+func (h *HybridRateLimitRepository) updateLocalCacheWithRedisValues(cacheKey string, limit int, currentCount int, ttl time.Duration) {
+	now := h.clock.Now().UnixNano()
 	resetTime := now + ttl.Nanoseconds()
 
 	// Load or create cache entry
-	value, exists := h.localCache.Load(userId)
+	entry, exists := h.localCache.get(cacheKey, now)
 	if !exists {
 		// Create new entry
 		entry := &CacheEntry{
@@ -107,45 +235,29 @@ func (h *HybridRateLimitRepository) updateLocalCacheWithRedisValues(userId strin
 			Limit:     limit,
 			ResetTime: resetTime,
 		}
-		h.localCache.Store(userId, entry)
-		h.logger.Debug().Str("user_id", userId).Int("count", currentCount).Int("limit", limit).Int64("reset_time", resetTime).Msg("Created new local cache entry with Redis values")
+		h.localCache.set(cacheKey, entry)
+		h.logger.Debug().Str("cache_key", cacheKey).Int("count", currentCount).Int("limit", limit).Int64("reset_time", resetTime).Msg("Created new local cache entry with Redis values")
 		return
 	}
 
 	// Update existing entry
-	entry := value.(*CacheEntry)
 	atomic.StoreInt64(&entry.Count, int64(currentCount))
 	entry.Limit = limit
 	atomic.StoreInt64(&entry.ResetTime, resetTime)
 
-	h.logger.Debug().Str("user_id", userId).Int("count", currentCount).Int("limit", limit).Int64("reset_time", resetTime).Msg("Updated local cache entry with Redis values")
+	h.logger.Debug().Str("cache_key", cacheKey).Int("count", currentCount).Int("limit", limit).Int64("reset_time", resetTime).Msg("Updated local cache entry with Redis values")
 }
 
-func (h *HybridRateLimitRepository) incrementLocalCache(userId string, limit int) {
-	value, exists := h.localCache.Load(userId)
+func (h *HybridRateLimitRepository) incrementLocalCache(cacheKey string, limit int, window time.Duration, start int64) {
+	entry, exists := h.localCache.get(cacheKey, h.clock.Now().UnixNano())
 	if !exists {
-		// Create new entry with count 1 (this request)
+		// No entry or its window expired, create new entry with count 1 (this request)
 		entry := &CacheEntry{
 			Count:     1,
 			Limit:     limit,
-			ResetTime: time.Now().Add(h.windowSize).UnixNano(),
+			ResetTime: time.UnixMilli(start).Add(window).UnixNano(),
 		}
-		h.localCache.Store(userId, entry)
-		return
-	}
-
-	entry := value.(*CacheEntry)
-	now := time.Now()
-	nowNano := now.UnixNano()
-	resetTime := entry.ResetTime
-
-	// Check if window expired and reset if needed
-	if nowNano > resetTime {
-		// Create new entry with count 1 (this request)
-
-		atomic.StoreInt64(&entry.Count, 1)
-		entry.Limit = limit
-		atomic.StoreInt64(&entry.ResetTime, time.Now().Add(h.windowSize).UnixNano())
+		h.localCache.set(cacheKey, entry)
 		return
 	}
 
@@ -154,56 +266,638 @@ func (h *HybridRateLimitRepository) incrementLocalCache(userId string, limit int
 }
 
 // RateLimitWithDetail checks rate limit using local cache first, then Redis for atomic updates with detailed info
-func (h *HybridRateLimitRepository) RateLimitWithDetail(userId string, limit int) (int, time.Duration, error) {
-	h.logger.Debug().Str("user_id", userId).Int("limit", limit).Msg("Checking hybrid rate limit with detail")
+func (h *HybridRateLimitRepository) RateLimitWithDetail(userId string, limit int, window time.Duration) (*domain.RateLimitResult, error) {
+	h.logger.Debug().Str("user_id", userId).Int("limit", limit).Dur("window", window).Msg("Checking hybrid rate limit with detail")
+	start := windowStart(window, h.clock.Now())
+	cacheKey := windowKey("rate_limit", userId, window, start)
 
 	// First check local cache
-	if !h.checkLocalCache(userId, limit) {
+	if !h.checkLocalCache(cacheKey, limit) {
 		h.logger.Debug().Str("user_id", userId).Msg("Rate limit exceeded in local cache")
 		// Return 0 remaining and get TTL from local cache if possible
-		value, exists := h.localCache.Load(userId)
-		if exists {
-			entry := value.(*CacheEntry)
+		result := &domain.RateLimitResult{Allowed: false, Limit: limit, WindowID: start}
+		now := h.clock.Now().UnixNano()
+		if entry, exists := h.localCache.get(cacheKey, now); exists {
 			resetTime := atomic.LoadInt64(&entry.ResetTime)
-			now := time.Now().UnixNano()
 			if resetTime > now {
-				ttl := time.Duration(resetTime - now)
-				return 0, ttl, nil
+				result.ResetAfter = time.Duration(resetTime - now)
+				result.RetryAfter = result.ResetAfter
 			}
 		}
-		return 0, 0, nil
+		return result, nil
 	}
 
 	// Local cache allows, now call Redis for atomic update with detail
-	remaining, ttl, err := h.redisRepository.RateLimitWithDetail(userId, limit)
+	var result *domain.RateLimitResult
+	err := h.callRedis(func() (err error) {
+		result, err = h.redisRepository.RateLimitWithDetail(userId, limit, window)
+		return err
+	})
 	if err != nil {
-		h.logger.Error().Str("user_id", userId).Err(err).Msg("Redis rate limit with detail failed")
-		return 0, 0, err
+		h.logRedisFailure(userId, err)
+		compound := h.localAllWithDetail([]string{cacheKey}, []int64{start}, []domain.Rule{{Limit: limit, Window: window}}, 1)
+		return &compound.Results[0], nil
 	}
 
 	// Always increment local cache counter since each call represents a request
-	h.incrementLocalCache(userId, limit)
+	h.incrementLocalCache(windowKey("rate_limit", userId, window, result.WindowID), limit, window, result.WindowID)
 
-	return remaining, ttl, nil
+	return result, nil
 }
 
-// CleanupExpiredEntries removes expired entries from local cache
-func (h *HybridRateLimitRepository) CleanupExpiredEntries() {
-	now := time.Now().UnixNano()
-	count := 0
+// RateLimitAllWithDetail checks every rule against the local cache first, then adds cost to all of them in Redis
+func (h *HybridRateLimitRepository) RateLimitAllWithDetail(userId string, rules []domain.Rule, cost int) (*domain.CompoundRateLimitResult, error) {
+	if h.leaseFraction > 0 {
+		return h.leaseAllWithDetail(userId, rules, cost)
+	}
+	if h.writeBehind {
+		return h.writeBehindAllWithDetail(userId, rules, cost)
+	}
 
-	h.localCache.Range(func(key, value interface{}) bool {
-		userId := key.(string)
-		entry := value.(*CacheEntry)
+	h.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Checking hybrid rate limits with detail")
+	starts := windowStarts(rules, h.clock.Now())
+	cacheKeys := windowKeys("rate_limit", userId, rules, starts)
+
+	// First check local cache, answering from it alone if any rule cannot cover the cost
+	exhausted := false
+	results := make([]domain.RateLimitResult, len(rules))
+	for i, rule := range rules {
+		results[i] = domain.RateLimitResult{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit, ResetAfter: rule.Window, WindowID: starts[i]}
+		now := h.clock.Now().UnixNano()
+		entry, exists := h.localCache.get(cacheKeys[i], now)
+		if !exists {
+			continue
+		}
 		resetTime := atomic.LoadInt64(&entry.ResetTime)
+		count := int(atomic.LoadInt64(&entry.Count))
+		results[i].ResetAfter = time.Duration(resetTime - now)
+		results[i].Remaining = max(rule.Limit-count, 0)
+		if results[i].Remaining < cost {
+			exhausted = true
+			results[i].Allowed = false
+			results[i].RetryAfter = results[i].ResetAfter
+		}
+	}
+	if exhausted {
+		h.logger.Debug().Str("user_id", userId).Msg("Rate limit exceeded in local cache")
+		return domain.NewCompoundRateLimitResult(results), nil
+	}
+
+	// Local cache allows, now call Redis for atomic update with detail
+	var compound *domain.CompoundRateLimitResult
+	err := h.callRedis(func() (err error) {
+		compound, err = h.redisRepository.RateLimitAllWithDetail(userId, rules, cost)
+		return err
+	})
+	if err != nil {
+		h.logRedisFailure(userId, err)
+		return h.localAllWithDetail(cacheKeys, starts, rules, cost), nil
+	}
+
+	// Update local cache with Redis values. Denied requests consume nothing,
+	// so the remaining budget may still cover cheaper requests.
+	redisKeys := resultKeys("rate_limit", userId, rules, compound.Results)
+	for i, result := range compound.Results {
+		currentCount := rules[i].Limit - result.Remaining
+		h.updateLocalCacheWithRedisValues(redisKeys[i], rules[i].Limit, currentCount, result.ResetAfter)
+	}
+
+	return compound, nil
+}
+
+// localAllWithDetail answers the request from the local cache alone while Redis is unavailable, counting it locally
+// when every rule has room for it. A counter missing from the cache starts the window beginning at its start.
+func (h *HybridRateLimitRepository) localAllWithDetail(cacheKeys []string, starts []int64, rules []domain.Rule, cost int) *domain.CompoundRateLimitResult {
+	now := h.clock.Now().UnixNano()
+	entries := make([]*CacheEntry, len(rules))
+	results := make([]domain.RateLimitResult, len(rules))
+	for i, rule := range rules {
+		entry, exists := h.localCache.get(cacheKeys[i], now)
+		if !exists {
+			entry = &CacheEntry{Limit: rule.Limit, ResetTime: time.UnixMilli(starts[i]).Add(rule.Window).UnixNano()}
+			h.localCache.set(cacheKeys[i], entry)
+		}
+		entries[i] = entry
+
+		remaining := pendingRemaining(entry, rule.Limit)
+		results[i] = domain.RateLimitResult{
+			Allowed:    remaining >= cost,
+			Limit:      rule.Limit,
+			Remaining:  remaining,
+			ResetAfter: time.Duration(atomic.LoadInt64(&entry.ResetTime) - now),
+			WindowID:   starts[i],
+		}
+		if !results[i].Allowed {
+			results[i].RetryAfter = results[i].ResetAfter
+		}
+	}
+
+	compound := domain.NewCompoundRateLimitResult(results)
+	compound.Approximate = true
+	if !compound.Allowed {
+		return compound
+	}
+
+	for i, entry := range entries {
+		// Requests written behind are kept pending so that they reach Redis once it recovers
+		counter := &entry.Count
+		if h.writeBehind {
+			counter = &entry.Pending
+		}
+		atomic.AddInt64(counter, int64(cost))
+		compound.Results[i].Remaining = max(compound.Results[i].Remaining-cost, 0)
+	}
+	return compound
+}
+
+// callRedis runs call through the circuit breaker, returning errCircuitOpen without calling Redis while it is open
+func (h *HybridRateLimitRepository) callRedis(call func() error) error {
+	generation, ok := h.breaker.allow()
+	if !ok {
+		return errCircuitOpen
+	}
+	err := call()
+	h.breaker.record(generation, err)
+	return err
+}
+
+// logRedisFailure logs that a check is answered from the local cache, quietly while the circuit breaker is open
+// so that an outage does not log every request
+func (h *HybridRateLimitRepository) logRedisFailure(userId string, err error) {
+	if errors.Is(err, errCircuitOpen) {
+		h.logger.Debug().Str("user_id", userId).Msg("Redis circuit breaker open, falling back to local cache")
+		return
+	}
+	h.logger.Error().Str("user_id", userId).Err(err).Msg("Redis rate limit check failed, falling back to local cache")
+}
+
+// leaseAllWithDetail serves the request from the tokens leased for every rule when they cover its cost, and otherwise
+// leases more from Redis for the rules whose lease falls short. Leased tokens are already counted in Redis, so instances
+// together never admit more than the limit, while one instance can hold back at most its lease from the others.
+func (h *HybridRateLimitRepository) leaseAllWithDetail(userId string, rules []domain.Rule, cost int) (*domain.CompoundRateLimitResult, error) {
+	h.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Checking hybrid rate limits with token leasing")
+	starts := windowStarts(rules, h.clock.Now())
+	cacheKeys := windowKeys("rate_limit", userId, rules, starts)
+
+	// Take the cost from every lease that covers it, answering locally when the local counts leave no room at all
+	now := h.clock.Now().UnixNano()
+	entries := make([]*CacheEntry, len(rules))
+	wanted := make([]int, len(rules))
+	covered, exhausted := true, false
+	results := make([]domain.RateLimitResult, len(rules))
+	for i, rule := range rules {
+		results[i] = domain.RateLimitResult{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit, ResetAfter: rule.Window, WindowID: starts[i]}
+		wanted[i] = cost
+
+		entry, exists := h.localCache.get(cacheKeys[i], now)
+		if !exists {
+			covered = false
+			continue
+		}
+		entries[i] = entry
+		results[i].ResetAfter = time.Duration(atomic.LoadInt64(&entry.ResetTime) - now)
+
+		if takeLeased(entry, cost) {
+			wanted[i] = 0
+			continue
+		}
+		covered = false
+
+		remaining := leaseRemaining(entry, rule.Limit)
+		if remaining < cost {
+			exhausted = true
+			results[i].Allowed = false
+			results[i].Remaining = remaining
+			results[i].RetryAfter = results[i].ResetAfter
+		}
+	}
+
+	if exhausted {
+		h.returnTaken(entries, wanted, cost)
+		// Rules whose lease covered the cost report what is left of it too, now that the cost is back
+		for i, rule := range rules {
+			if entries[i] != nil {
+				results[i].Remaining = leaseRemaining(entries[i], rule.Limit)
+			}
+		}
+		h.logger.Debug().Str("user_id", userId).Msg("Rate limit exceeded in local cache")
+		return domain.NewCompoundRateLimitResult(results), nil
+	}
+
+	if covered {
+		for i, rule := range rules {
+			results[i].Remaining = leaseRemaining(entries[i], rule.Limit)
+		}
+		h.logger.Debug().Str("user_id", userId).Msg("Rate limit served from leased tokens")
+		return domain.NewCompoundRateLimitResult(results), nil
+	}
+
+	// Lease more tokens for the rules whose lease falls short
+	var compound *domain.CompoundRateLimitResult
+	var granted []int
+	err := h.callRedis(func() (err error) {
+		compound, granted, err = h.redisRepository.LeaseAllWithDetail(userId, rules, wanted, h.leaseFraction)
+		return err
+	})
+	if err != nil {
+		h.returnTaken(entries, wanted, cost)
+		h.logRedisFailure(userId, err)
+		return h.localAllWithDetail(cacheKeys, starts, rules, cost), nil
+	}
+	if !compound.Allowed {
+		h.returnTaken(entries, wanted, cost)
+	}
+
+	// Redis may have counted the request in a later window than the local entries belong to
+	now = h.clock.Now().UnixNano()
+	redisKeys := resultKeys("rate_limit", userId, rules, compound.Results)
+	for i, result := range compound.Results {
+		entry := entries[i]
+		if entry == nil || redisKeys[i] != cacheKeys[i] || now > atomic.LoadInt64(&entry.ResetTime) {
+			entry = &CacheEntry{Limit: rules[i].Limit}
+			h.localCache.set(redisKeys[i], entry)
+		}
+		atomic.StoreInt64(&entry.Count, int64(rules[i].Limit-result.Remaining))
+		atomic.StoreInt64(&entry.ResetTime, now+result.ResetAfter.Nanoseconds())
+		if granted[i] > 0 {
+			// The request itself uses cost of the granted tokens
+			atomic.AddInt64(&entry.Leased, int64(granted[i]-cost))
+		}
+
+		if compound.Allowed {
+			compound.Results[i].Remaining = leaseRemaining(entry, rules[i].Limit)
+		}
+	}
+
+	return compound, nil
+}
 
-		if now > resetTime {
-			h.localCache.Delete(userId)
-		} else {
-			count++
+// takeLeased takes cost tokens from the entry's lease, leaving the lease untouched when it holds fewer
+func takeLeased(entry *CacheEntry, cost int) bool {
+	for {
+		leased := atomic.LoadInt64(&entry.Leased)
+		if leased < int64(cost) {
+			return false
 		}
-		return true // Continue iteration
+		if atomic.CompareAndSwapInt64(&entry.Leased, leased, leased-int64(cost)) {
+			return true
+		}
+	}
+}
+
+// returnTaken puts the cost back into the leases it was taken from, which are the ones no tokens were wanted for
+func (h *HybridRateLimitRepository) returnTaken(entries []*CacheEntry, wanted []int, cost int) {
+	for i, entry := range entries {
+		if entry != nil && wanted[i] == 0 {
+			atomic.AddInt64(&entry.Leased, int64(cost))
+		}
+	}
+}
+
+// returnLease gives the unused tokens leased for the counter at key back to Redis before the counter is changed
+// administratively, so they are neither lost with the local entry nor counted against the changed window.
+// The tokens stay leased when they cannot be given back.
+func (h *HybridRateLimitRepository) returnLease(key string) error {
+	entry, exists := h.localCache.get(key, h.clock.Now().UnixNano())
+	if !exists {
+		return nil
+	}
+
+	unused := atomic.SwapInt64(&entry.Leased, 0)
+	if unused == 0 {
+		return nil
+	}
+	if err := h.callRedis(func() error { return h.redisRepository.ReturnLeased([]string{key}, []int64{unused}) }); err != nil {
+		atomic.AddInt64(&entry.Leased, unused)
+		h.logger.Error().Str("key", key).Int64("tokens", unused).Err(err).Msg("Failed to return leased tokens")
+		return err
+	}
+	return nil
+}
+
+// leaseRemaining returns the tokens left to the instance, which are those left in Redis when last seen plus its own lease
+func leaseRemaining(entry *CacheEntry, limit int) int {
+	return max(limit-int(atomic.LoadInt64(&entry.Count))+int(atomic.LoadInt64(&entry.Leased)), 0)
+}
+
+// writeBehindAllWithDetail counts the request locally when every rule has room for it by the counts last read from
+// Redis plus those still pending, and leaves writing it to the flusher. A rule seen for the first time in its window,
+// or with max overshoot requests pending, is checked with Redis instead. Instances can therefore together admit up to
+// max overshoot requests per instance more than the limit, and locally answered results are marked approximate.
+func (h *HybridRateLimitRepository) writeBehindAllWithDetail(userId string, rules []domain.Rule, cost int) (*domain.CompoundRateLimitResult, error) {
+	h.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Checking hybrid rate limits with write-behind")
+	starts := windowStarts(rules, h.clock.Now())
+	cacheKeys := windowKeys("rate_limit", userId, rules, starts)
+
+	now := h.clock.Now().UnixNano()
+	entries := make([]*CacheEntry, len(rules))
+	synchronous, exhausted := false, false
+	results := make([]domain.RateLimitResult, len(rules))
+	for i, rule := range rules {
+		results[i] = domain.RateLimitResult{Allowed: true, Limit: rule.Limit, Remaining: rule.Limit, ResetAfter: rule.Window, WindowID: starts[i]}
+
+		entry, exists := h.localCache.get(cacheKeys[i], now)
+		if !exists {
+			synchronous = true
+			continue
+		}
+		entries[i] = entry
+		results[i].ResetAfter = time.Duration(atomic.LoadInt64(&entry.ResetTime) - now)
+
+		remaining := pendingRemaining(entry, rule.Limit)
+		if remaining < cost {
+			exhausted = true
+			results[i].Allowed = false
+			results[i].Remaining = remaining
+			results[i].RetryAfter = results[i].ResetAfter
+		} else if atomic.LoadInt64(&entry.Pending)+int64(cost) > h.maxOvershoot {
+			synchronous = true
+		}
+	}
+
+	if exhausted {
+		h.logger.Debug().Str("user_id", userId).Msg("Rate limit exceeded in local cache")
+		compound := domain.NewCompoundRateLimitResult(results)
+		compound.Approximate = true
+		return compound, nil
+	}
+
+	if !synchronous {
+		reserved := 0
+		for reserved < len(rules) && reservePending(entries[reserved], rules[reserved].Limit, int64(cost), h.maxOvershoot) {
+			reserved++
+		}
+		if reserved == len(rules) {
+			for i, rule := range rules {
+				results[i].Remaining = pendingRemaining(entries[i], rule.Limit)
+			}
+			h.logger.Debug().Str("user_id", userId).Msg("Rate limit counted locally")
+			compound := domain.NewCompoundRateLimitResult(results)
+			compound.Approximate = true
+			return compound, nil
+		}
+
+		// Concurrent requests took the room left, so Redis decides
+		for i := 0; i < reserved; i++ {
+			atomic.AddInt64(&entries[i].Pending, -int64(cost))
+		}
+	}
+
+	var compound *domain.CompoundRateLimitResult
+	err := h.callRedis(func() (err error) {
+		compound, err = h.redisRepository.RateLimitAllWithDetail(userId, rules, cost)
+		return err
+	})
+	if err != nil {
+		// Requests counted while Redis is unavailable are pending, and flushed once it recovers
+		h.logRedisFailure(userId, err)
+		return h.localAllWithDetail(cacheKeys, starts, rules, cost), nil
+	}
+
+	// Bring the local counts in line with Redis, keeping the requests still to be flushed. Redis may have counted the
+	// request in a later window than the local entries belong to.
+	now = h.clock.Now().UnixNano()
+	redisKeys := resultKeys("rate_limit", userId, rules, compound.Results)
+	for i, result := range compound.Results {
+		entry := entries[i]
+		if entry == nil || redisKeys[i] != cacheKeys[i] || now > atomic.LoadInt64(&entry.ResetTime) {
+			entry = &CacheEntry{Limit: rules[i].Limit}
+			h.localCache.set(redisKeys[i], entry)
+		}
+		atomic.StoreInt64(&entry.Count, int64(rules[i].Limit-result.Remaining))
+		atomic.StoreInt64(&entry.ResetTime, now+result.ResetAfter.Nanoseconds())
+		compound.Results[i].Remaining = pendingRemaining(entry, rules[i].Limit)
+	}
+
+	return compound, nil
+}
+
+// reservePending counts cost as pending on the entry, unless that takes the rule past its limit
+// or past the requests that may be pending
+func reservePending(entry *CacheEntry, limit int, cost int64, maxOvershoot int64) bool {
+	for {
+		pending := atomic.LoadInt64(&entry.Pending)
+		if pending+cost > maxOvershoot || int64(limit)-atomic.LoadInt64(&entry.Count)-pending < cost {
+			return false
+		}
+		if atomic.CompareAndSwapInt64(&entry.Pending, pending, pending+cost) {
+			return true
+		}
+	}
+}
+
+// pendingRemaining returns the requests left by the count last read from Redis and those not yet flushed
+func pendingRemaining(entry *CacheEntry, limit int) int {
+	return max(limit-int(atomic.LoadInt64(&entry.Count)+atomic.LoadInt64(&entry.Pending)), 0)
+}
+
+// flushPending writes the requests counted locally to Redis in a single round trip, and reads back the global counts
+func (h *HybridRateLimitRepository) flushPending() {
+	now := h.clock.Now().UnixNano()
+
+	var keys []string
+	var entries []*CacheEntry
+	var increments []int64
+	var resetAfter []time.Duration
+	h.localCache.each(now, func(key string, entry *CacheEntry) {
+		if atomic.LoadInt64(&entry.Pending) <= 0 {
+			return
+		}
+
+		// The entry stays locked until its flush is applied
+		entry.flushMu.Lock()
+		if pending := atomic.LoadInt64(&entry.Pending); pending > 0 {
+			keys = append(keys, key)
+			entries = append(entries, entry)
+			increments = append(increments, pending)
+			resetAfter = append(resetAfter, time.Duration(atomic.LoadInt64(&entry.ResetTime)-now))
+			return
+		}
+		entry.flushMu.Unlock()
+	})
+	defer func() {
+		for _, entry := range entries {
+			entry.flushMu.Unlock()
+		}
+	}()
+	if len(keys) == 0 {
+		return
+	}
+
+	var states []*domain.WindowState
+	err := h.callRedis(func() (err error) {
+		states, err = h.redisRepository.FlushIncrements(keys, increments, resetAfter)
+		return err
+	})
+	if errors.Is(err, errCircuitOpen) {
+		h.logger.Debug().Int("counters", len(keys)).Msg("Redis circuit breaker open, keeping requests pending")
+		return
+	}
+	if err != nil {
+		// Counters that failed keep their pending requests for the next flush
+		h.logger.Error().Int("counters", len(keys)).Err(err).Msg("Failed to flush pending requests")
+	}
+
+	now = h.clock.Now().UnixNano()
+	flushed := 0
+	for i, state := range states {
+		if state == nil {
+			continue
+		}
+		// Requests counted while the flush was in flight stay pending
+		atomic.AddInt64(&entries[i].Pending, -increments[i])
+		atomic.StoreInt64(&entries[i].Count, int64(state.Count))
+		atomic.StoreInt64(&entries[i].ResetTime, now+state.ResetAfter.Nanoseconds())
+		flushed++
+	}
+
+	h.logger.Debug().Int("counters", flushed).Msg("Flushed pending requests")
+}
+
+// flushEntry writes the requests pending for the single counter at key to Redis. The caller holds the entry's flush lock.
+func (h *HybridRateLimitRepository) flushEntry(key string, entry *CacheEntry) error {
+	pending := atomic.LoadInt64(&entry.Pending)
+	if pending <= 0 {
+		return nil
+	}
+
+	resetAfter := time.Duration(atomic.LoadInt64(&entry.ResetTime) - h.clock.Now().UnixNano())
+	err := h.callRedis(func() error {
+		_, err := h.redisRepository.FlushIncrements([]string{key}, []int64{pending}, []time.Duration{resetAfter})
+		return err
+	})
+	if err != nil {
+		h.logger.Error().Str("key", key).Int64("pending", pending).Err(err).Msg("Failed to flush pending requests")
+		return err
+	}
+
+	atomic.AddInt64(&entry.Pending, -pending)
+	return nil
+}
+
+// Peek reports the current window of the rule from Redis, leaving the local cache untouched
+func (h *HybridRateLimitRepository) Peek(userId string, rule domain.Rule) (*domain.RateLimitResult, error) {
+	h.logger.Debug().Str("user_id", userId).Int("limit", rule.Limit).Dur("window", rule.Window).Msg("Peeking hybrid rate limit")
+	var result *domain.RateLimitResult
+	err := h.callRedis(func() (err error) {
+		result, err = h.redisRepository.Peek(userId, rule)
+		return err
+	})
+	return result, err
+}
+
+// Refund takes cost back from the windows of every rule in Redis the request was counted in, then brings the local
+// cache in line with the current counts so that requests the local cache was denying are forwarded to Redis again
+func (h *HybridRateLimitRepository) Refund(userId string, rules []domain.Rule, windowIDs []int64, cost int) (*domain.CompoundRateLimitResult, error) {
+	h.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Refunding hybrid rate limit")
+	if len(windowIDs) != len(rules) {
+		return nil, fmt.Errorf("failed to refund rate limit: %w", domain.ErrWindowsRequired)
+	}
+
+	var compound *domain.CompoundRateLimitResult
+	err := h.callRedis(func() (err error) {
+		compound, err = h.redisRepository.Refund(userId, rules, windowIDs, cost)
+		return err
 	})
+	if err != nil {
+		h.logger.Error().Str("user_id", userId).Err(err).Msg("Redis rate limit refund failed")
+		return nil, err
+	}
 
-	h.logger.Debug().Int("remaining_entries", count).Msg("Cleaned up expired cache entries")
-}
\ No newline at end of file
+	cacheKeys := resultKeys("rate_limit", userId, rules, compound.Results)
+	for i, result := range compound.Results {
+		if _, exists := h.localCache.get(cacheKeys[i], h.clock.Now().UnixNano()); !exists {
+			continue
+		}
+		currentCount := rules[i].Limit - result.Remaining
+		h.updateLocalCacheWithRedisValues(cacheKeys[i], rules[i].Limit, currentCount, result.ResetAfter)
+	}
+
+	return compound, nil
+}
+
+// Reset gives unused leased tokens back, deletes the user's counter in Redis and drops the local cache entry.
+// Requests counted locally and not yet flushed belong to the window being reset, and are discarded with it.
+// Only this instance's cache is invalidated: another instance that saw the user exhausted keeps denying them from
+// its own entry until that window resets, and another instance's lease is not returned.
+func (h *HybridRateLimitRepository) Reset(userId string, window time.Duration) error {
+	h.logger.Debug().Str("user_id", userId).Dur("window", window).Msg("Resetting hybrid rate limit")
+	key := windowKey("rate_limit", userId, window, windowStart(window, h.clock.Now()))
+
+	if err := h.returnLease(key); err != nil {
+		return err
+	}
+
+	// Locking the entry keeps the flusher from writing its pending requests into the new window
+	entry, exists := h.localCache.get(key, h.clock.Now().UnixNano())
+	if exists {
+		entry.flushMu.Lock()
+		defer entry.flushMu.Unlock()
+	}
+
+	if err := h.callRedis(func() error { return h.redisRepository.Reset(userId, window) }); err != nil {
+		return err
+	}
+
+	if exists {
+		if discarded := atomic.SwapInt64(&entry.Pending, 0); discarded > 0 {
+			h.logger.Debug().Str("user_id", userId).Int64("pending", discarded).Msg("Discarded pending requests of the reset window")
+		}
+	}
+	h.localCache.delete(key)
+	return nil
+}
+
+// Adjust gives unused leased tokens back, overwrites the user's window in Redis and drops the local cache entry,
+// which is rebuilt from Redis on the next request. Requests counted locally and not yet flushed are written to Redis
+// first when the count is kept, and discarded when it is overwritten. As with Reset, other instances keep their entry.
+func (h *HybridRateLimitRepository) Adjust(userId string, window time.Duration, adjustment domain.Adjustment) (*domain.WindowState, error) {
+	h.logger.Debug().Str("user_id", userId).Dur("window", window).Msg("Adjusting hybrid rate limit")
+	now := h.clock.Now()
+	key := windowKey("rate_limit", userId, window, windowStart(window, now))
+
+	// Rejected before reaching Redis, so that the circuit breaker does not count it as a failure
+	if _, err := adjustableLeft(window, adjustment, now); err != nil {
+		return nil, err
+	}
+
+	if err := h.returnLease(key); err != nil {
+		return nil, err
+	}
+
+	// Locking the entry keeps the flusher from writing its pending requests past the adjustment
+	entry, exists := h.localCache.get(key, h.clock.Now().UnixNano())
+	if exists {
+		entry.flushMu.Lock()
+		defer entry.flushMu.Unlock()
+
+		if adjustment.Count == nil {
+			if err := h.flushEntry(key, entry); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var state *domain.WindowState
+	err := h.callRedis(func() (err error) {
+		state, err = h.redisRepository.Adjust(userId, window, adjustment)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if exists {
+		if discarded := atomic.SwapInt64(&entry.Pending, 0); discarded > 0 {
+			h.logger.Debug().Str("user_id", userId).Int64("pending", discarded).Msg("Discarded pending requests of the adjusted window")
+		}
+	}
+	h.localCache.delete(key)
+	return state, nil
+}
+
+// CleanupExpiredEntries removes expired entries from local cache
+func (h *HybridRateLimitRepository) CleanupExpiredEntries() {
+	removed, remaining := h.localCache.removeExpired(h.clock.Now().UnixNano())
+	h.logger.Debug().Int("removed_entries", removed).Int("remaining_entries", remaining).Msg("Cleaned up expired cache entries")
+}
@@ -0,0 +1,271 @@
+package infrastructure
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// newTestHybridRepository creates a hybrid repository backed by an in-memory Redis, without background work
+func newTestHybridRepository(t *testing.T, leaseFraction float64, writeBehind bool) (*HybridRateLimitRepository, *miniredis.Miniredis, *redis.Client) {
+	t.Helper()
+	test := newTestRedis(t)
+
+	log := logger.NewWithLevel("disabled")
+	return &HybridRateLimitRepository{
+		logger:          log,
+		redisRepository: NewRedisRateLimitRepository(log, test.client, test),
+		clock:           test,
+		localCache:      newCounterCache(100),
+		breaker:         newCircuitBreaker(log, 5, 1, time.Second),
+		leaseFraction:   leaseFraction,
+		writeBehind:     writeBehind,
+		maxOvershoot:    10,
+		stop:            make(chan struct{}),
+	}, test.server, test.client
+}
+
+// currentKey builds the key of the user's counter of the current window, both in Redis and in the local cache
+func currentKey(h *HybridRateLimitRepository, userId string, window time.Duration) string {
+	return windowKey("rate_limit", userId, window, windowStart(window, h.clock.Now()))
+}
+
+func TestHybridRepositoryReportsLeaseRemainingWhenExhausted(t *testing.T) {
+	h, _, _ := newTestHybridRepository(t, 0.5, false)
+	rules := []domain.Rule{{Limit: 10, Window: time.Minute}, {Limit: 2, Window: time.Hour}}
+	keys := windowKeys("rate_limit", "alice", rules, windowStarts(rules, h.clock.Now()))
+	resetTime := h.clock.Now().Add(time.Minute).UnixNano()
+
+	// The first lease covers the request, the second rule has no room left
+	h.localCache.set(keys[0], &CacheEntry{Count: 5, Limit: 10, ResetTime: resetTime, Leased: 3})
+	h.localCache.set(keys[1], &CacheEntry{Count: 2, Limit: 2, ResetTime: resetTime})
+
+	compound, err := h.RateLimitAllWithDetail("alice", rules, 1)
+	if err != nil {
+		t.Fatalf("RateLimitAllWithDetail() error = %v", err)
+	}
+	if compound.Allowed {
+		t.Fatal("RateLimitAllWithDetail() allowed, want denied by the second rule")
+	}
+	if got := compound.Results[0].Remaining; got != 8 {
+		t.Errorf("remaining of the covered rule = %d, want 8 from its count and lease", got)
+	}
+	if got := compound.Results[1].Remaining; got != 0 {
+		t.Errorf("remaining of the exhausted rule = %d, want 0", got)
+	}
+
+	entry, _ := h.localCache.get(keys[0], h.clock.Now().UnixNano())
+	if entry.Leased != 3 {
+		t.Errorf("lease after the denied request = %d, want 3 since the cost was put back", entry.Leased)
+	}
+}
+
+func TestHybridRepositoryAdjustReturnsLeasedTokens(t *testing.T) {
+	h, _, client := newTestHybridRepository(t, 0.5, false)
+	rules := []domain.Rule{{Limit: 10, Window: time.Minute}}
+	key := currentKey(h, "alice", time.Minute)
+
+	// The request takes one token and leases half of the nine left
+	if _, err := h.RateLimitAllWithDetail("alice", rules, 1); err != nil {
+		t.Fatalf("RateLimitAllWithDetail() error = %v", err)
+	}
+	if count, _ := client.Get(context.Background(), key).Int(); count != 5 {
+		t.Fatalf("count in Redis after leasing = %d, want 5", count)
+	}
+
+	// Moving the reset keeps the count, which no longer includes the unused lease
+	state, err := h.Adjust("alice", time.Minute, domain.Adjustment{ResetAfter: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("Adjust() error = %v", err)
+	}
+	if state.Count != 1 {
+		t.Errorf("count after adjusting = %d, want 1", state.Count)
+	}
+	if _, ok := h.localCache.get(key, h.clock.Now().UnixNano()); ok {
+		t.Error("local entry kept after adjusting, want it dropped")
+	}
+}
+
+func TestHybridRepositoryResetKeepsLeaseWhenRedisFails(t *testing.T) {
+	h, server, client := newTestHybridRepository(t, 0.5, false)
+	rules := []domain.Rule{{Limit: 10, Window: time.Minute}}
+	key := currentKey(h, "alice", time.Minute)
+
+	if _, err := h.RateLimitAllWithDetail("alice", rules, 1); err != nil {
+		t.Fatalf("RateLimitAllWithDetail() error = %v", err)
+	}
+
+	server.SetError("LOADING")
+	if err := h.Reset("alice", time.Minute); err == nil {
+		t.Fatal("Reset() succeeded while Redis fails, want an error")
+	}
+	entry, ok := h.localCache.get(key, h.clock.Now().UnixNano())
+	if !ok || entry.Leased != 4 {
+		t.Fatalf("local entry after a failed reset = %+v, want it kept with its lease of 4", entry)
+	}
+
+	server.SetError("")
+	if err := h.Reset("alice", time.Minute); err != nil {
+		t.Fatalf("Reset() error = %v", err)
+	}
+	if _, ok := h.localCache.get(key, h.clock.Now().UnixNano()); ok {
+		t.Error("local entry kept after resetting, want it dropped")
+	}
+	if exists, _ := client.Exists(context.Background(), key).Result(); exists != 0 {
+		t.Error("counter kept in Redis after resetting, want it deleted")
+	}
+}
+
+// countPending checks the first request with Redis and counts the next ones locally as pending
+func countPending(t *testing.T, h *HybridRateLimitRepository, userId string, rules []domain.Rule, pending int) {
+	t.Helper()
+	for i := 0; i <= pending; i++ {
+		if compound, err := h.RateLimitAllWithDetail(userId, rules, 1); err != nil || !compound.Allowed {
+			t.Fatalf("RateLimitAllWithDetail() = %+v, %v, want allowed", compound, err)
+		}
+	}
+	entry, _ := h.localCache.get(windowKeys("rate_limit", userId, rules, windowStarts(rules, h.clock.Now()))[0], h.clock.Now().UnixNano())
+	if entry == nil || entry.Pending != int64(pending) {
+		t.Fatalf("local entry = %+v, want %d pending requests", entry, pending)
+	}
+}
+
+func TestHybridRepositoryFlushesPendingRequestsTogether(t *testing.T) {
+	h, _, client := newTestHybridRepository(t, 0, true)
+	rules := []domain.Rule{{Limit: 10, Window: time.Minute}}
+	countPending(t, h, "alice", rules, 2)
+	countPending(t, h, "bob", rules, 3)
+
+	h.flushPending()
+
+	for userId, want := range map[string]int{"alice": 3, "bob": 4} {
+		key := currentKey(h, userId, time.Minute)
+		if count, _ := client.Get(context.Background(), key).Int(); count != want {
+			t.Errorf("count of %s in Redis = %d, want %d", userId, count, want)
+		}
+		entry, _ := h.localCache.get(key, h.clock.Now().UnixNano())
+		if entry.Pending != 0 || entry.Count != int64(want) {
+			t.Errorf("local entry of %s = %+v, want the flushed count and nothing pending", userId, entry)
+		}
+	}
+}
+
+func TestHybridRepositoryAdjustFlushesPendingRequestsFirst(t *testing.T) {
+	h, _, _ := newTestHybridRepository(t, 0, true)
+	countPending(t, h, "alice", []domain.Rule{{Limit: 10, Window: time.Minute}}, 2)
+
+	// The adjusted window keeps its count, including the requests counted locally
+	state, err := h.Adjust("alice", time.Minute, domain.Adjustment{ResetAfter: 30 * time.Second})
+	if err != nil {
+		t.Fatalf("Adjust() error = %v", err)
+	}
+	if state.Count != 3 || state.ResetAfter != 30*time.Second {
+		t.Errorf("state after adjusting = %+v, want a count of 3 resetting in 30s", state)
+	}
+}
+
+func TestHybridRepositoryAdjustCountDiscardsPendingRequests(t *testing.T) {
+	h, _, client := newTestHybridRepository(t, 0, true)
+	countPending(t, h, "alice", []domain.Rule{{Limit: 10, Window: time.Minute}}, 2)
+
+	count := 0
+	if _, err := h.Adjust("alice", time.Minute, domain.Adjustment{Count: &count}); err != nil {
+		t.Fatalf("Adjust() error = %v", err)
+	}
+	h.flushPending()
+
+	key := currentKey(h, "alice", time.Minute)
+	if got, _ := client.Get(context.Background(), key).Int(); got != 0 {
+		t.Errorf("count in Redis after adjusting = %d, want 0", got)
+	}
+}
+
+func TestHybridRepositoryResetDiscardsPendingRequests(t *testing.T) {
+	h, _, client := newTestHybridRepository(t, 0, true)
+	rules := []domain.Rule{{Limit: 10, Window: time.Minute}}
+	key := currentKey(h, "alice", time.Minute)
+
+	for i := 0; i < 20; i++ {
+		countPending(t, h, "alice", rules, 2)
+
+		// Whichever runs first, the requests of the reset window never reach the new one
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			h.flushPending()
+		}()
+		if err := h.Reset("alice", time.Minute); err != nil {
+			t.Fatalf("Reset() error = %v", err)
+		}
+		<-done
+
+		if exists, _ := client.Exists(context.Background(), key).Result(); exists != 0 {
+			count, _ := client.Get(context.Background(), key).Int()
+			t.Fatalf("counter in Redis after resetting = %d, want it deleted", count)
+		}
+	}
+}
+
+func TestFixedWindowFlushScript(t *testing.T) {
+	h, server, client := newTestHybridRepository(t, 0, true)
+	ctx := context.Background()
+
+	client.Set(ctx, "running", 5, time.Minute)
+	client.Set(ctx, "ended", 0, 0)
+
+	states, err := h.redisRepository.FlushIncrements(
+		[]string{"running", "new", "ended"},
+		[]int64{2, 3, 4},
+		[]time.Duration{time.Minute, 30 * time.Second, -time.Second},
+	)
+	if err != nil {
+		t.Fatalf("FlushIncrements() error = %v", err)
+	}
+
+	// A running window keeps its expiry
+	if states[0].Count != 7 || states[0].ResetAfter != time.Minute {
+		t.Errorf("running window = %+v, want a count of 7 resetting in 1m", states[0])
+	}
+	// A window missing from Redis expires when the caller last saw it reset
+	if states[1].Count != 3 || states[1].ResetAfter != 30*time.Second {
+		t.Errorf("new window = %+v, want a count of 3 resetting in 30s", states[1])
+	}
+	if ttl := server.TTL("new"); ttl != 30*time.Second {
+		t.Errorf("expiry of the new window = %s, want 30s", ttl)
+	}
+	// A window that has already ended is not counted into, and the counter is deleted
+	if states[2].Count != 0 || states[2].ResetAfter != 0 {
+		t.Errorf("ended window = %+v, want an empty state", states[2])
+	}
+	if server.Exists("ended") {
+		t.Error("counter of the ended window kept, want it deleted")
+	}
+}
+
+func TestFixedWindowFlushScriptReloadsWhenRedisLosesIt(t *testing.T) {
+	h, _, client := newTestHybridRepository(t, 0, true)
+	ctx := context.Background()
+
+	// The first flush finds no script in Redis, the second one finds it flushed away again
+	for round, want := range []int{2, 4} {
+		if round > 0 {
+			client.ScriptFlush(ctx)
+		}
+		states, err := h.redisRepository.FlushIncrements([]string{"counter"}, []int64{2}, []time.Duration{time.Minute})
+		if err != nil {
+			t.Fatalf("FlushIncrements() round %d error = %v", round, err)
+		}
+		if states[0] == nil || states[0].Count != want {
+			t.Fatalf("state after round %d = %+v, want a count of %d counted once", round, states[0], want)
+		}
+	}
+	if exists, _ := client.ScriptExists(ctx, fixedWindowFlushScript.Hash()).Result(); !exists[0] {
+		t.Error("flush script not loaded after flushing, want it loaded")
+	}
+}
@@ -0,0 +1,111 @@
+package infrastructure
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
+
+// rateLimitKey builds the Redis key for a user's limit. The window is part of the key
+// so that limits with different windows for the same user are tracked independently.
+func rateLimitKey(prefix string, userId string, window time.Duration) string {
+	return fmt.Sprintf("%s:%s:%d", prefix, userId, window.Milliseconds())
+}
+
+// leaseKey builds the Redis key of the sorted set holding a user's leases
+func leaseKey(userId string) string {
+	return fmt.Sprintf("concurrency:%s", userId)
+}
+
+// quotaKey builds the Redis key of a user's quota counter. The start of the period is part of the key
+// so that every period, and every time zone the period is aligned to, has its own counter.
+func quotaKey(userId string, period domain.Period, start time.Time) string {
+	return fmt.Sprintf("quota:%s:%s:%d", userId, period, start.Unix())
+}
+
+// shadowKeys builds the Redis keys of the sorted set holding when each subject was last denied by a shadow policy,
+// and of the hash counting the subject's denials
+func shadowKeys(policy string) (string, string) {
+	return fmt.Sprintf("shadow:%s:last", policy), fmt.Sprintf("shadow:%s:count", policy)
+}
+
+// ruleKeys builds the Redis key of every rule checked for a user, or for the rule's own subject when it has one
+func ruleKeys(prefix string, userId string, rules []domain.Rule) []string {
+	keys := make([]string, len(rules))
+	for i, rule := range rules {
+		subject := userId
+		if rule.Subject != "" {
+			subject = rule.Subject
+		}
+		keys[i] = rateLimitKey(prefix, subject, rule.Window)
+	}
+	return keys
+}
+
+// windowStart returns the start, in Unix milliseconds, of the window of the given size that now falls in.
+// Windows are aligned to multiples of their size, so every instance counts into the same window.
+func windowStart(window time.Duration, now time.Time) int64 {
+	millis := now.UnixMilli()
+	return millis - millis%window.Milliseconds()
+}
+
+// windowLeft returns the milliseconds left until the window starting at start ends, at least one
+func windowLeft(window time.Duration, start int64, now time.Time) int64 {
+	return max(start+window.Milliseconds()-now.UnixMilli(), 1)
+}
+
+// windowStarts returns the start of the current window of every rule
+func windowStarts(rules []domain.Rule, now time.Time) []int64 {
+	starts := make([]int64, len(rules))
+	for i, rule := range rules {
+		starts[i] = windowStart(rule.Window, now)
+	}
+	return starts
+}
+
+// windowKey builds the Redis key of the counter of a user's window starting at start. The start is part of the key,
+// so that every window has its own counter and nothing done to one window can carry over into the next.
+func windowKey(prefix string, userId string, window time.Duration, start int64) string {
+	return fmt.Sprintf("%s:%d", rateLimitKey(prefix, userId, window), start)
+}
+
+// windowKeys builds the Redis key of the counter of every rule's window starting at starts,
+// for the rule's own subject when it has one
+func windowKeys(prefix string, userId string, rules []domain.Rule, starts []int64) []string {
+	keys := ruleKeys(prefix, userId, rules)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("%s:%d", keys[i], starts[i])
+	}
+	return keys
+}
+
+// resultKeys builds the Redis key of the counter of the window every rule's result was counted in
+func resultKeys(prefix string, userId string, rules []domain.Rule, results []domain.RateLimitResult) []string {
+	starts := make([]int64, len(results))
+	for i, result := range results {
+		starts[i] = result.WindowID
+	}
+	return windowKeys(prefix, userId, rules, starts)
+}
+
+// ruleResults converts the flat reply of a multi-rule script into per-rule results.
+// Scripts reply with {allowed, remaining, reset after, retry after} for every rule, in the given unit.
+func ruleResults(values []int64, rules []domain.Rule, unit time.Duration) ([]domain.RateLimitResult, error) {
+	if len(values) != len(rules)*4 {
+		return nil, fmt.Errorf("unexpected script reply length %d for %d rules", len(values), len(rules))
+	}
+
+	results := make([]domain.RateLimitResult, len(rules))
+	for i, rule := range rules {
+		offset := i * 4
+		results[i] = domain.RateLimitResult{
+			Allowed:    values[offset] == 1,
+			Limit:      rule.Limit,
+			Remaining:  int(values[offset+1]),
+			ResetAfter: time.Duration(values[offset+2]) * unit,
+			RetryAfter: time.Duration(values[offset+3]) * unit,
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,193 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// accessRuleColumns lists the columns scanned by scanAccessRule, in order
+const accessRuleColumns = `id, list, match_type, value, reason, created_by, created_at`
+
+// accessAuditColumns lists the columns scanned by scanAccessAuditEntry, in order
+const accessAuditColumns = `id, rule_id, action, list, match_type, value, reason, actor, at`
+
+// auditAccessRuleQuery records a change to a rule in the audit trail
+const auditAccessRuleQuery = `INSERT INTO access_rule_audit (rule_id, action, list, match_type, value, reason, actor)
+	VALUES ($1, $2, $3, $4, $5, $6, $7)`
+
+// PostgresAccessRuleRepository implements the AccessRuleRepository interface using the access_rules and
+// access_rule_audit tables. Each change and its audit entry are written in the same transaction.
+type PostgresAccessRuleRepository struct {
+	logger logger.Logger
+	db     *pgxpool.Pool
+}
+
+// NewPostgresAccessRuleRepository creates a new PostgreSQL-based access rule repository
+func NewPostgresAccessRuleRepository(logger logger.Logger, db *pgxpool.Pool) *PostgresAccessRuleRepository {
+	return &PostgresAccessRuleRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// Create inserts the rule and its audit entry, failing if the list already holds the same match and value
+func (r *PostgresAccessRuleRepository) Create(ctx context.Context, rule *domain.AccessRule, actor string) error {
+	r.logger.Debug().Str("list", string(rule.List)).Str("match", string(rule.Match)).Str("value", rule.Value).Msg("Creating access rule")
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to create access rule: %w", err)
+	}
+	// Rolling back after a successful commit is a no-op
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	row := tx.QueryRow(ctx,
+		`INSERT INTO access_rules (list, match_type, value, reason, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+accessRuleColumns,
+		string(rule.List), string(rule.Match), rule.Value, rule.Reason, actor,
+	)
+	if err := scanAccessRule(row, rule); err != nil {
+		if isPgError(err, uniqueViolation) {
+			return domain.ErrAccessRuleExists
+		}
+		return fmt.Errorf("failed to create access rule: %w", err)
+	}
+
+	if err := auditAccessRule(ctx, tx, rule, domain.AccessRuleCreated, actor); err != nil {
+		return fmt.Errorf("failed to create access rule: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to create access rule: %w", err)
+	}
+
+	return nil
+}
+
+// Decide loads every rule and returns the one deciding the access of the subject
+func (r *PostgresAccessRuleRepository) Decide(ctx context.Context, subject domain.AccessSubject) (*domain.AccessRule, error) {
+	rules, err := r.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return domain.Decide(rules, subject), nil
+}
+
+// List loads every rule ordered by ID
+func (r *PostgresAccessRuleRepository) List(ctx context.Context) ([]domain.AccessRule, error) {
+	r.logger.Debug().Msg("Listing access rules")
+
+	rows, err := r.db.Query(ctx, `SELECT `+accessRuleColumns+` FROM access_rules ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access rules: %w", err)
+	}
+	defer rows.Close()
+
+	rules := []domain.AccessRule{}
+	for rows.Next() {
+		var rule domain.AccessRule
+		if err := scanAccessRule(rows, &rule); err != nil {
+			return nil, fmt.Errorf("failed to list access rules: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list access rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+// Delete removes the rule with the given ID and records the removal in the audit trail
+func (r *PostgresAccessRuleRepository) Delete(ctx context.Context, id int64, actor string) error {
+	r.logger.Debug().Int64("rule_id", id).Msg("Deleting access rule")
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to delete access rule: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	var rule domain.AccessRule
+	row := tx.QueryRow(ctx, `DELETE FROM access_rules WHERE id = $1 RETURNING `+accessRuleColumns, id)
+	if err := scanAccessRule(row, &rule); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrAccessRuleNotFound
+		}
+		return fmt.Errorf("failed to delete access rule: %w", err)
+	}
+
+	if err := auditAccessRule(ctx, tx, &rule, domain.AccessRuleDeleted, actor); err != nil {
+		return fmt.Errorf("failed to delete access rule: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to delete access rule: %w", err)
+	}
+
+	return nil
+}
+
+// Audit loads the most recent audit entries, newest first
+func (r *PostgresAccessRuleRepository) Audit(ctx context.Context, limit int) ([]domain.AccessAuditEntry, error) {
+	r.logger.Debug().Int("limit", limit).Msg("Listing access rule audit entries")
+
+	rows, err := r.db.Query(ctx, `SELECT `+accessAuditColumns+` FROM access_rule_audit ORDER BY at DESC, id DESC LIMIT $1`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access rule audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []domain.AccessAuditEntry{}
+	for rows.Next() {
+		var entry domain.AccessAuditEntry
+		if err := scanAccessAuditEntry(rows, &entry); err != nil {
+			return nil, fmt.Errorf("failed to list access rule audit entries: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list access rule audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+// auditAccessRule records the change to the rule within the transaction
+func auditAccessRule(ctx context.Context, tx pgx.Tx, rule *domain.AccessRule, action domain.AccessAction, actor string) error {
+	_, err := tx.Exec(ctx, auditAccessRuleQuery,
+		rule.ID, string(action), string(rule.List), string(rule.Match), rule.Value, rule.Reason, actor,
+	)
+	return err
+}
+
+// scanAccessRule reads a row selected with accessRuleColumns into the rule
+func scanAccessRule(row pgx.Row, rule *domain.AccessRule) error {
+	var list, match string
+	if err := row.Scan(&rule.ID, &list, &match, &rule.Value, &rule.Reason, &rule.CreatedBy, &rule.CreatedAt); err != nil {
+		return err
+	}
+	rule.List = domain.AccessList(list)
+	rule.Match = domain.AccessMatch(match)
+	return nil
+}
+
+// scanAccessAuditEntry reads a row selected with accessAuditColumns into the entry
+func scanAccessAuditEntry(row pgx.Row, entry *domain.AccessAuditEntry) error {
+	var action, list, match string
+	if err := row.Scan(&entry.ID, &entry.RuleID, &action, &list, &match, &entry.Value, &entry.Reason, &entry.Actor, &entry.At); err != nil {
+		return err
+	}
+	entry.Action = domain.AccessAction(action)
+	entry.List = domain.AccessList(list)
+	entry.Match = domain.AccessMatch(match)
+	return nil
+}
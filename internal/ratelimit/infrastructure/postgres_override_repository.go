@@ -0,0 +1,144 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// overrideColumns lists the columns scanned by scanOverride, in order
+const overrideColumns = `user_id, multiplier, request_limit, window_ms, reason, expires_at, created_at, updated_at`
+
+// PostgresOverrideRepository implements the OverrideRepository interface using the rate_limit_overrides table
+type PostgresOverrideRepository struct {
+	logger logger.Logger
+	db     *pgxpool.Pool
+}
+
+// NewPostgresOverrideRepository creates a new PostgreSQL-based override repository
+func NewPostgresOverrideRepository(logger logger.Logger, db *pgxpool.Pool) *PostgresOverrideRepository {
+	return &PostgresOverrideRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// Put inserts the override or replaces the user's existing one, keeping its creation time
+func (r *PostgresOverrideRepository) Put(ctx context.Context, override *domain.Override) error {
+	r.logger.Debug().Str("user_id", override.UserID).Msg("Storing rate limit override")
+
+	var multiplier *float64
+	var limit *int
+	var windowMs *int64
+	if override.Limit > 0 {
+		fixed, ms := override.Limit, override.Window.Milliseconds()
+		limit, windowMs = &fixed, &ms
+	} else {
+		factor := override.Multiplier
+		multiplier = &factor
+	}
+
+	row := r.db.QueryRow(ctx,
+		`INSERT INTO rate_limit_overrides (user_id, multiplier, request_limit, window_ms, reason, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (user_id) DO UPDATE SET
+			multiplier = EXCLUDED.multiplier,
+			request_limit = EXCLUDED.request_limit,
+			window_ms = EXCLUDED.window_ms,
+			reason = EXCLUDED.reason,
+			expires_at = EXCLUDED.expires_at,
+			updated_at = NOW()
+		RETURNING `+overrideColumns,
+		override.UserID, multiplier, limit, windowMs, override.Reason, override.ExpiresAt,
+	)
+	if err := scanOverride(row, override); err != nil {
+		return fmt.Errorf("failed to store override: %w", err)
+	}
+
+	return nil
+}
+
+// Get loads the user's override
+func (r *PostgresOverrideRepository) Get(ctx context.Context, userId string) (*domain.Override, error) {
+	r.logger.Debug().Str("user_id", userId).Msg("Loading rate limit override")
+
+	var override domain.Override
+	row := r.db.QueryRow(ctx, `SELECT `+overrideColumns+` FROM rate_limit_overrides WHERE user_id = $1`, userId)
+	if err := scanOverride(row, &override); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrOverrideNotFound
+		}
+		return nil, fmt.Errorf("failed to load override: %w", err)
+	}
+
+	return &override, nil
+}
+
+// List loads every override ordered by user ID
+func (r *PostgresOverrideRepository) List(ctx context.Context) ([]domain.Override, error) {
+	r.logger.Debug().Msg("Listing rate limit overrides")
+
+	rows, err := r.db.Query(ctx, `SELECT `+overrideColumns+` FROM rate_limit_overrides ORDER BY user_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overrides: %w", err)
+	}
+	defer rows.Close()
+
+	overrides := []domain.Override{}
+	for rows.Next() {
+		var override domain.Override
+		if err := scanOverride(rows, &override); err != nil {
+			return nil, fmt.Errorf("failed to list overrides: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list overrides: %w", err)
+	}
+
+	return overrides, nil
+}
+
+// Delete removes the user's override
+func (r *PostgresOverrideRepository) Delete(ctx context.Context, userId string) error {
+	r.logger.Debug().Str("user_id", userId).Msg("Deleting rate limit override")
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM rate_limit_overrides WHERE user_id = $1`, userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete override: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrOverrideNotFound
+	}
+
+	return nil
+}
+
+// scanOverride reads a row selected with overrideColumns into the override
+func scanOverride(row pgx.Row, override *domain.Override) error {
+	var multiplier *float64
+	var limit *int
+	var windowMs *int64
+	if err := row.Scan(&override.UserID, &multiplier, &limit, &windowMs, &override.Reason, &override.ExpiresAt, &override.CreatedAt, &override.UpdatedAt); err != nil {
+		return err
+	}
+
+	override.Multiplier, override.Limit, override.Window = 0, 0, 0
+	if multiplier != nil {
+		override.Multiplier = *multiplier
+	}
+	if limit != nil {
+		override.Limit = *limit
+	}
+	if windowMs != nil {
+		override.Window = time.Duration(*windowMs) * time.Millisecond
+	}
+	return nil
+}
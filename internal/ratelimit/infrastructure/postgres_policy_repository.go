@@ -0,0 +1,229 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+const (
+	// uniqueViolation is the PostgreSQL error code raised when an insert conflicts with a primary key or unique index
+	uniqueViolation = "23505"
+
+	// foreignKeyViolation is the PostgreSQL error code raised when a row references a missing row, or a referenced row is deleted
+	foreignKeyViolation = "23503"
+)
+
+// policyColumns lists the columns scanned by scanPolicy, in order
+const policyColumns = `name, request_limit, window_ms, algorithm, burst, mode, schedules, created_at, updated_at`
+
+// scheduleRecord is the JSON form a schedule is stored in
+type scheduleRecord struct {
+	Days     []string `json:"days,omitempty"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	TimeZone string   `json:"time_zone"`
+	Limit    int      `json:"limit"`
+	WindowMs int64    `json:"window_ms,omitempty"`
+	Burst    int      `json:"burst,omitempty"`
+}
+
+// PostgresPolicyRepository implements the PolicyRepository interface using the rate_limit_policies table
+type PostgresPolicyRepository struct {
+	logger logger.Logger
+	db     *pgxpool.Pool
+}
+
+// NewPostgresPolicyRepository creates a new PostgreSQL-based policy repository
+func NewPostgresPolicyRepository(logger logger.Logger, db *pgxpool.Pool) *PostgresPolicyRepository {
+	return &PostgresPolicyRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// Create inserts the policy, failing if its name is already taken
+func (r *PostgresPolicyRepository) Create(ctx context.Context, policy *domain.Policy) error {
+	r.logger.Debug().Str("policy", policy.Name).Msg("Creating rate limit policy")
+
+	schedules, err := encodeSchedules(policy.Schedules)
+	if err != nil {
+		return fmt.Errorf("failed to create policy: %w", err)
+	}
+
+	row := r.db.QueryRow(ctx,
+		`INSERT INTO rate_limit_policies (name, request_limit, window_ms, algorithm, burst, mode, schedules)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+policyColumns,
+		policy.Name, policy.Limit, policy.Window.Milliseconds(), string(policy.Algorithm), policy.Burst, string(policy.Mode), schedules,
+	)
+	if err := scanPolicy(row, policy); err != nil {
+		if isPgError(err, uniqueViolation) {
+			return domain.ErrPolicyExists
+		}
+		return fmt.Errorf("failed to create policy: %w", err)
+	}
+
+	return nil
+}
+
+// Get loads the policy with the given name
+func (r *PostgresPolicyRepository) Get(ctx context.Context, name string) (*domain.Policy, error) {
+	r.logger.Debug().Str("policy", name).Msg("Loading rate limit policy")
+
+	var policy domain.Policy
+	row := r.db.QueryRow(ctx, `SELECT `+policyColumns+` FROM rate_limit_policies WHERE name = $1`, name)
+	if err := scanPolicy(row, &policy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrPolicyNotFound
+		}
+		return nil, fmt.Errorf("failed to load policy: %w", err)
+	}
+
+	return &policy, nil
+}
+
+// List loads every policy ordered by name
+func (r *PostgresPolicyRepository) List(ctx context.Context) ([]domain.Policy, error) {
+	r.logger.Debug().Msg("Listing rate limit policies")
+
+	rows, err := r.db.Query(ctx, `SELECT `+policyColumns+` FROM rate_limit_policies ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+	defer rows.Close()
+
+	policies := []domain.Policy{}
+	for rows.Next() {
+		var policy domain.Policy
+		if err := scanPolicy(rows, &policy); err != nil {
+			return nil, fmt.Errorf("failed to list policies: %w", err)
+		}
+		policies = append(policies, policy)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list policies: %w", err)
+	}
+
+	return policies, nil
+}
+
+// Update overwrites the settings of an existing policy and refreshes its timestamps from the stored row
+func (r *PostgresPolicyRepository) Update(ctx context.Context, policy *domain.Policy) error {
+	r.logger.Debug().Str("policy", policy.Name).Msg("Updating rate limit policy")
+
+	schedules, err := encodeSchedules(policy.Schedules)
+	if err != nil {
+		return fmt.Errorf("failed to update policy: %w", err)
+	}
+
+	row := r.db.QueryRow(ctx,
+		`UPDATE rate_limit_policies
+		SET request_limit = $2, window_ms = $3, algorithm = $4, burst = $5, mode = $6, schedules = $7, updated_at = NOW()
+		WHERE name = $1
+		RETURNING `+policyColumns,
+		policy.Name, policy.Limit, policy.Window.Milliseconds(), string(policy.Algorithm), policy.Burst, string(policy.Mode), schedules,
+	)
+	if err := scanPolicy(row, policy); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return domain.ErrPolicyNotFound
+		}
+		return fmt.Errorf("failed to update policy: %w", err)
+	}
+
+	return nil
+}
+
+// Delete removes the policy with the given name
+func (r *PostgresPolicyRepository) Delete(ctx context.Context, name string) error {
+	r.logger.Debug().Str("policy", name).Msg("Deleting rate limit policy")
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM rate_limit_policies WHERE name = $1`, name)
+	if err != nil {
+		if isPgError(err, foreignKeyViolation) {
+			return domain.ErrPolicyInUse
+		}
+		return fmt.Errorf("failed to delete policy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrPolicyNotFound
+	}
+
+	return nil
+}
+
+// isPgError returns true if err was raised by PostgreSQL with the given error code
+func isPgError(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == code
+}
+
+// scanPolicy reads a row selected with policyColumns into the policy
+func scanPolicy(row pgx.Row, policy *domain.Policy) error {
+	var windowMs int64
+	var algorithm, mode string
+	var schedules []byte
+	if err := row.Scan(&policy.Name, &policy.Limit, &windowMs, &algorithm, &policy.Burst, &mode, &schedules, &policy.CreatedAt, &policy.UpdatedAt); err != nil {
+		return err
+	}
+	policy.Window = time.Duration(windowMs) * time.Millisecond
+	policy.Algorithm = domain.Algorithm(algorithm)
+	policy.Mode = domain.PolicyMode(mode)
+
+	var err error
+	policy.Schedules, err = decodeSchedules(schedules)
+	return err
+}
+
+// encodeSchedules converts schedules into the JSON stored in the schedules column
+func encodeSchedules(schedules []domain.Schedule) ([]byte, error) {
+	records := make([]scheduleRecord, len(schedules))
+	for i, schedule := range schedules {
+		spec := schedule.Spec()
+		records[i] = scheduleRecord{
+			Days:     spec.Days,
+			Start:    spec.Start,
+			End:      spec.End,
+			TimeZone: spec.TimeZone,
+			Limit:    spec.Limit,
+			WindowMs: spec.Window.Milliseconds(),
+			Burst:    spec.Burst,
+		}
+	}
+	return json.Marshal(records)
+}
+
+// decodeSchedules parses the JSON stored in the schedules column
+func decodeSchedules(data []byte) ([]domain.Schedule, error) {
+	var records []scheduleRecord
+	if err := json.Unmarshal(data, &records); err != nil {
+		return nil, fmt.Errorf("invalid schedules: %w", err)
+	}
+
+	var schedules []domain.Schedule
+	for _, record := range records {
+		schedule, err := domain.ScheduleSpec{
+			Days:     record.Days,
+			Start:    record.Start,
+			End:      record.End,
+			TimeZone: record.TimeZone,
+			Limit:    record.Limit,
+			Window:   time.Duration(record.WindowMs) * time.Millisecond,
+			Burst:    record.Burst,
+		}.Parse()
+		if err != nil {
+			return nil, fmt.Errorf("invalid schedules: %w", err)
+		}
+		schedules = append(schedules, schedule)
+	}
+	return schedules, nil
+}
@@ -0,0 +1,74 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// PostgresQuotaUsageStore implements the QuotaUsageStore interface using the quota_usage table
+type PostgresQuotaUsageStore struct {
+	logger logger.Logger
+	db     *pgxpool.Pool
+}
+
+// NewPostgresQuotaUsageStore creates a new PostgreSQL-based quota usage store
+func NewPostgresQuotaUsageStore(logger logger.Logger, db *pgxpool.Pool) *PostgresQuotaUsageStore {
+	return &PostgresQuotaUsageStore{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// LoadUsage returns the recorded usage of the period, or zero when none was recorded
+func (s *PostgresQuotaUsageStore) LoadUsage(ctx context.Context, userId string, period domain.Period, start time.Time) (int, error) {
+	s.logger.Debug().Str("user_id", userId).Str("period", string(period)).Msg("Loading quota usage")
+
+	var used int
+	err := s.db.QueryRow(ctx,
+		`SELECT used FROM quota_usage WHERE user_id = $1 AND period = $2 AND period_start = $3`,
+		userId, string(period), start,
+	).Scan(&used)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to load quota usage: %w", err)
+	}
+
+	return used, nil
+}
+
+// SaveUsages records the usage of every period in a single batch, keeping the higher value when one is already recorded
+func (s *PostgresQuotaUsageStore) SaveUsages(ctx context.Context, usages []domain.QuotaUsage) error {
+	s.logger.Debug().Int("usages", len(usages)).Msg("Saving quota usage")
+
+	batch := &pgx.Batch{}
+	for _, usage := range usages {
+		batch.Queue(
+			`INSERT INTO quota_usage (user_id, period, period_start, used, updated_at)
+			VALUES ($1, $2, $3, $4, NOW())
+			ON CONFLICT (user_id, period, period_start)
+			DO UPDATE SET used = GREATEST(quota_usage.used, EXCLUDED.used), updated_at = NOW()`,
+			usage.UserID, string(usage.Period), usage.PeriodStart, usage.Used,
+		)
+	}
+
+	results := s.db.SendBatch(ctx, batch)
+	defer results.Close()
+
+	for range usages {
+		if _, err := results.Exec(); err != nil {
+			return fmt.Errorf("failed to save quota usage: %w", err)
+		}
+	}
+
+	return nil
+}
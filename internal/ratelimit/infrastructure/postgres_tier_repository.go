@@ -0,0 +1,115 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// assignTierQuery inserts a tier assignment or replaces the user's current one
+const assignTierQuery = `INSERT INTO user_tiers (user_id, tier)
+	VALUES ($1, $2)
+	ON CONFLICT (user_id) DO UPDATE SET tier = EXCLUDED.tier, assigned_at = NOW()
+	RETURNING assigned_at`
+
+// PostgresTierRepository implements the TierRepository interface using the user_tiers table
+type PostgresTierRepository struct {
+	logger logger.Logger
+	db     *pgxpool.Pool
+}
+
+// NewPostgresTierRepository creates a new PostgreSQL-based tier repository
+func NewPostgresTierRepository(logger logger.Logger, db *pgxpool.Pool) *PostgresTierRepository {
+	return &PostgresTierRepository{
+		logger: logger,
+		db:     db,
+	}
+}
+
+// Assign stores the user's tier, replacing the current one
+func (r *PostgresTierRepository) Assign(ctx context.Context, assignment *domain.TierAssignment) error {
+	r.logger.Debug().Str("user_id", assignment.UserID).Str("tier", assignment.Tier).Msg("Assigning tier")
+
+	err := r.db.QueryRow(ctx, assignTierQuery, assignment.UserID, assignment.Tier).Scan(&assignment.AssignedAt)
+	if err != nil {
+		if isPgError(err, foreignKeyViolation) {
+			return domain.ErrUnknownTier
+		}
+		return fmt.Errorf("failed to assign tier: %w", err)
+	}
+
+	return nil
+}
+
+// Get loads the user's tier assignment
+func (r *PostgresTierRepository) Get(ctx context.Context, userId string) (*domain.TierAssignment, error) {
+	r.logger.Debug().Str("user_id", userId).Msg("Loading tier assignment")
+
+	assignment := domain.TierAssignment{UserID: userId}
+	err := r.db.QueryRow(ctx,
+		`SELECT tier, assigned_at FROM user_tiers WHERE user_id = $1`,
+		userId,
+	).Scan(&assignment.Tier, &assignment.AssignedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrTierNotAssigned
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tier assignment: %w", err)
+	}
+
+	return &assignment, nil
+}
+
+// Unassign removes the user's tier assignment
+func (r *PostgresTierRepository) Unassign(ctx context.Context, userId string) error {
+	r.logger.Debug().Str("user_id", userId).Msg("Removing tier assignment")
+
+	tag, err := r.db.Exec(ctx, `DELETE FROM user_tiers WHERE user_id = $1`, userId)
+	if err != nil {
+		return fmt.Errorf("failed to remove tier assignment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrTierNotAssigned
+	}
+
+	return nil
+}
+
+// Import stores every assignment in a single transaction, sending them to PostgreSQL in one batch
+func (r *PostgresTierRepository) Import(ctx context.Context, assignments []domain.TierAssignment) error {
+	r.logger.Debug().Int("assignments", len(assignments)).Msg("Importing tier assignments")
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to import tier assignments: %w", err)
+	}
+	// Rolling back after a successful commit is a no-op
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	batch := &pgx.Batch{}
+	for i := range assignments {
+		assignment := &assignments[i]
+		batch.Queue(assignTierQuery, assignment.UserID, assignment.Tier).QueryRow(func(row pgx.Row) error {
+			return row.Scan(&assignment.AssignedAt)
+		})
+	}
+
+	if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+		if isPgError(err, foreignKeyViolation) {
+			return domain.ErrUnknownTier
+		}
+		return fmt.Errorf("failed to import tier assignments: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("failed to import tier assignments: %w", err)
+	}
+
+	return nil
+}
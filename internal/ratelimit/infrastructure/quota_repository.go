@@ -0,0 +1,213 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// quotaScript counts a request against the counter of a calendar period, only if it has room for it.
+// The counter expires at the end of its period. When it does not exist yet the script asks for the usage
+// persisted for the period, so that usage survives a Redis flush.
+// KEYS[1] - counter key
+// ARGV[1] - cost of the request
+// ARGV[2] - limit
+// ARGV[3] - end of the period as a Unix timestamp in milliseconds
+// ARGV[4] - persisted usage to start the counter from, empty when not loaded yet
+// Returns {allowed, used}, or {-1, 0} when the persisted usage is needed
+var quotaScript = redis.NewScript(`
+local requested = tonumber(ARGV[1])
+local limit = tonumber(ARGV[2])
+
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	local persisted = tonumber(ARGV[4])
+	if persisted == nil then
+		return {-1, 0}
+	end
+	redis.call('SET', KEYS[1], persisted, 'PXAT', ARGV[3])
+end
+
+local used = tonumber(redis.call('GET', KEYS[1]))
+if used + requested > limit then
+	return {0, used}
+end
+return {1, redis.call('INCRBY', KEYS[1], requested)}
+`)
+
+// RedisQuotaRepository implements the QuotaRepository interface using a Redis counter per calendar period,
+// backed by a durable usage store. Usage is persisted in the background, so a slow or unavailable store
+// never holds up a check.
+type RedisQuotaRepository struct {
+	logger         logger.Logger
+	redisClient    *redis.Client
+	usageStore     ports.QuotaUsageStore
+	persistTimeout time.Duration
+
+	mu      sync.Mutex
+	unsaved map[string]domain.QuotaUsage // Latest usage per counter key not yet persisted
+	stop    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// NewRedisQuotaRepository creates a new Redis-based quota repository and starts persisting usage every
+// persist interval. The returned cleanup function stops persisting and saves the usage still unsaved.
+func NewRedisQuotaRepository(
+	logger logger.Logger,
+	redisClient *redis.Client,
+	usageStore ports.QuotaUsageStore,
+	cfg *config.Config,
+) (*RedisQuotaRepository, func(), error) {
+	interval := cfg.RateLimit.QuotaPersistInterval
+	if interval <= 0 {
+		return nil, nil, fmt.Errorf("quota persist interval must be greater than 0, got %s", interval)
+	}
+
+	r := &RedisQuotaRepository{
+		logger:         logger,
+		redisClient:    redisClient,
+		usageStore:     usageStore,
+		persistTimeout: interval,
+		unsaved:        make(map[string]domain.QuotaUsage),
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+
+	logger.Info().Dur("persist_interval", interval).Msg("Starting quota usage persister")
+	go func() {
+		defer close(r.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.persistUsage()
+			case <-r.stop:
+				return
+			}
+		}
+	}()
+
+	return r, r.Close, nil
+}
+
+// Close stops the background persister and saves the usage it has not saved yet
+func (r *RedisQuotaRepository) Close() {
+	r.once.Do(func() {
+		close(r.stop)
+		<-r.done
+		r.persistUsage()
+		r.logger.Info().Msg("Quota usage persister stopped")
+	})
+}
+
+// Consume adds cost to the counter of the current period if the quota has room for it, and queues the new usage
+// to be persisted
+func (r *RedisQuotaRepository) Consume(userId string, quota domain.Quota, cost int) (*domain.QuotaResult, error) {
+	ctx := context.Background()
+	now := time.Now()
+	start, end := quota.Period.Bounds(now, quota.Location)
+	key := quotaKey(userId, quota.Period, start)
+
+	r.logger.Debug().Str("user_id", userId).Int("limit", quota.Limit).Str("period", string(quota.Period)).Str("key", key).Int("cost", cost).Msg("Checking quota")
+
+	values, err := quotaScript.Run(ctx, r.redisClient, []string{key}, cost, quota.Limit, end.UnixMilli(), "").Int64Slice()
+	if err == nil && values[0] == -1 {
+		// The counter is missing, e.g. at the start of a period or after a flush, so seed it from the store
+		persisted, loadErr := r.usageStore.LoadUsage(ctx, userId, quota.Period, start)
+		if loadErr != nil {
+			r.logger.Error().Str("user_id", userId).Err(loadErr).Msg("Failed to load persisted quota usage")
+			return nil, fmt.Errorf("failed to check quota: %w", loadErr)
+		}
+		// Usage counted since the last save is not in the store yet
+		persisted = max(persisted, r.unsavedUsage(key))
+		values, err = quotaScript.Run(ctx, r.redisClient, []string{key}, cost, quota.Limit, end.UnixMilli(), persisted).Int64Slice()
+	}
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute quota script")
+		return nil, fmt.Errorf("failed to check quota: %w", err)
+	}
+
+	allowed := values[0] == 1
+	used := int(values[1])
+	if allowed {
+		r.queueUsage(key, domain.QuotaUsage{UserID: userId, Period: quota.Period, PeriodStart: start, Used: used})
+	}
+
+	result := &domain.QuotaResult{
+		Allowed:     allowed,
+		Limit:       quota.Limit,
+		Used:        used,
+		Remaining:   max(quota.Limit-used, 0),
+		PeriodStart: start,
+		PeriodEnd:   end,
+		ResetAfter:  end.Sub(now),
+	}
+	if !allowed {
+		result.RetryAfter = result.ResetAfter
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("used", result.Used).Int("remaining", result.Remaining).Dur("reset_after", result.ResetAfter).Bool("allowed", result.Allowed).Msg("Quota check result")
+
+	return result, nil
+}
+
+// queueUsage records usage to be persisted, keeping the highest usage seen for the counter
+func (r *RedisQuotaRepository) queueUsage(key string, usage domain.QuotaUsage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if queued, ok := r.unsaved[key]; !ok || usage.Used > queued.Used {
+		r.unsaved[key] = usage
+	}
+}
+
+// unsavedUsage returns the usage of the counter not persisted yet, zero when there is none
+func (r *RedisQuotaRepository) unsavedUsage(key string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.unsaved[key].Used
+}
+
+// persistUsage saves the usage queued since the last save in one batch. When the save fails the usage is queued
+// again, unless newer usage was queued in the meantime, and retried on the next run.
+func (r *RedisQuotaRepository) persistUsage() {
+	r.mu.Lock()
+	batch := r.unsaved
+	r.unsaved = make(map[string]domain.QuotaUsage, len(batch))
+	r.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+
+	usages := make([]domain.QuotaUsage, 0, len(batch))
+	for _, usage := range batch {
+		usages = append(usages, usage)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.persistTimeout)
+	defer cancel()
+
+	if err := r.usageStore.SaveUsages(ctx, usages); err != nil {
+		// Redis stays the source of truth while it is up, so a late save only risks usage lost to a flush meanwhile
+		r.logger.Error().Int("usages", len(usages)).Err(err).Msg("Failed to persist quota usage, retrying on the next run")
+		for key, usage := range batch {
+			r.queueUsage(key, usage)
+		}
+		return
+	}
+
+	r.logger.Debug().Int("usages", len(usages)).Msg("Quota usage persisted")
+}
@@ -0,0 +1,99 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// fakeQuotaUsageStore records saved usage in memory and fails saves while err is set
+type fakeQuotaUsageStore struct {
+	mu      sync.Mutex
+	err     error
+	batches [][]domain.QuotaUsage
+}
+
+func (s *fakeQuotaUsageStore) LoadUsage(ctx context.Context, userId string, period domain.Period, start time.Time) (int, error) {
+	return 0, nil
+}
+
+func (s *fakeQuotaUsageStore) SaveUsages(ctx context.Context, usages []domain.QuotaUsage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, usages)
+	return nil
+}
+
+func newTestQuotaRepository(store *fakeQuotaUsageStore) *RedisQuotaRepository {
+	return &RedisQuotaRepository{
+		logger:         logger.NewWithLevel("disabled"),
+		usageStore:     store,
+		persistTimeout: time.Second,
+		unsaved:        make(map[string]domain.QuotaUsage),
+	}
+}
+
+func TestQuotaRepositoryPersistsLatestUsageInOneBatch(t *testing.T) {
+	store := &fakeQuotaUsageStore{}
+	r := newTestQuotaRepository(store)
+	start := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	r.queueUsage("quota:alice", domain.QuotaUsage{UserID: "alice", Period: domain.PeriodMonth, PeriodStart: start, Used: 3})
+	r.queueUsage("quota:alice", domain.QuotaUsage{UserID: "alice", Period: domain.PeriodMonth, PeriodStart: start, Used: 5})
+	// A reply arriving out of order must not lower the usage
+	r.queueUsage("quota:alice", domain.QuotaUsage{UserID: "alice", Period: domain.PeriodMonth, PeriodStart: start, Used: 4})
+	r.queueUsage("quota:bob", domain.QuotaUsage{UserID: "bob", Period: domain.PeriodMonth, PeriodStart: start, Used: 1})
+
+	r.persistUsage()
+
+	if len(store.batches) != 1 {
+		t.Fatalf("saved %d batches, want 1", len(store.batches))
+	}
+	used := map[string]int{}
+	for _, usage := range store.batches[0] {
+		used[usage.UserID] = usage.Used
+	}
+	if used["alice"] != 5 || used["bob"] != 1 || len(used) != 2 {
+		t.Errorf("saved usage %v, want alice 5 and bob 1", used)
+	}
+	if got := r.unsavedUsage("quota:alice"); got != 0 {
+		t.Errorf("unsaved usage after persisting = %d, want 0", got)
+	}
+
+	// Nothing queued, nothing saved
+	r.persistUsage()
+	if len(store.batches) != 1 {
+		t.Errorf("saved %d batches with nothing queued, want 1", len(store.batches))
+	}
+}
+
+func TestQuotaRepositoryRequeuesUsageWhenSaveFails(t *testing.T) {
+	store := &fakeQuotaUsageStore{err: errors.New("connection refused")}
+	r := newTestQuotaRepository(store)
+	start := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+
+	r.queueUsage("quota:alice", domain.QuotaUsage{UserID: "alice", Period: domain.PeriodMonth, PeriodStart: start, Used: 5})
+	r.persistUsage()
+
+	if got := r.unsavedUsage("quota:alice"); got != 5 {
+		t.Fatalf("unsaved usage after a failed save = %d, want 5", got)
+	}
+
+	// Usage counted while the store was down wins over the requeued usage
+	r.queueUsage("quota:alice", domain.QuotaUsage{UserID: "alice", Period: domain.PeriodMonth, PeriodStart: start, Used: 7})
+	store.err = nil
+	r.persistUsage()
+
+	if len(store.batches) != 1 || len(store.batches[0]) != 1 || store.batches[0][0].Used != 7 {
+		t.Fatalf("saved batches %v, want a single usage of 7", store.batches)
+	}
+}
@@ -3,96 +3,408 @@ package infrastructure
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/redis/go-redis/v9"
 
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
 	"github.com/go-clean/platform/logger"
 )
 
-// RedisRateLimitRepository implements the RateLimitRepository interface using Redis
+// fixedWindowScript increments the counter of the current window and makes sure it expires.
+// The expiry is set whenever the key has none, so a counter can never outlive its window.
+// KEYS[1] - counter key
+// ARGV[1] - milliseconds left in the window
+// Returns {count, milliseconds until reset}
+var fixedWindowScript = redis.NewScript(`
+local count = redis.call('INCR', KEYS[1])
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+	ttl = tonumber(ARGV[1])
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+
+return {count, ttl}
+`)
+
+// fixedWindowAllScript counts a request against the fixed window of every rule, only if all of them have room for it.
+// KEYS[i] - counter key of rule i
+// ARGV[1] - cost of the request
+// ARGV[2i], ARGV[2i+1] - limit and milliseconds left in the window of rule i
+// Returns {allowed, remaining, milliseconds until reset, milliseconds until retry} per rule
+var fixedWindowAllScript = redis.NewScript(`
+local requested = tonumber(ARGV[1])
+
+local windows = {}
+local all_allowed = true
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 2])
+	local left = tonumber(ARGV[i * 2 + 1])
+
+	local count = tonumber(redis.call('GET', key)) or 0
+	local ttl = redis.call('PTTL', key)
+	if ttl < 0 then
+		ttl = left
+	end
+
+	if count + requested > limit then
+		all_allowed = false
+	end
+	windows[i] = {count = count, ttl = ttl}
+end
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 2])
+	local state = windows[i]
+
+	local allowed = 0
+	local retry_after = state.ttl
+	if state.count + requested <= limit then
+		allowed = 1
+		retry_after = 0
+		if all_allowed then
+			state.count = redis.call('INCRBY', key, requested)
+			if redis.call('PTTL', key) < 0 then
+				redis.call('PEXPIRE', key, state.ttl)
+			end
+		end
+	end
+
+	table.insert(results, allowed)
+	table.insert(results, math.max(limit - state.count, 0))
+	table.insert(results, state.ttl)
+	table.insert(results, retry_after)
+end
+
+return results
+`)
+
+// fixedWindowPeekScript reads the counter of the current window without touching it.
+// KEYS[1] - counter key
+// ARGV[1] - limit
+// ARGV[2] - milliseconds left in the window
+// Returns {allowed, remaining, milliseconds until reset, milliseconds until retry}
+var fixedWindowPeekScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local left = tonumber(ARGV[2])
+
+local count = tonumber(redis.call('GET', KEYS[1])) or 0
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+	ttl = left
+end
+
+if count < limit then
+	return {1, limit - count, ttl, 0}
+end
+return {0, 0, ttl, ttl}
+`)
+
+// fixedWindowRefundScript takes units back from the counter of the window each rule's request was counted in, never
+// going below zero, and reports the rule's current window. The counter of a window that has ended is gone, so a refund
+// arriving after the window rolled over changes nothing.
+// KEYS[2i-1] - counter key of the window rule i's request was counted in
+// KEYS[2i] - counter key of rule i's current window, which is the same key until the window ends
+// ARGV[1] - units to refund
+// ARGV[2i], ARGV[2i+1] - limit and milliseconds left in the current window of rule i
+// Returns {allowed, remaining, milliseconds until reset, milliseconds until retry} per rule
+var fixedWindowRefundScript = redis.NewScript(`
+local refunded = tonumber(ARGV[1])
+
+local results = {}
+for i = 1, #KEYS / 2 do
+	local counted_key = KEYS[i * 2 - 1]
+	local current_key = KEYS[i * 2]
+	local limit = tonumber(ARGV[i * 2])
+	local left = tonumber(ARGV[i * 2 + 1])
+
+	if redis.call('PTTL', counted_key) > 0 then
+		local counted = math.max((tonumber(redis.call('GET', counted_key)) or 0) - refunded, 0)
+		redis.call('SET', counted_key, counted, 'KEEPTTL')
+	end
+
+	local count = tonumber(redis.call('GET', current_key)) or 0
+	local ttl = redis.call('PTTL', current_key)
+	if ttl < 0 then
+		ttl = left
+	end
+
+	if count < limit then
+		table.insert(results, 1)
+		table.insert(results, limit - count)
+		table.insert(results, ttl)
+		table.insert(results, 0)
+	else
+		table.insert(results, 0)
+		table.insert(results, 0)
+		table.insert(results, ttl)
+		table.insert(results, ttl)
+	end
+end
+
+return results
+`)
+
+// fixedWindowAdjustScript overwrites the counter and/or expiry of the current window.
+// The current value is kept for whichever of the two is not given, and a window is started when none exists.
+// KEYS[1] - counter key
+// ARGV[1] - new count, empty to keep the current count
+// ARGV[2] - new milliseconds until reset, 0 to keep the current expiry
+// ARGV[3] - milliseconds left in the window
+// Returns {count, milliseconds until reset}
+var fixedWindowAdjustScript = redis.NewScript(`
+local count = tonumber(ARGV[1])
+if count == nil then
+	count = tonumber(redis.call('GET', KEYS[1])) or 0
+end
+
+local ttl = tonumber(ARGV[2])
+if ttl <= 0 then
+	ttl = redis.call('PTTL', KEYS[1])
+	if ttl < 0 then
+		ttl = tonumber(ARGV[3])
+	end
+end
+
+redis.call('SET', KEYS[1], count, 'PX', ttl)
+return {count, ttl}
+`)
+
+// RedisRateLimitRepository implements the RateLimitRepository interface using Redis. Windows are aligned to multiples
+// of their size by the clock, and every window has its own counter keyed by its start.
 type RedisRateLimitRepository struct {
 	logger      logger.Logger
 	redisClient *redis.Client
-	windowSize  time.Duration
+	clock       ports.Clock
 }
 
 // NewRedisRateLimitRepository creates a new Redis-based rate limit repository
 func NewRedisRateLimitRepository(
 	logger logger.Logger,
 	redisClient *redis.Client,
+	clock ports.Clock,
 ) *RedisRateLimitRepository {
 	return &RedisRateLimitRepository{
 		logger:      logger,
 		redisClient: redisClient,
-		windowSize:  time.Minute, // Default 1-minute window
+		clock:       clock,
 	}
 }
 
 // RateLimit checks if a user is allowed to make a request based on the rate limit
-func (r *RedisRateLimitRepository) RateLimit(userId string, limit int) bool {
+func (r *RedisRateLimitRepository) RateLimit(userId string, limit int, window time.Duration) bool {
+	result, err := r.RateLimitWithDetail(userId, limit, window)
+	if err != nil {
+		return false // Fail closed - deny request on error
+	}
+	return result.Allowed
+}
+
+// RateLimitWithDetail checks if a user is allowed to make a request and returns detailed information
+func (r *RedisRateLimitRepository) RateLimitWithDetail(userId string, limit int, window time.Duration) (*domain.RateLimitResult, error) {
 	ctx := context.Background()
-	key := fmt.Sprintf("rate_limit:%s", userId)
+	now := r.clock.Now()
+	start := windowStart(window, now)
+	key := windowKey("rate_limit", userId, window, start)
 
-	r.logger.Debug().Str("user_id", userId).Int("limit", limit).Str("key", key).Msg("Checking rate limit")
+	r.logger.Debug().Str("user_id", userId).Int("limit", limit).Dur("window", window).Str("key", key).Msg("Checking rate limit with detail")
 
-	// Use Redis pipeline for atomic operations
-	pipe := r.redisClient.Pipeline()
+	// Increment and read the expiry in one atomic round trip
+	values, err := fixedWindowScript.Run(ctx, r.redisClient, []string{key}, windowLeft(window, start, now)).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute fixed window script")
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	// Get the current count and TTL
+	currentCount := values[0]
+	ttl := time.Duration(values[1]) * time.Millisecond
+
+	// Calculate remaining requests
+	remaining := limit - int(currentCount)
+	if remaining < 0 {
+		remaining = 0
+	}
 
-	// Set expiration if this is the first request
-	_ = pipe.SetNX(ctx, key, 0, r.windowSize)
-	// Increment the counter
-	incrCmd := pipe.Incr(ctx, key)
+	allowed := currentCount <= int64(limit)
 
-	// Execute pipeline
-	_, err := pipe.Exec(ctx)
+	r.logger.Debug().Str("user_id", userId).Int64("current_count", currentCount).Int("limit", limit).Int("remaining", remaining).Dur("ttl", ttl).Bool("allowed", allowed).Msg("Rate limit check with detail result")
+
+	result := &domain.RateLimitResult{
+		Allowed:    allowed,
+		Limit:      limit,
+		Remaining:  remaining,
+		ResetAfter: ttl,
+		WindowID:   start,
+	}
+	if !allowed {
+		result.RetryAfter = ttl
+	}
+
+	return result, nil
+}
+
+// RateLimitAllWithDetail adds cost to the window of every rule, only if all of them have that much room left
+func (r *RedisRateLimitRepository) RateLimitAllWithDetail(userId string, rules []domain.Rule, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	now := r.clock.Now()
+	starts := windowStarts(rules, now)
+	keys := windowKeys("rate_limit", userId, rules, starts)
+
+	args := []interface{}{cost}
+	for i, rule := range rules {
+		args = append(args, rule.Limit, windowLeft(rule.Window, starts[i], now))
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Checking rate limits with detail")
+
+	values, err := fixedWindowAllScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
 	if err != nil {
-		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute Redis pipeline")
-		return false // Fail closed - deny request on error
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute fixed window script")
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
 	}
 
-	// Get the current count
-	currentCount := incrCmd.Val()
+	results, err := ruleResults(values, rules, time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	for i := range results {
+		results[i].WindowID = starts[i]
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
 
-	r.logger.Debug().Str("user_id", userId).Int64("current_count", currentCount).Int("limit", limit).Bool("allowed", currentCount <= int64(limit)).Msg("Rate limit check result")
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Bool("allowed", compound.Allowed).Msg("Rate limits check with detail result")
 
-	return currentCount <= int64(limit)
+	return compound, nil
 }
 
-// RateLimitWithDetail checks if a user is allowed to make a request and returns detailed information
-func (r *RedisRateLimitRepository) RateLimitWithDetail(userId string, limit int) (int, time.Duration, error) {
+// Peek reports the current window of the rule without counting a request
+func (r *RedisRateLimitRepository) Peek(userId string, rule domain.Rule) (*domain.RateLimitResult, error) {
 	ctx := context.Background()
-	key := fmt.Sprintf("rate_limit:%s", userId)
+	now := r.clock.Now()
+	start := windowStart(rule.Window, now)
+	key := windowKeys("rate_limit", userId, []domain.Rule{rule}, []int64{start})[0]
 
-	r.logger.Debug().Str("user_id", userId).Int("limit", limit).Str("key", key).Msg("Checking rate limit with detail")
+	r.logger.Debug().Str("user_id", userId).Int("limit", rule.Limit).Dur("window", rule.Window).Str("key", key).Msg("Peeking rate limit")
 
-	// Use Redis pipeline for atomic operations
-	pipe := r.redisClient.Pipeline()
+	values, err := fixedWindowPeekScript.Run(ctx, r.redisClient, []string{key}, rule.Limit, windowLeft(rule.Window, start, now)).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute fixed window peek script")
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
 
-	_ = pipe.SetNX(ctx, key, 0, r.windowSize)
-	// Increment the counter
-	incrCmd := pipe.Incr(ctx, key)
-	// Get TTL for reset time calculation
-	ttlCmd := pipe.TTL(ctx, key)
+	results, err := ruleResults(values, []domain.Rule{rule}, time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
+	results[0].WindowID = start
+
+	r.logger.Debug().Str("user_id", userId).Int("remaining", results[0].Remaining).Dur("reset_after", results[0].ResetAfter).Bool("allowed", results[0].Allowed).Msg("Rate limit peek result")
+
+	return &results[0], nil
+}
+
+// Reset deletes the user's counter of the current window
+func (r *RedisRateLimitRepository) Reset(userId string, window time.Duration) error {
+	ctx := context.Background()
+	key := windowKey("rate_limit", userId, window, windowStart(window, r.clock.Now()))
 
-	// Execute pipeline
-	_, err := pipe.Exec(ctx)
+	r.logger.Debug().Str("user_id", userId).Dur("window", window).Str("key", key).Msg("Resetting rate limit")
+
+	if err := r.redisClient.Del(ctx, key).Err(); err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to delete rate limit key")
+		return fmt.Errorf("failed to reset rate limit: %w", err)
+	}
+
+	return nil
+}
+
+// Adjust overwrites the user's count and/or the expiry of the current window. The reset can only be brought forward,
+// since the next window starts at the end of this one whatever the expiry of its counter.
+func (r *RedisRateLimitRepository) Adjust(userId string, window time.Duration, adjustment domain.Adjustment) (*domain.WindowState, error) {
+	ctx := context.Background()
+	now := r.clock.Now()
+	start := windowStart(window, now)
+	key := windowKey("rate_limit", userId, window, start)
+
+	left, err := adjustableLeft(window, adjustment, now)
 	if err != nil {
-		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute Redis pipeline")
-		return 0, 0, fmt.Errorf("failed to check rate limit: %w", err)
+		return nil, err
 	}
 
-	// Get the current count and TTL
-	currentCount := incrCmd.Val()
-	ttl := ttlCmd.Val()
+	count := ""
+	if adjustment.Count != nil {
+		count = strconv.Itoa(*adjustment.Count)
+	}
 
-	// Calculate remaining requests
-	remaining := limit - int(currentCount)
-	if remaining < 0 {
-		remaining = 0
+	r.logger.Debug().Str("user_id", userId).Dur("window", window).Str("count", count).Dur("reset_after", adjustment.ResetAfter).Str("key", key).Msg("Adjusting rate limit")
+
+	values, err := fixedWindowAdjustScript.Run(ctx, r.redisClient, []string{key}, count, adjustment.ResetAfter.Milliseconds(), left).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute fixed window adjust script")
+		return nil, fmt.Errorf("failed to adjust rate limit: %w", err)
+	}
+
+	state := &domain.WindowState{
+		Count:      int(values[0]),
+		ResetAfter: time.Duration(values[1]) * time.Millisecond,
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("count", state.Count).Dur("reset_after", state.ResetAfter).Msg("Rate limit adjusted")
+
+	return state, nil
+}
+
+// adjustableLeft returns the milliseconds left in the current window, or ErrResetAfterBeyondWindow when the adjustment
+// moves the reset past its end
+func adjustableLeft(window time.Duration, adjustment domain.Adjustment, now time.Time) (int64, error) {
+	left := windowLeft(window, windowStart(window, now), now)
+	if adjustment.ResetAfter.Milliseconds() > left {
+		return 0, fmt.Errorf("%w: the window ends in %s", domain.ErrResetAfterBeyondWindow, time.Duration(left)*time.Millisecond)
+	}
+	return left, nil
+}
+
+// Refund takes cost back from the window of every rule the request was counted in, while that window lasts
+func (r *RedisRateLimitRepository) Refund(userId string, rules []domain.Rule, windowIDs []int64, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	if len(windowIDs) != len(rules) {
+		return nil, fmt.Errorf("failed to refund rate limit: %w", domain.ErrWindowsRequired)
+	}
+
+	now := r.clock.Now()
+	starts := windowStarts(rules, now)
+	counted := windowKeys("rate_limit", userId, rules, windowIDs)
+	current := windowKeys("rate_limit", userId, rules, starts)
+
+	keys := make([]string, 0, len(rules)*2)
+	args := []interface{}{cost}
+	for i, rule := range rules {
+		keys = append(keys, counted[i], current[i])
+		args = append(args, rule.Limit, windowLeft(rule.Window, starts[i], now))
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Refunding rate limit")
+
+	values, err := fixedWindowRefundScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute fixed window refund script")
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, rules, time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+	for i := range results {
+		results[i].WindowID = starts[i]
 	}
+	compound := domain.NewCompoundRateLimitResult(results)
 
-	r.logger.Debug().Str("user_id", userId).Int64("current_count", currentCount).Int("limit", limit).Int("remaining", remaining).Dur("ttl", ttl).Msg("Rate limit check with detail result")
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Msg("Rate limit refund result")
 
-	return remaining, ttl, nil
+	return compound, nil
 }
@@ -0,0 +1,155 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// testRedis is an in-memory Redis whose clock only moves when the test advances it, both for the scripts reading
+// TIME and for key expiry. It is the clock of the repositories under test too.
+type testRedis struct {
+	server *miniredis.Miniredis
+	client *redis.Client
+	now    time.Time
+}
+
+func newTestRedis(t *testing.T) *testRedis {
+	t.Helper()
+	server := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: server.Addr()})
+	t.Cleanup(func() { client.Close() })
+
+	now := time.Date(2026, time.October, 16, 12, 0, 0, 0, time.UTC)
+	server.SetTime(now)
+	return &testRedis{server: server, client: client, now: now}
+}
+
+// Now returns the time of Redis
+func (r *testRedis) Now() time.Time {
+	return r.now
+}
+
+// advance moves the clock of Redis forward
+func (r *testRedis) advance(d time.Duration) {
+	r.now = r.now.Add(d)
+	r.server.SetTime(r.now)
+	r.server.FastForward(d)
+}
+
+// rateLimitStep is a call made against a repository and the result it must report
+type rateLimitStep struct {
+	name       string
+	advance    time.Duration // Time passing before the call
+	call       func(repository ports.RateLimitRepository) (*domain.CompoundRateLimitResult, error)
+	allowed    bool
+	remaining  []int         // Remaining quota of every rule
+	retryAfter time.Duration // Retry after of the binding rule
+}
+
+// check counts a request costing cost against the rules
+func check(rules []domain.Rule, cost int) func(ports.RateLimitRepository) (*domain.CompoundRateLimitResult, error) {
+	return func(repository ports.RateLimitRepository) (*domain.CompoundRateLimitResult, error) {
+		return repository.RateLimitAllWithDetail("alice", rules, cost)
+	}
+}
+
+// peek reports the quota of the rule
+func peek(rule domain.Rule) func(ports.RateLimitRepository) (*domain.CompoundRateLimitResult, error) {
+	return func(repository ports.RateLimitRepository) (*domain.CompoundRateLimitResult, error) {
+		result, err := repository.Peek("alice", rule)
+		if err != nil {
+			return nil, err
+		}
+		return domain.NewCompoundRateLimitResult([]domain.RateLimitResult{*result}), nil
+	}
+}
+
+// refund gives cost back to the windows of the rules the request was counted in
+func refund(rules []domain.Rule, windowIDs []int64, cost int) func(ports.RateLimitRepository) (*domain.CompoundRateLimitResult, error) {
+	return func(repository ports.RateLimitRepository) (*domain.CompoundRateLimitResult, error) {
+		return repository.Refund("alice", rules, windowIDs, cost)
+	}
+}
+
+// reset clears the state of the rule's window, then reports its quota
+func reset(rule domain.Rule) func(ports.RateLimitRepository) (*domain.CompoundRateLimitResult, error) {
+	return func(repository ports.RateLimitRepository) (*domain.CompoundRateLimitResult, error) {
+		if err := repository.(ports.RateLimitAdminRepository).Reset("alice", rule.Window); err != nil {
+			return nil, err
+		}
+		return peek(rule)(repository)
+	}
+}
+
+// runRateLimitSteps makes the calls of the steps in order, each continuing from the state the previous ones left
+func runRateLimitSteps(t *testing.T, redis *testRedis, repository ports.RateLimitRepository, steps []rateLimitStep) {
+	t.Helper()
+	for _, step := range steps {
+		redis.advance(step.advance)
+
+		compound, err := step.call(repository)
+		if err != nil {
+			t.Fatalf("%s: error = %v", step.name, err)
+		}
+		if compound.Allowed != step.allowed {
+			t.Errorf("%s: allowed = %t, want %t", step.name, compound.Allowed, step.allowed)
+		}
+		remaining := make([]int, len(compound.Results))
+		for i, result := range compound.Results {
+			remaining[i] = result.Remaining
+		}
+		if len(remaining) != len(step.remaining) {
+			t.Fatalf("%s: %d results, want %d", step.name, len(remaining), len(step.remaining))
+		}
+		for i := range remaining {
+			if remaining[i] != step.remaining[i] {
+				t.Errorf("%s: remaining = %v, want %v", step.name, remaining, step.remaining)
+				break
+			}
+		}
+		if got := compound.Binding().RetryAfter; got != step.retryAfter {
+			t.Errorf("%s: retry after = %s, want %s", step.name, got, step.retryAfter)
+		}
+	}
+}
+
+func TestRedisRateLimitRepository(t *testing.T) {
+	redis := newTestRedis(t)
+	repository := NewRedisRateLimitRepository(logger.NewWithLevel("disabled"), redis.client, redis)
+
+	// The clock starts at the beginning of a window of both rules
+	second := domain.Rule{Limit: 3, Window: time.Second}
+	minute := domain.Rule{Limit: 5, Window: time.Minute}
+	tenant := domain.Rule{Limit: 3, Window: time.Second, Subject: "tenant=acme"}
+	start := redis.now.UnixMilli()
+
+	runRateLimitSteps(t, redis, repository, []rateLimitStep{
+		{name: "admits the first request", call: check([]domain.Rule{second}, 1), allowed: true, remaining: []int{2}},
+		{name: "counts the cost", call: check([]domain.Rule{second}, 2), allowed: true, remaining: []int{0}},
+		{name: "denies until the window resets", advance: 400 * time.Millisecond, call: check([]domain.Rule{second}, 1), remaining: []int{0}, retryAfter: 600 * time.Millisecond},
+		{name: "peek reports the full window", call: peek(second), remaining: []int{0}, retryAfter: 600 * time.Millisecond},
+		{name: "peek reports the next window", advance: 600 * time.Millisecond, call: peek(second), allowed: true, remaining: []int{3}},
+		{name: "peek counts nothing", call: peek(second), allowed: true, remaining: []int{3}},
+		{name: "denies every rule when one lacks room", call: check([]domain.Rule{second, minute}, 4), remaining: []int{3, 5}, retryAfter: time.Second},
+		{name: "counts nothing against the other rule", call: peek(minute), allowed: true, remaining: []int{5}},
+		{name: "counts against every rule", call: check([]domain.Rule{second, minute}, 2), allowed: true, remaining: []int{1, 3}},
+		{name: "refund takes the count back", call: refund([]domain.Rule{second, minute}, []int64{start + 1000, start}, 1), allowed: true, remaining: []int{2, 4}},
+		{name: "refund stops at zero", call: refund([]domain.Rule{second}, []int64{start + 1000}, 5), allowed: true, remaining: []int{3}},
+		{name: "counts the whole limit", call: check([]domain.Rule{second}, 3), allowed: true, remaining: []int{0}},
+		{name: "counts into the next window", advance: time.Second, call: check([]domain.Rule{second}, 1), allowed: true, remaining: []int{2}},
+		{name: "refund of an ended window leaves the next one alone", call: refund([]domain.Rule{second}, []int64{start + 1000}, 3), allowed: true, remaining: []int{2}},
+		{name: "refund of the current window takes the count back", call: refund([]domain.Rule{second}, []int64{start + 2000}, 1), allowed: true, remaining: []int{3}},
+		{name: "counts the whole limit again", call: check([]domain.Rule{second}, 3), allowed: true, remaining: []int{0}},
+		{name: "reset starts a fresh window", call: reset(second), allowed: true, remaining: []int{3}},
+		{name: "counts against the subject of the rule", call: check([]domain.Rule{tenant}, 2), allowed: true, remaining: []int{1}},
+		{name: "peek reports the counter of the subject", call: peek(tenant), allowed: true, remaining: []int{1}},
+		{name: "peek leaves the user apart from the subject", call: peek(second), allowed: true, remaining: []int{3}},
+	})
+}
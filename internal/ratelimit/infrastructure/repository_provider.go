@@ -0,0 +1,65 @@
+package infrastructure
+
+import (
+	"fmt"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+)
+
+// AlgorithmRepositoryProvider implements the RateLimitRepositoryProvider interface
+type AlgorithmRepositoryProvider struct {
+	logger       logger.Logger
+	repositories map[domain.Algorithm]ports.RateLimitRepository
+	admins       map[domain.Algorithm]ports.RateLimitAdminRepository
+}
+
+// NewAlgorithmRepositoryProvider creates a new provider with a repository registered for every algorithm
+func NewAlgorithmRepositoryProvider(
+	logger logger.Logger,
+	fixedWindowRepository ports.RateLimitRepository,
+	fixedWindowAdminRepository ports.RateLimitAdminRepository,
+	tokenBucketRepository *TokenBucketRateLimitRepository,
+	slidingWindowLogRepository *SlidingWindowLogRateLimitRepository,
+	slidingWindowCounterRepository *SlidingWindowCounterRateLimitRepository,
+	gcraRepository *GCRARateLimitRepository,
+) *AlgorithmRepositoryProvider {
+	return &AlgorithmRepositoryProvider{
+		logger: logger,
+		repositories: map[domain.Algorithm]ports.RateLimitRepository{
+			domain.AlgorithmFixedWindow:          fixedWindowRepository,
+			domain.AlgorithmTokenBucket:          tokenBucketRepository,
+			domain.AlgorithmSlidingWindowLog:     slidingWindowLogRepository,
+			domain.AlgorithmSlidingWindowCounter: slidingWindowCounterRepository,
+			domain.AlgorithmGCRA:                 gcraRepository,
+		},
+		admins: map[domain.Algorithm]ports.RateLimitAdminRepository{
+			domain.AlgorithmFixedWindow:          fixedWindowAdminRepository,
+			domain.AlgorithmTokenBucket:          tokenBucketRepository,
+			domain.AlgorithmSlidingWindowLog:     slidingWindowLogRepository,
+			domain.AlgorithmSlidingWindowCounter: slidingWindowCounterRepository,
+			domain.AlgorithmGCRA:                 gcraRepository,
+		},
+	}
+}
+
+// Repository returns the repository for the given algorithm
+func (p *AlgorithmRepositoryProvider) Repository(algorithm domain.Algorithm) (ports.RateLimitRepository, error) {
+	repository, exists := p.repositories[algorithm]
+	if !exists {
+		p.logger.Error().Str("algorithm", string(algorithm)).Msg("No repository registered for algorithm")
+		return nil, fmt.Errorf("unsupported rate limit algorithm: %s", algorithm)
+	}
+	return repository, nil
+}
+
+// AdminRepository returns the repository making administrative changes to the state of the given algorithm
+func (p *AlgorithmRepositoryProvider) AdminRepository(algorithm domain.Algorithm) (ports.RateLimitAdminRepository, error) {
+	repository, exists := p.admins[algorithm]
+	if !exists {
+		p.logger.Error().Str("algorithm", string(algorithm)).Msg("No admin repository registered for algorithm")
+		return nil, fmt.Errorf("unsupported rate limit algorithm: %s", algorithm)
+	}
+	return repository, nil
+}
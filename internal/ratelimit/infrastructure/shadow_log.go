@@ -0,0 +1,121 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// shadowPruneBatch caps the number of expired subjects pruned per recorded denial, so a burst of
+// expirations is spread over several calls instead of blocking Redis
+const shadowPruneBatch = 100
+
+// recordShadowScript counts a would-deny of a subject and notes when it happened, pruning subjects
+// whose last would-deny is older than the retention period. Both keys expire when the policy stops denying.
+// KEYS[1] - sorted set of subjects scored by their last would-deny in milliseconds
+// KEYS[2] - hash of would-deny counts per subject
+// ARGV[1] - subject
+// ARGV[2] - retention in milliseconds
+// ARGV[3] - maximum number of expired subjects to prune
+// Returns the subject's would-deny count
+var recordShadowScript = redis.NewScript(`
+local retention = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local expired = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', now - retention, 'LIMIT', 0, tonumber(ARGV[3]))
+if #expired > 0 then
+	redis.call('ZREM', KEYS[1], unpack(expired))
+	redis.call('HDEL', KEYS[2], unpack(expired))
+end
+
+redis.call('ZADD', KEYS[1], now, ARGV[1])
+local count = redis.call('HINCRBY', KEYS[2], ARGV[1], 1)
+redis.call('PEXPIRE', KEYS[1], retention)
+redis.call('PEXPIRE', KEYS[2], retention)
+return count
+`)
+
+// RedisShadowLog implements the ShadowLog interface using a Redis sorted set and hash per policy
+type RedisShadowLog struct {
+	logger      logger.Logger
+	redisClient *redis.Client
+	retention   time.Duration
+}
+
+// NewRedisShadowLog creates a new Redis-based shadow log keeping would-denies for the configured retention
+func NewRedisShadowLog(
+	logger logger.Logger,
+	redisClient *redis.Client,
+	cfg *config.Config,
+) *RedisShadowLog {
+	return &RedisShadowLog{
+		logger:      logger,
+		redisClient: redisClient,
+		retention:   cfg.RateLimit.ShadowRetention,
+	}
+}
+
+// Record counts a would-deny of the subject under the policy
+func (l *RedisShadowLog) Record(ctx context.Context, policy string, subject string) error {
+	lastKey, countKey := shadowKeys(policy)
+
+	count, err := recordShadowScript.Run(ctx, l.redisClient, []string{lastKey, countKey}, subject, l.retention.Milliseconds(), shadowPruneBatch).Int64()
+	if err != nil {
+		l.logger.Error().Str("policy", policy).Str("subject", subject).Err(err).Msg("Failed to execute record shadow script")
+		return fmt.Errorf("failed to record shadow denial: %w", err)
+	}
+
+	l.logger.Debug().Str("policy", policy).Str("subject", subject).Int64("denials", count).Msg("Shadow denial recorded")
+
+	return nil
+}
+
+// Report returns up to limit subjects the policy would have denied within the retention period, most recently denied first
+func (l *RedisShadowLog) Report(ctx context.Context, policy string, limit int) ([]domain.ShadowDenial, error) {
+	lastKey, countKey := shadowKeys(policy)
+	since := time.Now().Add(-l.retention).UnixMilli()
+
+	members, err := l.redisClient.ZRevRangeByScoreWithScores(ctx, lastKey, &redis.ZRangeBy{
+		Min:   fmt.Sprintf("(%d", since),
+		Max:   "+inf",
+		Count: int64(limit),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shadow report: %w", err)
+	}
+
+	denials := make([]domain.ShadowDenial, len(members))
+	if len(members) == 0 {
+		return denials, nil
+	}
+
+	subjects := make([]string, len(members))
+	for i, member := range members {
+		subjects[i] = member.Member.(string)
+	}
+	counts, err := l.redisClient.HMGet(ctx, countKey, subjects...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load shadow report: %w", err)
+	}
+
+	for i, member := range members {
+		denials[i] = domain.ShadowDenial{
+			Subject:      subjects[i],
+			LastDeniedAt: time.UnixMilli(int64(member.Score)),
+		}
+		// A subject pruned between the two reads has no count left
+		if count, ok := counts[i].(string); ok {
+			denials[i].Denials, _ = strconv.ParseInt(count, 10, 64)
+		}
+	}
+	return denials, nil
+}
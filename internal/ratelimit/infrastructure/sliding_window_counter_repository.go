@@ -0,0 +1,352 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// slidingWindowCounterScript approximates a sliding window by weighting the previous fixed window's
+// count by the portion of it that still overlaps the sliding window, plus the current window's count.
+// Both counters of a rule live in a single hash, and requests are only counted when every rule allows them.
+// KEYS[i] - counter key of rule i
+// ARGV[1] - cost of the request
+// ARGV[2i], ARGV[2i+1] - limit and window size in milliseconds of rule i
+// Returns {allowed, remaining, milliseconds until the current window ends, milliseconds until retry} per rule,
+// followed by the time in milliseconds the request was counted at
+var slidingWindowCounterScript = redis.NewScript(`
+local requested = tonumber(ARGV[1])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local counters = {}
+local all_allowed = true
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 2])
+	local window = tonumber(ARGV[i * 2 + 1])
+	local current_window = math.floor(now / window)
+	local elapsed = now - current_window * window
+
+	local state = redis.call('HMGET', key, 'window', 'current', 'previous')
+	local stored_window = tonumber(state[1])
+	local current = tonumber(state[2]) or 0
+	local previous = tonumber(state[3]) or 0
+	if stored_window ~= current_window then
+		if stored_window == current_window - 1 then
+			previous = current
+		else
+			previous = 0
+		end
+		current = 0
+	end
+
+	local estimate = previous * (window - elapsed) / window + current
+	if estimate + requested > limit then
+		all_allowed = false
+	end
+	counters[i] = {window = current_window, elapsed = elapsed, current = current, previous = previous, estimate = estimate}
+end
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 2])
+	local window = tonumber(ARGV[i * 2 + 1])
+	local counter = counters[i]
+	local elapsed = counter.elapsed
+
+	local allowed = 0
+	local retry_after = 0
+	if counter.estimate + requested <= limit then
+		allowed = 1
+		if all_allowed then
+			counter.current = counter.current + requested
+			counter.estimate = counter.estimate + requested
+		end
+	elseif counter.current + requested <= limit then
+		-- Wait until enough of the previous window has slid out
+		retry_after = math.ceil(window * (1 - (limit - counter.current - requested) / counter.previous)) - elapsed
+	else
+		-- Wait for the next window, then until enough of the current window has slid out
+		retry_after = window - elapsed + math.ceil(window * (1 - math.max(limit - requested, 0) / counter.current))
+	end
+
+	redis.call('HSET', key, 'window', counter.window, 'current', counter.current, 'previous', counter.previous)
+	redis.call('PEXPIRE', key, window * 2 - elapsed)
+
+	table.insert(results, allowed)
+	table.insert(results, math.max(math.floor(limit - counter.estimate), 0))
+	table.insert(results, window - elapsed)
+	table.insert(results, retry_after)
+end
+table.insert(results, now)
+
+return results
+`)
+
+// slidingWindowCounterPeekScript computes the weighted count of a rule without rolling its windows over in Redis.
+// KEYS[1] - counter key
+// ARGV[1] - limit
+// ARGV[2] - window size in milliseconds
+// Returns {allowed, remaining, milliseconds until the current window ends, milliseconds until retry}
+var slidingWindowCounterPeekScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+local current_window = math.floor(now / window)
+local elapsed = now - current_window * window
+
+local state = redis.call('HMGET', KEYS[1], 'window', 'current', 'previous')
+local stored_window = tonumber(state[1])
+local current = tonumber(state[2]) or 0
+local previous = tonumber(state[3]) or 0
+if stored_window ~= current_window then
+	if stored_window == current_window - 1 then
+		previous = current
+	else
+		previous = 0
+	end
+	current = 0
+end
+
+local estimate = previous * (window - elapsed) / window + current
+local remaining = math.max(math.floor(limit - estimate), 0)
+if estimate + 1 <= limit then
+	return {1, remaining, window - elapsed, 0}
+end
+
+local retry_after
+if current + 1 <= limit then
+	retry_after = math.ceil(window * (1 - (limit - current - 1) / previous)) - elapsed
+else
+	retry_after = window - elapsed + math.ceil(window * (1 - (limit - 1) / current))
+end
+return {0, remaining, window - elapsed, retry_after}
+`)
+
+// slidingWindowCounterRefundScript takes units back from the count of the window each rule's request was counted in,
+// never going below zero. The window is either the current or the previous one, since older counts are gone, so a
+// refund never carries over into a later window.
+// KEYS[i] - counter key of rule i
+// ARGV[1] - units to refund
+// ARGV[3i-1], ARGV[3i], ARGV[3i+1] - limit, window size in milliseconds and index of the window the request was
+// counted in of rule i
+// Returns {allowed, remaining, milliseconds until the current window ends, milliseconds until retry} per rule
+var slidingWindowCounterRefundScript = redis.NewScript(`
+local refunded = tonumber(ARGV[1])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 3 - 1])
+	local window = tonumber(ARGV[i * 3])
+	local counted_window = tonumber(ARGV[i * 3 + 1])
+	local current_window = math.floor(now / window)
+	local elapsed = now - current_window * window
+
+	local state = redis.call('HMGET', key, 'window', 'current', 'previous')
+	local stored_window = tonumber(state[1])
+	local current = tonumber(state[2]) or 0
+	local previous = tonumber(state[3]) or 0
+	if stored_window ~= current_window then
+		if stored_window == current_window - 1 then
+			previous = current
+		else
+			previous = 0
+		end
+		current = 0
+	end
+
+	if stored_window then
+		if counted_window == current_window then
+			current = math.max(current - refunded, 0)
+		elseif counted_window == current_window - 1 then
+			previous = math.max(previous - refunded, 0)
+		end
+		redis.call('HSET', key, 'window', current_window, 'current', current, 'previous', previous)
+		redis.call('PEXPIRE', key, window * 2 - elapsed)
+	end
+
+	local estimate = previous * (window - elapsed) / window + current
+	local remaining = math.max(math.floor(limit - estimate), 0)
+	if estimate + 1 <= limit then
+		table.insert(results, 1)
+		table.insert(results, remaining)
+		table.insert(results, window - elapsed)
+		table.insert(results, 0)
+	else
+		local retry_after
+		if current + 1 <= limit then
+			retry_after = math.ceil(window * (1 - (limit - current - 1) / previous)) - elapsed
+		else
+			retry_after = window - elapsed + math.ceil(window * (1 - (limit - 1) / current))
+		end
+		table.insert(results, 0)
+		table.insert(results, remaining)
+		table.insert(results, window - elapsed)
+		table.insert(results, retry_after)
+	end
+end
+
+return results
+`)
+
+// SlidingWindowCounterRateLimitRepository implements the RateLimitRepository interface using a weighted
+// count of the previous and current fixed windows stored in Redis
+type SlidingWindowCounterRateLimitRepository struct {
+	logger      logger.Logger
+	redisClient *redis.Client
+}
+
+// NewSlidingWindowCounterRateLimitRepository creates a new Redis-based sliding window counter rate limit repository
+func NewSlidingWindowCounterRateLimitRepository(
+	logger logger.Logger,
+	redisClient *redis.Client,
+) *SlidingWindowCounterRateLimitRepository {
+	return &SlidingWindowCounterRateLimitRepository{
+		logger:      logger,
+		redisClient: redisClient,
+	}
+}
+
+// RateLimit checks if a user is allowed to make a request based on the rate limit
+func (r *SlidingWindowCounterRateLimitRepository) RateLimit(userId string, limit int, window time.Duration) bool {
+	result, err := r.RateLimitWithDetail(userId, limit, window)
+	if err != nil {
+		return false // Fail closed - deny request on error
+	}
+	return result.Allowed
+}
+
+// RateLimitWithDetail admits the request if the weighted request count over the sliding window stays within limit.
+// For admitted requests the reset time is the end of the current window, for denied requests the retry time
+// is the time until the weighted count drops enough to admit a request again.
+func (r *SlidingWindowCounterRateLimitRepository) RateLimitWithDetail(userId string, limit int, window time.Duration) (*domain.RateLimitResult, error) {
+	compound, err := r.RateLimitAllWithDetail(userId, []domain.Rule{{Limit: limit, Window: window}}, 1)
+	if err != nil {
+		return nil, err
+	}
+	return compound.Binding(), nil
+}
+
+// RateLimitAllWithDetail adds cost to every rule, only if the weighted count of each stays within its limit
+func (r *SlidingWindowCounterRateLimitRepository) RateLimitAllWithDetail(userId string, rules []domain.Rule, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	keys := ruleKeys("sliding_counter", userId, rules)
+
+	args := []interface{}{cost}
+	for _, rule := range rules {
+		args = append(args, rule.Limit, rule.Window.Milliseconds())
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Checking sliding window counter rate limit")
+
+	values, err := slidingWindowCounterScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute sliding window counter script")
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("failed to check rate limit: empty script reply")
+	}
+
+	// The index of the aligned window the request was counted in names it in a refund
+	countedAt := values[len(values)-1]
+	results, err := ruleResults(values[:len(values)-1], rules, time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	for i, rule := range rules {
+		results[i].WindowID = countedAt / rule.Window.Milliseconds()
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
+
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Bool("allowed", compound.Allowed).Msg("Sliding window counter check result")
+
+	return compound, nil
+}
+
+// Peek reports the weighted request count of the rule without counting a request
+func (r *SlidingWindowCounterRateLimitRepository) Peek(userId string, rule domain.Rule) (*domain.RateLimitResult, error) {
+	ctx := context.Background()
+	key := ruleKeys("sliding_counter", userId, []domain.Rule{rule})[0]
+
+	r.logger.Debug().Str("user_id", userId).Int("limit", rule.Limit).Dur("window", rule.Window).Str("key", key).Msg("Peeking sliding window counter rate limit")
+
+	values, err := slidingWindowCounterPeekScript.Run(ctx, r.redisClient, []string{key}, rule.Limit, rule.Window.Milliseconds()).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute sliding window counter peek script")
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, []domain.Rule{rule}, time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("remaining", results[0].Remaining).Dur("reset_after", results[0].ResetAfter).Bool("allowed", results[0].Allowed).Msg("Sliding window counter peek result")
+
+	return &results[0], nil
+}
+
+// Refund takes cost back from the count of the window every rule's request was counted in, while it is the current
+// or the previous window
+func (r *SlidingWindowCounterRateLimitRepository) Refund(userId string, rules []domain.Rule, windowIDs []int64, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	if len(windowIDs) != len(rules) {
+		return nil, fmt.Errorf("failed to refund rate limit: %w", domain.ErrWindowsRequired)
+	}
+	keys := ruleKeys("sliding_counter", userId, rules)
+
+	args := []interface{}{cost}
+	for i, rule := range rules {
+		args = append(args, rule.Limit, rule.Window.Milliseconds(), windowIDs[i])
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Refunding sliding window counter rate limit")
+
+	values, err := slidingWindowCounterRefundScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute sliding window counter refund script")
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, rules, time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
+
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Msg("Sliding window counter refund result")
+
+	return compound, nil
+}
+
+// Reset deletes the user's counts of the current and previous window for the window so the next request starts from zero
+func (r *SlidingWindowCounterRateLimitRepository) Reset(userId string, window time.Duration) error {
+	ctx := context.Background()
+	key := rateLimitKey("sliding_counter", userId, window)
+
+	r.logger.Debug().Str("user_id", userId).Dur("window", window).Str("key", key).Msg("Resetting sliding window counter rate limit")
+
+	if err := r.redisClient.Del(ctx, key).Err(); err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to delete sliding window counter key")
+		return fmt.Errorf("failed to reset rate limit: %w", err)
+	}
+
+	return nil
+}
+
+// Adjust is not supported, since the counter weighs two aligned windows rather than keeping one count with an expiry
+func (r *SlidingWindowCounterRateLimitRepository) Adjust(userId string, window time.Duration, adjustment domain.Adjustment) (*domain.WindowState, error) {
+	return nil, fmt.Errorf("%w: %s", domain.ErrAdjustUnsupported, domain.AlgorithmSlidingWindowCounter)
+}
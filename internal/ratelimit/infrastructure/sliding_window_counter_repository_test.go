@@ -0,0 +1,38 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+func TestSlidingWindowCounterRateLimitRepository(t *testing.T) {
+	redis := newTestRedis(t)
+	repository := NewSlidingWindowCounterRateLimitRepository(logger.NewWithLevel("disabled"), redis.client)
+
+	// The clock starts at the beginning of a window of both rules
+	second := domain.Rule{Limit: 4, Window: time.Second}
+	minute := domain.Rule{Limit: 10, Window: time.Minute}
+	window := redis.now.UnixMilli() / 1000
+
+	runRateLimitSteps(t, redis, repository, []rateLimitStep{
+		{name: "admits into an empty window", call: check([]domain.Rule{second}, 1), allowed: true, remaining: []int{3}},
+		{name: "counts the cost", call: check([]domain.Rule{second}, 2), allowed: true, remaining: []int{1}},
+		// The next window, then a third of it for the weighted count of this one to make room
+		{name: "denies a cost above the room left", call: check([]domain.Rule{second}, 2), remaining: []int{1}, retryAfter: 1334 * time.Millisecond},
+		{name: "peek reports room for one request", call: peek(second), allowed: true, remaining: []int{1}},
+		{name: "denies every rule when one is full", advance: 500 * time.Millisecond, call: check([]domain.Rule{second, minute}, 2), remaining: []int{1, 10}, retryAfter: 834 * time.Millisecond},
+		{name: "counts nothing for the other rule", call: peek(minute), allowed: true, remaining: []int{10}},
+		{name: "refund takes the cost back", call: refund([]domain.Rule{second}, []int64{window}, 2), allowed: true, remaining: []int{3}},
+		{name: "previous window weighs fully at its end", advance: 500 * time.Millisecond, call: check([]domain.Rule{second, minute}, 1), allowed: true, remaining: []int{2, 9}},
+		{name: "previous window weighs less as it slides out", advance: 500 * time.Millisecond, call: peek(second), allowed: true, remaining: []int{2}},
+		{name: "peek counts nothing", call: peek(second), allowed: true, remaining: []int{2}},
+		// Half of the previous window's request still weighs
+		{name: "counts into the next window", advance: time.Second, call: check([]domain.Rule{second}, 1), allowed: true, remaining: []int{2}},
+		{name: "refund of an older window changes nothing", call: refund([]domain.Rule{second}, []int64{window}, 1), allowed: true, remaining: []int{2}},
+		{name: "refund of the previous window takes its count back", call: refund([]domain.Rule{second}, []int64{window + 1}, 1), allowed: true, remaining: []int{3}},
+		{name: "reset clears both windows", call: reset(second), allowed: true, remaining: []int{4}},
+	})
+}
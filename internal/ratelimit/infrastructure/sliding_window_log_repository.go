@@ -0,0 +1,322 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+// slidingWindowLogScript records request timestamps in one sorted set per rule and admits a request
+// only if every rule logged fewer than its limit requests during its last window.
+// KEYS[i] - log key of rule i
+// ARGV[1] - cost of the request, logged as that many entries
+// ARGV[2i], ARGV[2i+1] - limit and window size in microseconds of rule i
+// Returns {allowed, remaining, microseconds until the oldest request leaves the window,
+// microseconds until retry} per rule, followed by the time in microseconds the request was logged at
+var slidingWindowLogScript = redis.NewScript(`
+local requested = tonumber(ARGV[1])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local counts = {}
+local all_allowed = true
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 2])
+	local window = tonumber(ARGV[i * 2 + 1])
+
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+	counts[i] = redis.call('ZCARD', key)
+	if counts[i] + requested > limit then
+		all_allowed = false
+	end
+end
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 2])
+	local window = tonumber(ARGV[i * 2 + 1])
+	local count = counts[i]
+
+	local allowed = 0
+	local retry_after = 0
+	if count + requested <= limit then
+		allowed = 1
+		if all_allowed then
+			for j = 0, requested - 1 do
+				-- The count disambiguates requests logged within the same microsecond
+				redis.call('ZADD', key, now, time[1] .. '.' .. time[2] .. '-' .. (count + j))
+			end
+			count = count + requested
+			redis.call('PEXPIRE', key, math.ceil(window / 1000))
+		end
+	else
+		-- Wait until enough logged requests leave the window to make room for this one
+		local index = count + requested - limit - 1
+		local entry = redis.call('ZRANGE', key, index, index, 'WITHSCORES')
+		retry_after = window
+		if entry[2] then
+			retry_after = tonumber(entry[2]) + window - now
+		end
+	end
+
+	local reset_after = window
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	if oldest[2] then
+		reset_after = tonumber(oldest[2]) + window - now
+	end
+
+	table.insert(results, allowed)
+	table.insert(results, math.max(limit - count, 0))
+	table.insert(results, reset_after)
+	table.insert(results, retry_after)
+end
+table.insert(results, now)
+
+return results
+`)
+
+// slidingWindowLogPeekScript counts the requests logged during the last window without pruning the log.
+// KEYS[1] - log key
+// ARGV[1] - limit
+// ARGV[2] - window size in microseconds
+// Returns {allowed, remaining, microseconds until the oldest request leaves the window, microseconds until retry}
+var slidingWindowLogPeekScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+local since = now - window + 1
+
+local count = redis.call('ZCOUNT', KEYS[1], since, '+inf')
+
+local reset_after = window
+local oldest = redis.call('ZRANGEBYSCORE', KEYS[1], since, '+inf', 'WITHSCORES', 'LIMIT', 0, 1)
+if oldest[2] then
+	reset_after = tonumber(oldest[2]) + window - now
+end
+
+if count < limit then
+	return {1, limit - count, reset_after, 0}
+end
+
+-- Wait until enough logged requests leave the window to make room for one more
+local entry = redis.call('ZRANGEBYSCORE', KEYS[1], since, '+inf', 'WITHSCORES', 'LIMIT', count - limit, 1)
+local retry_after = window
+if entry[2] then
+	retry_after = tonumber(entry[2]) + window - now
+end
+return {0, 0, reset_after, retry_after}
+`)
+
+// slidingWindowLogRefundScript removes the entries of the refunded request from the log of every rule, while they are
+// still within its window. Only entries logged at the time the request was logged at are removed, so a refund arriving
+// after they left the window never takes back a later request.
+// KEYS[i] - log key of rule i
+// ARGV[1] - number of logged requests to remove
+// ARGV[3i-1], ARGV[3i], ARGV[3i+1] - limit, window size in microseconds and time in microseconds the request was
+// logged at of rule i
+// Returns {allowed, remaining, microseconds until the oldest request leaves the window,
+// microseconds until retry} per rule
+var slidingWindowLogRefundScript = redis.NewScript(`
+local refunded = tonumber(ARGV[1])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000000 + tonumber(time[2])
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 3 - 1])
+	local window = tonumber(ARGV[i * 3])
+	local logged_at = ARGV[i * 3 + 1]
+
+	redis.call('ZREMRANGEBYSCORE', key, '-inf', now - window)
+	local entries = redis.call('ZRANGEBYSCORE', key, logged_at, logged_at, 'LIMIT', 0, refunded)
+	if #entries > 0 then
+		redis.call('ZREM', key, unpack(entries))
+	end
+	local count = redis.call('ZCARD', key)
+
+	local reset_after = window
+	local oldest = redis.call('ZRANGE', key, 0, 0, 'WITHSCORES')
+	if oldest[2] then
+		reset_after = tonumber(oldest[2]) + window - now
+	end
+
+	if count < limit then
+		table.insert(results, 1)
+		table.insert(results, limit - count)
+		table.insert(results, reset_after)
+		table.insert(results, 0)
+	else
+		-- Wait until enough logged requests leave the window to make room for one more
+		local entry = redis.call('ZRANGE', key, count - limit, count - limit, 'WITHSCORES')
+		local retry_after = window
+		if entry[2] then
+			retry_after = tonumber(entry[2]) + window - now
+		end
+		table.insert(results, 0)
+		table.insert(results, 0)
+		table.insert(results, reset_after)
+		table.insert(results, retry_after)
+	end
+end
+
+return results
+`)
+
+// SlidingWindowLogRateLimitRepository implements the RateLimitRepository interface using a Redis sorted set
+// holding the timestamp of every admitted request in the current window
+type SlidingWindowLogRateLimitRepository struct {
+	logger      logger.Logger
+	redisClient *redis.Client
+}
+
+// NewSlidingWindowLogRateLimitRepository creates a new Redis-based sliding window log rate limit repository
+func NewSlidingWindowLogRateLimitRepository(
+	logger logger.Logger,
+	redisClient *redis.Client,
+) *SlidingWindowLogRateLimitRepository {
+	return &SlidingWindowLogRateLimitRepository{
+		logger:      logger,
+		redisClient: redisClient,
+	}
+}
+
+// RateLimit checks if a user is allowed to make a request based on the rate limit
+func (r *SlidingWindowLogRateLimitRepository) RateLimit(userId string, limit int, window time.Duration) bool {
+	result, err := r.RateLimitWithDetail(userId, limit, window)
+	if err != nil {
+		return false // Fail closed - deny request on error
+	}
+	return result.Allowed
+}
+
+// RateLimitWithDetail admits the request if fewer than limit requests were logged during the last window.
+// The reset time is the time until the oldest logged request leaves the window and frees a slot.
+func (r *SlidingWindowLogRateLimitRepository) RateLimitWithDetail(userId string, limit int, window time.Duration) (*domain.RateLimitResult, error) {
+	compound, err := r.RateLimitAllWithDetail(userId, []domain.Rule{{Limit: limit, Window: window}}, 1)
+	if err != nil {
+		return nil, err
+	}
+	return compound.Binding(), nil
+}
+
+// RateLimitAllWithDetail logs cost entries against every rule, only if each of them has room for all of them
+func (r *SlidingWindowLogRateLimitRepository) RateLimitAllWithDetail(userId string, rules []domain.Rule, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	keys := ruleKeys("sliding_log", userId, rules)
+
+	args := []interface{}{cost}
+	for _, rule := range rules {
+		args = append(args, rule.Limit, rule.Window.Microseconds())
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Checking sliding window log rate limit")
+
+	values, err := slidingWindowLogScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute sliding window log script")
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, fmt.Errorf("failed to check rate limit: empty script reply")
+	}
+
+	// The time the request was logged at names its entries in a refund
+	loggedAt := values[len(values)-1]
+	results, err := ruleResults(values[:len(values)-1], rules, time.Microsecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	for i := range results {
+		results[i].WindowID = loggedAt
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
+
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Bool("allowed", compound.Allowed).Msg("Sliding window log check result")
+
+	return compound, nil
+}
+
+// Peek reports the requests logged during the last window of the rule without logging one
+func (r *SlidingWindowLogRateLimitRepository) Peek(userId string, rule domain.Rule) (*domain.RateLimitResult, error) {
+	ctx := context.Background()
+	key := ruleKeys("sliding_log", userId, []domain.Rule{rule})[0]
+
+	r.logger.Debug().Str("user_id", userId).Int("limit", rule.Limit).Dur("window", rule.Window).Str("key", key).Msg("Peeking sliding window log rate limit")
+
+	values, err := slidingWindowLogPeekScript.Run(ctx, r.redisClient, []string{key}, rule.Limit, rule.Window.Microseconds()).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute sliding window log peek script")
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, []domain.Rule{rule}, time.Microsecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("remaining", results[0].Remaining).Dur("reset_after", results[0].ResetAfter).Bool("allowed", results[0].Allowed).Msg("Sliding window log peek result")
+
+	return &results[0], nil
+}
+
+// Refund removes up to cost entries logged at the time the check reported as the window ID from the log of every rule,
+// unless they already left its window
+func (r *SlidingWindowLogRateLimitRepository) Refund(userId string, rules []domain.Rule, windowIDs []int64, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	if len(windowIDs) != len(rules) {
+		return nil, fmt.Errorf("failed to refund rate limit: %w", domain.ErrWindowsRequired)
+	}
+	keys := ruleKeys("sliding_log", userId, rules)
+
+	args := []interface{}{cost}
+	for i, rule := range rules {
+		args = append(args, rule.Limit, rule.Window.Microseconds(), windowIDs[i])
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Refunding sliding window log rate limit")
+
+	values, err := slidingWindowLogRefundScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute sliding window log refund script")
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, rules, time.Microsecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
+
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Msg("Sliding window log refund result")
+
+	return compound, nil
+}
+
+// Reset deletes the user's log of requests for the window so the next request finds it empty
+func (r *SlidingWindowLogRateLimitRepository) Reset(userId string, window time.Duration) error {
+	ctx := context.Background()
+	key := rateLimitKey("sliding_log", userId, window)
+
+	r.logger.Debug().Str("user_id", userId).Dur("window", window).Str("key", key).Msg("Resetting sliding window log rate limit")
+
+	if err := r.redisClient.Del(ctx, key).Err(); err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to delete sliding window log key")
+		return fmt.Errorf("failed to reset rate limit: %w", err)
+	}
+
+	return nil
+}
+
+// Adjust is not supported, since the log holds the time of every request rather than a count
+func (r *SlidingWindowLogRateLimitRepository) Adjust(userId string, window time.Duration, adjustment domain.Adjustment) (*domain.WindowState, error) {
+	return nil, fmt.Errorf("%w: %s", domain.ErrAdjustUnsupported, domain.AlgorithmSlidingWindowLog)
+}
@@ -0,0 +1,36 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+func TestSlidingWindowLogRateLimitRepository(t *testing.T) {
+	redis := newTestRedis(t)
+	repository := NewSlidingWindowLogRateLimitRepository(logger.NewWithLevel("disabled"), redis.client)
+
+	second := domain.Rule{Limit: 3, Window: time.Second}
+	minute := domain.Rule{Limit: 5, Window: time.Minute}
+	start := redis.now.UnixMicro()
+
+	runRateLimitSteps(t, redis, repository, []rateLimitStep{
+		{name: "admits into an empty log", call: check([]domain.Rule{second}, 1), allowed: true, remaining: []int{2}},
+		{name: "logs the cost in entries", call: check([]domain.Rule{second}, 2), allowed: true, remaining: []int{0}},
+		{name: "denies until the oldest entry leaves", call: check([]domain.Rule{second}, 1), remaining: []int{0}, retryAfter: time.Second},
+		{name: "peek reports the full log", call: peek(second), remaining: []int{0}, retryAfter: time.Second},
+		{name: "denies every rule when one is full", advance: 500 * time.Millisecond, call: check([]domain.Rule{second, minute}, 1), remaining: []int{0, 5}, retryAfter: 500 * time.Millisecond},
+		{name: "logs nothing for the other rule", call: peek(minute), allowed: true, remaining: []int{5}},
+		{name: "refund removes logged entries", call: refund([]domain.Rule{second}, []int64{start}, 1), allowed: true, remaining: []int{1}},
+		{name: "logs against every rule", call: check([]domain.Rule{second, minute}, 1), allowed: true, remaining: []int{0, 4}},
+		{name: "entries slide out of the window", advance: 500 * time.Millisecond, call: peek(second), allowed: true, remaining: []int{2}},
+		{name: "peek logs nothing", call: check([]domain.Rule{second}, 2), allowed: true, remaining: []int{0}},
+		{name: "logs once the entries left the window", advance: time.Second, call: check([]domain.Rule{second}, 1), allowed: true, remaining: []int{2}},
+		{name: "refund of entries that left the window removes nothing", call: refund([]domain.Rule{second}, []int64{start + 1000000}, 2), allowed: true, remaining: []int{2}},
+		{name: "refund removes the entries of the request", call: refund([]domain.Rule{second}, []int64{start + 2000000}, 1), allowed: true, remaining: []int{3}},
+		{name: "logs the whole limit", call: check([]domain.Rule{second}, 3), allowed: true, remaining: []int{0}},
+		{name: "reset empties the log", call: reset(second), allowed: true, remaining: []int{3}},
+	})
+}
@@ -0,0 +1,16 @@
+package infrastructure
+
+import "time"
+
+// SystemClock implements the Clock interface using the local system time
+type SystemClock struct{}
+
+// NewSystemClock creates a new clock reading the local system time
+func NewSystemClock() *SystemClock {
+	return &SystemClock{}
+}
+
+// Now returns the current local system time
+func (SystemClock) Now() time.Time {
+	return time.Now()
+}
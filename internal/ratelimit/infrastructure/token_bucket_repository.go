@@ -0,0 +1,301 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/config"
+	"github.com/go-clean/platform/logger"
+)
+
+// tokenBucketScript refills and consumes one token bucket per rule atomically.
+// Each bucket is stored as a hash holding the current token count and the last refill timestamp,
+// and Redis server time is used so that all instances share the same clock.
+// Tokens are only taken when every bucket holds enough of them.
+// KEYS[i] - bucket key of rule i
+// ARGV[1] - cost of the request in tokens
+// ARGV[2i], ARGV[2i+1] - capacity and refill rate in tokens per millisecond of rule i
+// Returns {allowed, remaining tokens, milliseconds until the bucket is full, milliseconds until retry} per rule
+var tokenBucketScript = redis.NewScript(`
+local requested = tonumber(ARGV[1])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local buckets = {}
+local all_allowed = true
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[i * 2])
+	local rate = tonumber(ARGV[i * 2 + 1])
+
+	local state = redis.call('HMGET', key, 'tokens', 'timestamp')
+	local tokens = tonumber(state[1])
+	local timestamp = tonumber(state[2])
+	if tokens == nil or timestamp == nil then
+		tokens = capacity
+		timestamp = now
+	end
+
+	local elapsed = math.max(0, now - timestamp)
+	tokens = math.min(capacity, tokens + elapsed * rate)
+	if tokens < requested then
+		all_allowed = false
+	end
+	buckets[i] = {capacity = capacity, rate = rate, tokens = tokens}
+end
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local bucket = buckets[i]
+	local allowed = 0
+	local retry_after = 0
+	if bucket.tokens >= requested then
+		allowed = 1
+		if all_allowed then
+			bucket.tokens = bucket.tokens - requested
+		end
+	else
+		retry_after = math.ceil((requested - bucket.tokens) / bucket.rate)
+	end
+
+	local full_after = math.ceil((bucket.capacity - bucket.tokens) / bucket.rate)
+	redis.call('HSET', key, 'tokens', tostring(bucket.tokens), 'timestamp', now)
+	redis.call('PEXPIRE', key, math.max(full_after, 1))
+
+	table.insert(results, allowed)
+	table.insert(results, math.floor(bucket.tokens))
+	table.insert(results, full_after)
+	table.insert(results, retry_after)
+end
+
+return results
+`)
+
+// tokenBucketPeekScript refills a bucket in memory to report its current tokens without storing them.
+// KEYS[1] - bucket key
+// ARGV[1] - capacity
+// ARGV[2] - refill rate in tokens per millisecond
+// Returns {allowed, remaining tokens, milliseconds until the bucket is full, milliseconds until retry}
+var tokenBucketPeekScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'timestamp')
+local tokens = tonumber(state[1])
+local timestamp = tonumber(state[2])
+if tokens == nil or timestamp == nil then
+	tokens = capacity
+	timestamp = now
+end
+tokens = math.min(capacity, tokens + math.max(0, now - timestamp) * rate)
+
+local full_after = math.ceil((capacity - tokens) / rate)
+if tokens >= 1 then
+	return {1, math.floor(tokens), full_after, 0}
+end
+return {0, 0, full_after, math.ceil((1 - tokens) / rate)}
+`)
+
+// tokenBucketRefundScript puts tokens back into every rule's bucket, never beyond its capacity.
+// Buckets that expired are already full and are left alone.
+// KEYS[i] - bucket key of rule i
+// ARGV[1] - tokens to refund
+// ARGV[2i], ARGV[2i+1] - capacity and refill rate in tokens per millisecond of rule i
+// Returns {allowed, remaining tokens, milliseconds until the bucket is full, milliseconds until retry} per rule
+var tokenBucketRefundScript = redis.NewScript(`
+local refunded = tonumber(ARGV[1])
+
+local time = redis.call('TIME')
+local now = tonumber(time[1]) * 1000 + math.floor(tonumber(time[2]) / 1000)
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local capacity = tonumber(ARGV[i * 2])
+	local rate = tonumber(ARGV[i * 2 + 1])
+
+	local state = redis.call('HMGET', key, 'tokens', 'timestamp')
+	local tokens = tonumber(state[1])
+	local timestamp = tonumber(state[2])
+	if tokens == nil or timestamp == nil then
+		tokens = capacity
+	else
+		tokens = math.min(capacity, tokens + math.max(0, now - timestamp) * rate + refunded)
+		redis.call('HSET', key, 'tokens', tostring(tokens), 'timestamp', now)
+		redis.call('PEXPIRE', key, math.max(math.ceil((capacity - tokens) / rate), 1))
+	end
+
+	local full_after = math.ceil((capacity - tokens) / rate)
+	if tokens >= 1 then
+		table.insert(results, 1)
+		table.insert(results, math.floor(tokens))
+		table.insert(results, full_after)
+		table.insert(results, 0)
+	else
+		table.insert(results, 0)
+		table.insert(results, 0)
+		table.insert(results, full_after)
+		table.insert(results, math.ceil((1 - tokens) / rate))
+	end
+end
+
+return results
+`)
+
+// TokenBucketRateLimitRepository implements the RateLimitRepository interface using a Redis token bucket
+type TokenBucketRateLimitRepository struct {
+	logger      logger.Logger
+	redisClient *redis.Client
+	burst       int
+}
+
+// NewTokenBucketRateLimitRepository creates a new Redis-based token bucket rate limit repository
+func NewTokenBucketRateLimitRepository(
+	logger logger.Logger,
+	redisClient *redis.Client,
+	cfg *config.Config,
+) *TokenBucketRateLimitRepository {
+	return &TokenBucketRateLimitRepository{
+		logger:      logger,
+		redisClient: redisClient,
+		burst:       cfg.RateLimit.Burst,
+	}
+}
+
+// RateLimit checks if a user is allowed to make a request based on the rate limit
+func (r *TokenBucketRateLimitRepository) RateLimit(userId string, limit int, window time.Duration) bool {
+	result, err := r.RateLimitWithDetail(userId, limit, window)
+	if err != nil {
+		return false // Fail closed - deny request on error
+	}
+	return result.Allowed
+}
+
+// RateLimitWithDetail takes a token from the user's bucket, which refills at limit tokens per window
+// and holds at most the configured burst
+func (r *TokenBucketRateLimitRepository) RateLimitWithDetail(userId string, limit int, window time.Duration) (*domain.RateLimitResult, error) {
+	compound, err := r.RateLimitAllWithDetail(userId, []domain.Rule{{Limit: limit, Window: window}}, 1)
+	if err != nil {
+		return nil, err
+	}
+	return compound.Binding(), nil
+}
+
+// RateLimitAllWithDetail takes cost tokens from the user's bucket of every rule, only if all of them hold enough
+func (r *TokenBucketRateLimitRepository) RateLimitAllWithDetail(userId string, rules []domain.Rule, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	keys := ruleKeys("token_bucket", userId, rules)
+
+	args := []interface{}{cost}
+	for _, rule := range rules {
+		args = append(args, r.capacity(rule), float64(rule.Limit)/float64(rule.Window.Milliseconds()))
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Checking token bucket rate limit")
+
+	values, err := tokenBucketScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute token bucket script")
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, rules, time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	for i, rule := range rules {
+		results[i].Limit = r.capacity(rule)
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
+
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Bool("allowed", compound.Allowed).Msg("Token bucket check result")
+
+	return compound, nil
+}
+
+// capacity returns the bucket capacity for a rule, which is the rule's own burst, the configured burst or the rule's limit when neither is set
+func (r *TokenBucketRateLimitRepository) capacity(rule domain.Rule) int {
+	return rule.Capacity(domain.AlgorithmTokenBucket, r.burst)
+}
+
+// Peek reports the tokens currently in the bucket of the rule without taking one
+func (r *TokenBucketRateLimitRepository) Peek(userId string, rule domain.Rule) (*domain.RateLimitResult, error) {
+	ctx := context.Background()
+	key := ruleKeys("token_bucket", userId, []domain.Rule{rule})[0]
+
+	r.logger.Debug().Str("user_id", userId).Int("limit", rule.Limit).Dur("window", rule.Window).Int("burst", rule.Burst).Str("key", key).Msg("Peeking token bucket rate limit")
+
+	values, err := tokenBucketPeekScript.Run(ctx, r.redisClient, []string{key}, r.capacity(rule), float64(rule.Limit)/float64(rule.Window.Milliseconds())).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute token bucket peek script")
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, []domain.Rule{rule}, time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to peek rate limit: %w", err)
+	}
+	results[0].Limit = r.capacity(rule)
+
+	r.logger.Debug().Str("user_id", userId).Int("remaining", results[0].Remaining).Dur("reset_after", results[0].ResetAfter).Bool("allowed", results[0].Allowed).Msg("Token bucket peek result")
+
+	return &results[0], nil
+}
+
+// Refund puts cost tokens back into the bucket of every rule. Buckets have no windows, so the window IDs are ignored.
+func (r *TokenBucketRateLimitRepository) Refund(userId string, rules []domain.Rule, windowIDs []int64, cost int) (*domain.CompoundRateLimitResult, error) {
+	ctx := context.Background()
+	keys := ruleKeys("token_bucket", userId, rules)
+
+	args := []interface{}{cost}
+	for _, rule := range rules {
+		args = append(args, r.capacity(rule), float64(rule.Limit)/float64(rule.Window.Milliseconds()))
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Int("cost", cost).Msg("Refunding token bucket rate limit")
+
+	values, err := tokenBucketRefundScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute token bucket refund script")
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+
+	results, err := ruleResults(values, rules, time.Millisecond)
+	if err != nil {
+		return nil, fmt.Errorf("failed to refund rate limit: %w", err)
+	}
+	for i, rule := range rules {
+		results[i].Limit = r.capacity(rule)
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
+
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Dur("reset_after", compound.ResetAfter).Msg("Token bucket refund result")
+
+	return compound, nil
+}
+
+// Reset deletes the user's bucket for the window so the next request finds it full
+func (r *TokenBucketRateLimitRepository) Reset(userId string, window time.Duration) error {
+	ctx := context.Background()
+	key := rateLimitKey("token_bucket", userId, window)
+
+	r.logger.Debug().Str("user_id", userId).Dur("window", window).Str("key", key).Msg("Resetting token bucket rate limit")
+
+	if err := r.redisClient.Del(ctx, key).Err(); err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to delete token bucket key")
+		return fmt.Errorf("failed to reset rate limit: %w", err)
+	}
+
+	return nil
+}
+
+// Adjust is not supported, since a bucket holds tokens rather than a count of requests
+func (r *TokenBucketRateLimitRepository) Adjust(userId string, window time.Duration, adjustment domain.Adjustment) (*domain.WindowState, error) {
+	return nil, fmt.Errorf("%w: %s", domain.ErrAdjustUnsupported, domain.AlgorithmTokenBucket)
+}
@@ -0,0 +1,34 @@
+package infrastructure
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+)
+
+func TestTokenBucketRateLimitRepository(t *testing.T) {
+	redis := newTestRedis(t)
+	repository := &TokenBucketRateLimitRepository{logger: logger.NewWithLevel("disabled"), redisClient: redis.client}
+
+	// One token a second up to three, and a bucket of five refilled at 100 a minute
+	slow := domain.Rule{Limit: 10, Window: 10 * time.Second, Burst: 3}
+	fast := domain.Rule{Limit: 100, Window: time.Minute, Burst: 5}
+
+	runRateLimitSteps(t, redis, repository, []rateLimitStep{
+		{name: "admits from a full bucket", call: check([]domain.Rule{slow}, 1), allowed: true, remaining: []int{2}},
+		{name: "takes the cost in tokens", call: check([]domain.Rule{slow}, 2), allowed: true, remaining: []int{0}},
+		{name: "denies until a token refills", call: check([]domain.Rule{slow}, 1), remaining: []int{0}, retryAfter: time.Second},
+		{name: "peek reports the empty bucket", call: peek(slow), remaining: []int{0}, retryAfter: time.Second},
+		{name: "peek reports the refilled token", advance: time.Second, call: peek(slow), allowed: true, remaining: []int{1}},
+		{name: "peek takes nothing", call: peek(slow), allowed: true, remaining: []int{1}},
+		{name: "denies every rule when one lacks tokens", call: check([]domain.Rule{slow, fast}, 2), remaining: []int{1, 5}, retryAfter: time.Second},
+		{name: "takes nothing from the other rule", call: peek(fast), allowed: true, remaining: []int{5}},
+		{name: "takes from every rule", call: check([]domain.Rule{slow, fast}, 1), allowed: true, remaining: []int{0, 4}},
+		{name: "refund puts tokens back", call: refund([]domain.Rule{slow, fast}, nil, 1), allowed: true, remaining: []int{1, 5}},
+		{name: "refund stops at the capacity", call: refund([]domain.Rule{slow}, nil, 5), allowed: true, remaining: []int{3}},
+		{name: "takes the whole bucket", call: check([]domain.Rule{slow}, 3), allowed: true, remaining: []int{0}},
+		{name: "reset fills the bucket", call: reset(slow), allowed: true, remaining: []int{3}},
+	})
+}
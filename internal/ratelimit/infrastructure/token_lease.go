@@ -0,0 +1,156 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
+
+// fixedWindowLeaseScript counts a request against the fixed window of every rule, only if all of them have room for it,
+// and leases a share of the tokens left in each window it counts against on top, so they can be served locally.
+// Rules asking for no tokens are covered by an earlier lease, and are only reported.
+// KEYS[i] - counter key of rule i
+// ARGV[1] - share of the tokens left after the request to lease, between 0 and 1
+// ARGV[3i-1], ARGV[3i], ARGV[3i+1] - limit, milliseconds left in the window and tokens wanted of rule i
+// Returns {allowed, remaining, milliseconds until reset, milliseconds until retry, tokens granted} per rule
+var fixedWindowLeaseScript = redis.NewScript(`
+local fraction = tonumber(ARGV[1])
+
+local windows = {}
+local all_allowed = true
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 3 - 1])
+	local left = tonumber(ARGV[i * 3])
+	local wanted = tonumber(ARGV[i * 3 + 1])
+
+	local count = tonumber(redis.call('GET', key)) or 0
+	local ttl = redis.call('PTTL', key)
+	if ttl < 0 then
+		ttl = left
+	end
+
+	if count + wanted > limit then
+		all_allowed = false
+	end
+	windows[i] = {count = count, ttl = ttl}
+end
+
+local results = {}
+for i, key in ipairs(KEYS) do
+	local limit = tonumber(ARGV[i * 3 - 1])
+	local wanted = tonumber(ARGV[i * 3 + 1])
+	local state = windows[i]
+
+	local allowed = 0
+	local retry_after = state.ttl
+	local granted = 0
+	if state.count + wanted <= limit then
+		allowed = 1
+		retry_after = 0
+		if all_allowed and wanted > 0 then
+			granted = wanted + math.floor((limit - state.count - wanted) * fraction)
+			state.count = redis.call('INCRBY', key, granted)
+			if redis.call('PTTL', key) < 0 then
+				redis.call('PEXPIRE', key, state.ttl)
+			end
+		end
+	end
+
+	table.insert(results, allowed)
+	table.insert(results, math.max(limit - state.count, 0))
+	table.insert(results, state.ttl)
+	table.insert(results, retry_after)
+	table.insert(results, granted)
+end
+
+return results
+`)
+
+// fixedWindowReturnScript gives unused leased tokens back to the current window of every counter, never going below zero.
+// Expired counters are left alone, since their tokens went back when the window ended.
+// KEYS[i] - counter key i
+// ARGV[i] - unused tokens of counter i
+// Returns the number of counters tokens were given back to
+var fixedWindowReturnScript = redis.NewScript(`
+local returned = 0
+for i, key in ipairs(KEYS) do
+	if redis.call('PTTL', key) > 0 then
+		local count = tonumber(redis.call('GET', key)) or 0
+		redis.call('SET', key, math.max(count - tonumber(ARGV[i]), 0), 'KEEPTTL')
+		returned = returned + 1
+	end
+end
+
+return returned
+`)
+
+// LeaseAllWithDetail adds the tokens wanted to the window of every rule, only if all of them have that much room left,
+// and leases fraction of the tokens left in each of those windows on top. It returns the tokens granted per rule,
+// which include the wanted tokens.
+func (r *RedisRateLimitRepository) LeaseAllWithDetail(userId string, rules []domain.Rule, wanted []int, fraction float64) (*domain.CompoundRateLimitResult, []int, error) {
+	ctx := context.Background()
+	now := r.clock.Now()
+	starts := windowStarts(rules, now)
+	keys := windowKeys("rate_limit", userId, rules, starts)
+
+	args := []interface{}{strconv.FormatFloat(fraction, 'f', -1, 64)}
+	for i, rule := range rules {
+		args = append(args, rule.Limit, windowLeft(rule.Window, starts[i], now), wanted[i])
+	}
+
+	r.logger.Debug().Str("user_id", userId).Int("rules", len(rules)).Msg("Leasing rate limit tokens")
+
+	values, err := fixedWindowLeaseScript.Run(ctx, r.redisClient, keys, args...).Int64Slice()
+	if err != nil {
+		r.logger.Error().Str("user_id", userId).Err(err).Msg("Failed to execute fixed window lease script")
+		return nil, nil, fmt.Errorf("failed to lease rate limit tokens: %w", err)
+	}
+	if len(values) != len(rules)*5 {
+		return nil, nil, fmt.Errorf("failed to lease rate limit tokens: unexpected script reply length %d for %d rules", len(values), len(rules))
+	}
+
+	// Split the granted tokens off, leaving the reply every multi-rule script gives
+	granted := make([]int, len(rules))
+	reply := make([]int64, 0, len(rules)*4)
+	for i := range rules {
+		reply = append(reply, values[i*5:i*5+4]...)
+		granted[i] = int(values[i*5+4])
+	}
+
+	results, err := ruleResults(reply, rules, time.Millisecond)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to lease rate limit tokens: %w", err)
+	}
+	for i := range results {
+		results[i].WindowID = starts[i]
+	}
+	compound := domain.NewCompoundRateLimitResult(results)
+
+	r.logger.Debug().Str("user_id", userId).Int("binding_rule", compound.BindingIndex).Int("remaining", compound.Binding().Remaining).Bool("allowed", compound.Allowed).Msg("Rate limit tokens leased")
+
+	return compound, granted, nil
+}
+
+// ReturnLeased gives unused leased tokens back to the counters at keys, unless their window has ended
+func (r *RedisRateLimitRepository) ReturnLeased(keys []string, tokens []int64) error {
+	ctx := context.Background()
+
+	args := make([]interface{}, len(tokens))
+	for i, unused := range tokens {
+		args[i] = unused
+	}
+
+	returned, err := fixedWindowReturnScript.Run(ctx, r.redisClient, keys, args...).Int()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to execute fixed window return script")
+		return fmt.Errorf("failed to return leased tokens: %w", err)
+	}
+
+	r.logger.Debug().Int("counters", len(keys)).Int("returned", returned).Msg("Leased tokens returned")
+	return nil
+}
@@ -0,0 +1,61 @@
+package infrastructure
+
+import (
+	"sync"
+	"time"
+)
+
+// ttlCacheEntry holds a cached value until expiresAt
+type ttlCacheEntry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// ttlCache is an in-memory map whose entries expire after a fixed TTL. Expired entries are swept
+// at most once per TTL when a value is set, so keys that stop being read do not stay in memory.
+type ttlCache[V any] struct {
+	ttl       time.Duration
+	mu        sync.RWMutex
+	entries   map[string]ttlCacheEntry[V]
+	lastSweep time.Time
+}
+
+// newTTLCache creates an empty cache whose entries expire after ttl
+func newTTLCache[V any](ttl time.Duration) *ttlCache[V] {
+	return &ttlCache[V]{
+		ttl:       ttl,
+		entries:   make(map[string]ttlCacheEntry[V]),
+		lastSweep: time.Now(),
+	}
+}
+
+// get returns the value cached for the key, and false when it is missing or expired
+func (c *ttlCache[V]) get(key string) (V, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[key]
+	c.mu.RUnlock()
+	if !ok || !time.Now().Before(entry.expiresAt) {
+		var zero V
+		return zero, false
+	}
+	return entry.value, true
+}
+
+// set caches the value for the key for one TTL
+func (c *ttlCache[V]) set(key string, value V) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiresAt) {
+				delete(c.entries, k)
+			}
+		}
+		c.lastSweep = now
+	}
+
+	c.entries[key] = ttlCacheEntry[V]{value: value, expiresAt: now.Add(c.ttl)}
+}
@@ -0,0 +1,110 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
+
+// fixedWindowFlushScript adds requests counted elsewhere to the counter of the current window and makes sure it expires.
+// KEYS[1] - counter key
+// ARGV[1] - requests to add
+// ARGV[2] - milliseconds until the window resets, as last seen by the caller
+// Returns {count, milliseconds until reset}
+var fixedWindowFlushScript = redis.NewScript(`
+local count = redis.call('INCRBY', KEYS[1], ARGV[1])
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+	ttl = tonumber(ARGV[2])
+	if ttl <= 0 then
+		-- The window the requests were counted in has ended
+		redis.call('DEL', KEYS[1])
+		return {0, 0}
+	end
+	redis.call('PEXPIRE', KEYS[1], ttl)
+end
+
+return {count, ttl}
+`)
+
+// FlushIncrements adds the increments to the counters at keys in a single pipeline, and returns the resulting state of
+// every counter. The state of a counter whose increment failed is nil, and the first failure is returned as the error.
+func (r *RedisRateLimitRepository) FlushIncrements(keys []string, increments []int64, resetAfter []time.Duration) ([]*domain.WindowState, error) {
+	ctx := context.Background()
+
+	r.logger.Debug().Int("counters", len(keys)).Msg("Flushing rate limit increments")
+
+	cmds := make([]*redis.Cmd, len(keys))
+	indexes := make([]int, len(keys))
+	for i := range indexes {
+		indexes[i] = i
+	}
+	err := r.flushPipeline(ctx, cmds, indexes, keys, increments, resetAfter)
+
+	// A pipeline cannot fall back from EVALSHA to EVAL, so when Redis lost the script it is loaded once and only the
+	// counters it never ran for are flushed again, which counts nothing twice
+	if missing := missingScript(cmds); len(missing) > 0 {
+		r.logger.Warn().Int("counters", len(missing)).Msg("Fixed window flush script missing, loading it")
+		if loadErr := fixedWindowFlushScript.Load(ctx, r.redisClient).Err(); loadErr != nil {
+			r.logger.Error().Err(loadErr).Msg("Failed to load fixed window flush script")
+			err = loadErr
+		} else {
+			r.flushPipeline(ctx, cmds, missing, keys, increments, resetAfter)
+			err = firstError(cmds)
+		}
+	}
+
+	states := make([]*domain.WindowState, len(keys))
+	for i, cmd := range cmds {
+		values, cmdErr := cmd.Int64Slice()
+		if cmdErr != nil || len(values) != 2 {
+			continue
+		}
+		states[i] = &domain.WindowState{
+			Count:      int(values[0]),
+			ResetAfter: time.Duration(values[1]) * time.Millisecond,
+		}
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("Failed to execute fixed window flush pipeline")
+		return states, fmt.Errorf("failed to flush rate limit increments: %w", err)
+	}
+
+	return states, nil
+}
+
+// flushPipeline runs the flush script for the counters at the indexes in a single pipeline, storing their commands
+// in cmds, and returns the first failure
+func (r *RedisRateLimitRepository) flushPipeline(ctx context.Context, cmds []*redis.Cmd, indexes []int, keys []string, increments []int64, resetAfter []time.Duration) error {
+	pipe := r.redisClient.Pipeline()
+	for _, i := range indexes {
+		cmds[i] = fixedWindowFlushScript.EvalSha(ctx, pipe, []string{keys[i]}, increments[i], resetAfter[i].Milliseconds())
+	}
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// missingScript returns the indexes of the commands that failed because Redis did not have the script
+func missingScript(cmds []*redis.Cmd) []int {
+	var missing []int
+	for i, cmd := range cmds {
+		if err := cmd.Err(); err != nil && redis.HasErrorPrefix(err, "NOSCRIPT") {
+			missing = append(missing, i)
+		}
+	}
+	return missing
+}
+
+// firstError returns the error of the first command that failed, if any
+func firstError(cmds []*redis.Cmd) error {
+	for _, cmd := range cmds {
+		if err := cmd.Err(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,29 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
+
+// AccessRuleRepository defines the interface for storing the allow and deny lists.
+// Every change is recorded in the audit trail together with the actor who made it.
+type AccessRuleRepository interface {
+	// Create adds the rule to its list and records who added it
+	// Returns domain.ErrAccessRuleExists if the list already holds the same match and value
+	Create(ctx context.Context, rule *domain.AccessRule, actor string) error
+	
+	// Decide returns the rule deciding the access of the subject, or nil when no rule matches
+	// The deny list wins over the allow list
+	Decide(ctx context.Context, subject domain.AccessSubject) (*domain.AccessRule, error)
+	
+	// List returns every rule ordered by ID
+	List(ctx context.Context) ([]domain.AccessRule, error)
+	
+	// Delete removes the rule with the given ID and records who removed it
+	// Returns domain.ErrAccessRuleNotFound if no rule has the ID
+	Delete(ctx context.Context, id int64, actor string) error
+	
+	// Audit returns the most recent changes to the lists, newest first
+	Audit(ctx context.Context, limit int) ([]domain.AccessAuditEntry, error)
+}
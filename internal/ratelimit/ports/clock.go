@@ -0,0 +1,10 @@
+package ports
+
+import "time"
+
+// Clock defines the interface for reading the current time, so that time-dependent behaviour such as
+// scheduled limits can be driven by a fake clock
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+}
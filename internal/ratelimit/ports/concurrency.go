@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
+
+// ConcurrencyLimitRepository defines the interface for limiting the requests a user has in flight at once
+type ConcurrencyLimitRepository interface {
+	// Acquire takes one of the user's limit slots for at most ttl, after which it is released automatically
+	// The lease is not acquired when the user already holds limit unexpired leases
+	Acquire(userId string, limit int, ttl time.Duration) (*domain.LeaseResult, error)
+	
+	// Release gives a lease back before it expires
+	// Returns false if the lease was not held, e.g. because it already expired
+	Release(userId string, leaseId string) (bool, error)
+}
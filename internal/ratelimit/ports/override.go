@@ -0,0 +1,24 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
+
+// OverrideRepository defines the interface for storing per-user rate limit overrides
+type OverrideRepository interface {
+	// Put stores the user's override, replacing any override the user already has
+	Put(ctx context.Context, override *domain.Override) error
+	
+	// Get returns the user's override, including an expired one
+	// Returns domain.ErrOverrideNotFound if the user has no override
+	Get(ctx context.Context, userId string) (*domain.Override, error)
+	
+	// List returns every stored override ordered by user ID, including expired ones
+	List(ctx context.Context) ([]domain.Override, error)
+	
+	// Delete removes the user's override
+	// Returns domain.ErrOverrideNotFound if the user has no override
+	Delete(ctx context.Context, userId string) error
+}
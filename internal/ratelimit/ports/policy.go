@@ -0,0 +1,46 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
+
+// PolicyRepository defines the interface for storing named rate limit policies
+type PolicyRepository interface {
+	// Create stores a new policy
+	// Returns domain.ErrPolicyExists if a policy with the same name is already stored
+	Create(ctx context.Context, policy *domain.Policy) error
+	
+	// Get returns the policy with the given name
+	// Returns domain.ErrPolicyNotFound if no such policy is stored
+	Get(ctx context.Context, name string) (*domain.Policy, error)
+	
+	// List returns every stored policy ordered by name
+	List(ctx context.Context) ([]domain.Policy, error)
+	
+	// Update overwrites the limit, window, algorithm, burst and mode of an existing policy
+	// Returns domain.ErrPolicyNotFound if no such policy is stored
+	Update(ctx context.Context, policy *domain.Policy) error
+	
+	// Delete removes the policy with the given name
+	// Returns domain.ErrPolicyNotFound if no such policy is stored
+	Delete(ctx context.Context, name string) error
+}
+
+// PolicyFileSource defines the interface for reading the policies declared in the policy file
+type PolicyFileSource interface {
+	// Current returns the last valid version of the policy file, or an empty set when none is configured
+	// The result is shared and must not be modified
+	Current() *domain.PolicySet
+}
+
+
+// ShadowLog defines the interface for recording the requests shadow policies would have denied
+type ShadowLog interface {
+	// Record notes that the policy would have denied a request of the subject
+	Record(ctx context.Context, policy string, subject string) error
+	
+	// Report returns the subjects the policy would have denied within the retention period, most recently denied first
+	Report(ctx context.Context, policy string, limit int) ([]domain.ShadowDenial, error)
+}
@@ -0,0 +1,25 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
+
+// QuotaRepository defines the interface for long-period quotas that reset on calendar boundaries
+type QuotaRepository interface {
+	// Consume counts cost units against the user's quota for the current period
+	// The request is only counted if at least cost units remain
+	Consume(userId string, quota domain.Quota, cost int) (*domain.QuotaResult, error)
+}
+
+// QuotaUsageStore defines the interface for durable storage of quota usage
+type QuotaUsageStore interface {
+	// LoadUsage returns the units the user used during the period starting at start, zero if none were recorded
+	LoadUsage(ctx context.Context, userId string, period domain.Period, start time.Time) (int, error)
+	
+	// SaveUsages records the usage of several users and periods at once
+	// Usage is never lowered, so saves arriving out of order cannot lose requests
+	SaveUsages(ctx context.Context, usages []domain.QuotaUsage) error
+}
@@ -1,14 +1,53 @@
 package ports
 
-import "time"
+import (
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
 
 // RateLimitRepository defines the interface for rate limit data access
 type RateLimitRepository interface {
-	// RateLimit checks if a user is allowed to make a request based on the rate limit
+	// RateLimit checks if a user is allowed to make a request based on the rate limit within the window
 	// Returns true if the request is allowed, false otherwise
-	RateLimit(userId string, limit int) bool
+	RateLimit(userId string, limit int, window time.Duration) bool
 	
 	// RateLimitWithDetail checks if a user is allowed to make a request and returns detailed information
-	// Returns current count, time until reset, and error if any
-	RateLimitWithDetail(userId string, limit int) (int, time.Duration, error)
-}
\ No newline at end of file
+	// Returns the check result including remaining requests and time until reset, and error if any
+	RateLimitWithDetail(userId string, limit int, window time.Duration) (*domain.RateLimitResult, error)
+	
+	// RateLimitAllWithDetail checks several rules for a user atomically, consuming cost units of each
+	// The request is only counted against the rules if every rule has at least cost units remaining
+	RateLimitAllWithDetail(userId string, rules []domain.Rule, cost int) (*domain.CompoundRateLimitResult, error)
+	
+	// Peek reports the user's current quota for the rule without consuming any of it, counted for the rule's own
+	// subject when it has one and with the rule's burst where the algorithm has one
+	// Allowed and RetryAfter describe whether a single request would be admitted now
+	Peek(userId string, rule domain.Rule) (*domain.RateLimitResult, error)
+	
+	// Refund gives cost units back to every rule for a request that was admitted but never served
+	// windowIDs names the window of every rule the request was counted in, as reported by the check, and is ignored
+	// by algorithms without windows. Units are only returned to that window, so nothing is returned once it has ended,
+	// and the usage never drops below zero
+	// Returns the quota of every rule after the refund, as Peek would report it
+	Refund(userId string, rules []domain.Rule, windowIDs []int64, cost int) (*domain.CompoundRateLimitResult, error)
+}
+
+// RateLimitAdminRepository defines the interface for administrative changes to a user's rate limit state
+type RateLimitAdminRepository interface {
+	// Reset clears the user's state for the window so the next request starts afresh
+	Reset(userId string, window time.Duration) error
+	
+	// Adjust overwrites the user's count and/or the time until the window resets
+	// Returns the state of the window after the adjustment, or domain.ErrAdjustUnsupported for algorithms keeping no count
+	Adjust(userId string, window time.Duration, adjustment domain.Adjustment) (*domain.WindowState, error)
+}
+
+// RateLimitRepositoryProvider resolves the repository implementing a rate limiting algorithm
+type RateLimitRepositoryProvider interface {
+	// Repository returns the repository for the given algorithm
+	Repository(algorithm domain.Algorithm) (RateLimitRepository, error)
+	
+	// AdminRepository returns the repository making administrative changes to the state of the given algorithm
+	AdminRepository(algorithm domain.Algorithm) (RateLimitAdminRepository, error)
+}
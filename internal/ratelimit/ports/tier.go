@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/go-clean/internal/ratelimit/domain"
+)
+
+// TierRepository defines the interface for storing the tier each user is assigned to
+type TierRepository interface {
+	// Assign assigns the user to the tier, replacing the user's current tier
+	// Returns domain.ErrUnknownTier if no policy is named after the tier
+	Assign(ctx context.Context, assignment *domain.TierAssignment) error
+	
+	// Get returns the user's tier assignment
+	// Returns domain.ErrTierNotAssigned if the user has no tier
+	Get(ctx context.Context, userId string) (*domain.TierAssignment, error)
+	
+	// Unassign removes the user's tier assignment
+	// Returns domain.ErrTierNotAssigned if the user has no tier
+	Unassign(ctx context.Context, userId string) error
+	
+	// Import assigns every user to their tier in a single transaction, so either all assignments are stored or none
+	// Returns domain.ErrUnknownTier if any tier has no policy named after it
+	Import(ctx context.Context, assignments []domain.TierAssignment) error
+}
@@ -0,0 +1,281 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/application/query"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// actorHeader names the administrator making a change to the access lists, for the audit trail
+const actorHeader = "X-Actor"
+
+// AccessHandler handles allow and deny list HTTP requests
+type AccessHandler struct {
+	logger        logger.Logger
+	createHandler *command.CreateAccessRuleCommandHandler
+	deleteHandler *command.DeleteAccessRuleCommandHandler
+	listHandler   *query.ListAccessRulesQueryHandler
+	auditHandler  *query.ListAccessAuditQueryHandler
+}
+
+// NewAccessHandler creates a new access list handler
+func NewAccessHandler(
+	logger logger.Logger,
+	createHandler *command.CreateAccessRuleCommandHandler,
+	deleteHandler *command.DeleteAccessRuleCommandHandler,
+	listHandler *query.ListAccessRulesQueryHandler,
+	auditHandler *query.ListAccessAuditQueryHandler,
+) *AccessHandler {
+	return &AccessHandler{
+		logger:        logger,
+		createHandler: createHandler,
+		deleteHandler: deleteHandler,
+		listHandler:   listHandler,
+		auditHandler:  auditHandler,
+	}
+}
+
+// CreateAccessRule handles POST /admin/access-rules requests
+// @Summary Add a rule to the allow or deny list
+// @Description Allowed subjects are never rate limited and denied subjects are always rejected, without reaching Redis. A user ID or API key is matched exactly or by prefix, an IP address by CIDR. The deny list wins over the allow list.
+// @Tags Access Lists
+// @Accept json
+// @Produce json
+// @Param X-Actor header string false "Administrator making the change, recorded in the audit trail"
+// @Param request body AccessRuleRequest true "Rule to add"
+// @Success 201 {object} AccessRuleResponse "Rule added"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 409 {object} map[string]string "Rule already on the list"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/access-rules [post]
+func (h *AccessHandler) CreateAccessRule(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/access-rules").Msg("Access rule create endpoint called")
+	ctx := c.Context()
+
+	var req AccessRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	rule := domain.AccessRule{List: domain.AccessList(req.List), Match: domain.AccessMatch(req.Match), Value: req.Value}
+	if err := rule.Validate(); err != nil {
+		h.logger.Error().Str("value", req.Value).Err(err).Msg("Invalid access rule request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	result, err := h.createHandler.Handle(ctx, command.CreateAccessRuleCommand{
+		List:   rule.List,
+		Match:  rule.Match,
+		Value:  rule.Value,
+		Reason: req.Reason,
+		Actor:  actor(c),
+	})
+	if errors.Is(err, domain.ErrAccessRuleExists) {
+		return c.Status(http.StatusConflict).JSON(fiber.Map{
+			"error": "Rule already on the list",
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("value", rule.Value).Msg("Failed to create access rule")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create access rule",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Int64("rule_id", result.ID).Str("list", string(result.List)).Str("value", result.Value).Msg("Access rule created")
+	return c.Status(http.StatusCreated).JSON(newAccessRuleResponse(result))
+}
+
+// ListAccessRules handles GET /admin/access-rules requests
+// @Summary List the allow and deny lists
+// @Description Returns every access rule ordered by ID
+// @Tags Access Lists
+// @Produce json
+// @Success 200 {array} AccessRuleResponse "Access rules"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/access-rules [get]
+func (h *AccessHandler) ListAccessRules(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/access-rules").Msg("Access rule list endpoint called")
+	ctx := c.Context()
+
+	rules, err := h.listHandler.Handle(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list access rules")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list access rules",
+			"details": err.Error(),
+		})
+	}
+
+	response := make([]AccessRuleResponse, len(rules))
+	for i := range rules {
+		response[i] = newAccessRuleResponse(&rules[i])
+	}
+	return c.JSON(response)
+}
+
+// DeleteAccessRule handles DELETE /admin/access-rules/{id} requests
+// @Summary Remove a rule from its list
+// @Description Removes the access rule, so matching subjects are rate limited as usual again
+// @Tags Access Lists
+// @Produce json
+// @Param id path int true "Access rule ID"
+// @Param X-Actor header string false "Administrator making the change, recorded in the audit trail"
+// @Success 204 "Rule removed"
+// @Failure 400 {object} map[string]string "Invalid ID"
+// @Failure 404 {object} map[string]string "Rule not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/access-rules/{id} [delete]
+func (h *AccessHandler) DeleteAccessRule(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/access-rules/:id").Msg("Access rule delete endpoint called")
+	ctx := c.Context()
+
+	id, err := strconv.ParseInt(c.Params("id"), 10, 64)
+	if err != nil {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "id must be an integer",
+		})
+	}
+
+	err = h.deleteHandler.Handle(ctx, command.DeleteAccessRuleCommand{ID: id, Actor: actor(c)})
+	if errors.Is(err, domain.ErrAccessRuleNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Access rule not found",
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Int64("rule_id", id).Msg("Failed to delete access rule")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete access rule",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Int64("rule_id", id).Msg("Access rule deleted")
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ListAccessAudit handles GET /admin/access-rules/audit requests
+// @Summary List recent changes to the access lists
+// @Description Returns who added or removed which rule and when, newest first
+// @Tags Access Lists
+// @Produce json
+// @Param limit query int false "Maximum number of entries, 100 by default and at most 1000"
+// @Success 200 {array} AccessAuditResponse "Audit entries"
+// @Failure 400 {object} map[string]string "Invalid limit"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/access-rules/audit [get]
+func (h *AccessHandler) ListAccessAudit(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/access-rules/audit").Msg("Access rule audit endpoint called")
+	ctx := c.Context()
+
+	limit := c.QueryInt("limit", query.DefaultAuditLimit)
+	if limit <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit must be greater than 0",
+		})
+	}
+
+	entries, err := h.auditHandler.Handle(ctx, query.ListAccessAuditQuery{Limit: limit})
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list access rule audit entries")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list access rule audit entries",
+			"details": err.Error(),
+		})
+	}
+
+	response := make([]AccessAuditResponse, len(entries))
+	for i, entry := range entries {
+		response[i] = AccessAuditResponse{
+			ID:     entry.ID,
+			RuleID: entry.RuleID,
+			Action: string(entry.Action),
+			List:   string(entry.List),
+			Match:  string(entry.Match),
+			Value:  entry.Value,
+			Reason: entry.Reason,
+			Actor:  entry.Actor,
+			At:     entry.At,
+		}
+	}
+	return c.JSON(response)
+}
+
+// RegisterRoutes registers access list routes
+func (h *AccessHandler) RegisterRoutes(router fiber.Router) {
+	h.logger.Info().Msg("Registering access list routes")
+	router.Get("/admin/access-rules", h.ListAccessRules)
+	router.Post("/admin/access-rules", h.CreateAccessRule)
+	router.Get("/admin/access-rules/audit", h.ListAccessAudit)
+	router.Delete("/admin/access-rules/:id", h.DeleteAccessRule)
+	h.logger.Debug().Str("route", "/admin/access-rules").Msg("Access list routes registered")
+}
+
+// actor returns who is making a change to the access lists, falling back to the client IP
+// when the request does not name an administrator
+func actor(c *fiber.Ctx) string {
+	if name := c.Get(actorHeader); name != "" {
+		return name
+	}
+	return c.IP()
+}
+
+// AccessRuleRequest represents the request body for adding an access rule
+type AccessRuleRequest struct {
+	List   string `json:"list" validate:"required,oneof=allow deny"`
+	Match  string `json:"match" validate:"required,oneof=id prefix cidr"`
+	Value  string `json:"value" validate:"required"`
+	Reason string `json:"reason"`
+}
+
+// AccessRuleResponse represents an access rule
+type AccessRuleResponse struct {
+	ID        int64     `json:"id"`
+	List      string    `json:"list"`
+	Match     string    `json:"match"`
+	Value     string    `json:"value"`
+	Reason    string    `json:"reason,omitempty"`
+	CreatedBy string    `json:"created_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newAccessRuleResponse converts a domain access rule into its response body
+func newAccessRuleResponse(rule *domain.AccessRule) AccessRuleResponse {
+	return AccessRuleResponse{
+		ID:        rule.ID,
+		List:      string(rule.List),
+		Match:     string(rule.Match),
+		Value:     rule.Value,
+		Reason:    rule.Reason,
+		CreatedBy: rule.CreatedBy,
+		CreatedAt: rule.CreatedAt,
+	}
+}
+
+// AccessAuditResponse represents a change made to the access lists
+type AccessAuditResponse struct {
+	ID     int64     `json:"id"`
+	RuleID int64     `json:"rule_id"`
+	Action string    `json:"action"`
+	List   string    `json:"list"`
+	Match  string    `json:"match"`
+	Value  string    `json:"value"`
+	Reason string    `json:"reason,omitempty"`
+	Actor  string    `json:"actor"`
+	At     time.Time `json:"at"`
+}
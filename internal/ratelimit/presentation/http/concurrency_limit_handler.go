@@ -0,0 +1,190 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ConcurrencyLimitHandler handles concurrency limit HTTP requests
+type ConcurrencyLimitHandler struct {
+	logger         logger.Logger
+	acquireHandler *command.AcquireLeaseCommandHandler
+	releaseHandler *command.ReleaseLeaseCommandHandler
+}
+
+// NewConcurrencyLimitHandler creates a new concurrency limit handler
+func NewConcurrencyLimitHandler(
+	logger logger.Logger,
+	acquireHandler *command.AcquireLeaseCommandHandler,
+	releaseHandler *command.ReleaseLeaseCommandHandler,
+) *ConcurrencyLimitHandler {
+	return &ConcurrencyLimitHandler{
+		logger:         logger,
+		acquireHandler: acquireHandler,
+		releaseHandler: releaseHandler,
+	}
+}
+
+// AcquireLease handles POST /concurrency/{user_id}/leases requests
+// @Summary Acquire a concurrency lease for a user
+// @Description Takes one of the user's concurrent request slots until it is released or expires
+// @Tags Concurrency Limit
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body LeaseRequest false "Lease request"
+// @Success 200 {object} LeaseResponse "Lease acquired"
+// @Success 429 {object} LeaseResponse "Concurrency limit reached"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /concurrency/{user_id}/leases [post]
+func (h *ConcurrencyLimitHandler) AcquireLease(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/concurrency/:user_id/leases").Msg("Acquire lease endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+	if userID == "" {
+		h.logger.Error().Msg("Missing user_id in path")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "user_id is required",
+		})
+	}
+
+	var req LeaseRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			h.logger.Error().Err(err).Msg("Failed to parse request body")
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	if req.Limit < 0 {
+		h.logger.Error().Int("limit", req.Limit).Msg("Invalid limit in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit must be greater than 0",
+		})
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		parsed, err := time.ParseDuration(req.TTL)
+		if err != nil || parsed < domain.MinWindow {
+			h.logger.Error().Str("ttl", req.TTL).Msg("Invalid ttl in request")
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "ttl must be a duration of at least 1ms, e.g. 30s or 5m",
+			})
+		}
+		ttl = parsed
+	}
+
+	result, err := h.acquireHandler.Handle(ctx, command.AcquireLeaseCommand{
+		UserID: userID,
+		Limit:  req.Limit,
+		TTL:    ttl,
+	})
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to acquire lease")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to acquire lease",
+			"details": err.Error(),
+		})
+	}
+
+	response := LeaseResponse{
+		Acquired:   result.Acquired,
+		LeaseID:    result.LeaseID,
+		UserID:     userID,
+		Limit:      result.Limit,
+		InFlight:   result.InFlight,
+		Remaining:  result.Remaining,
+		ExpiresIn:  result.ExpiresIn.Milliseconds(),
+		RetryAfter: result.RetryAfter.Milliseconds(),
+	}
+
+	statusCode := http.StatusOK
+	if !result.Acquired {
+		statusCode = http.StatusTooManyRequests
+		c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(int64(math.Ceil(result.RetryAfter.Seconds())), 10))
+		h.logger.Warn().Str("user_id", userID).Int("limit", result.Limit).Int("in_flight", result.InFlight).Msg("Concurrency limit reached")
+	} else {
+		h.logger.Info().Str("user_id", userID).Str("lease_id", result.LeaseID).Int("in_flight", result.InFlight).Msg("Lease acquired")
+	}
+
+	return c.Status(statusCode).JSON(response)
+}
+
+// ReleaseLease handles DELETE /concurrency/{user_id}/leases/{lease_id} requests
+// @Summary Release a concurrency lease
+// @Description Gives a lease back before it expires, freeing the slot for another request
+// @Tags Concurrency Limit
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param lease_id path string true "Lease ID"
+// @Success 204 "Lease released"
+// @Failure 404 {object} map[string]string "Lease not held"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /concurrency/{user_id}/leases/{lease_id} [delete]
+func (h *ConcurrencyLimitHandler) ReleaseLease(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/concurrency/:user_id/leases/:lease_id").Msg("Release lease endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+	leaseID := c.Params("lease_id")
+
+	released, err := h.releaseHandler.Handle(ctx, command.ReleaseLeaseCommand{
+		UserID:  userID,
+		LeaseID: leaseID,
+	})
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to release lease")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to release lease",
+			"details": err.Error(),
+		})
+	}
+
+	if !released {
+		h.logger.Warn().Str("user_id", userID).Str("lease_id", leaseID).Msg("Lease not held")
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "lease not found or already expired",
+		})
+	}
+
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RegisterRoutes registers concurrency limit routes
+func (h *ConcurrencyLimitHandler) RegisterRoutes(router fiber.Router) {
+	h.logger.Info().Msg("Registering concurrency limit routes")
+	router.Post("/concurrency/:user_id/leases", h.AcquireLease)
+	router.Delete("/concurrency/:user_id/leases/:lease_id", h.ReleaseLease)
+	h.logger.Debug().Str("route", "/concurrency/:user_id/leases").Msg("Concurrency limit routes registered")
+}
+
+// LeaseRequest represents the request body for acquiring a lease
+type LeaseRequest struct {
+	Limit int    `json:"limit" validate:"omitempty,min=1"`
+	TTL   string `json:"ttl"`
+}
+
+// LeaseResponse represents the response body for acquiring a lease
+type LeaseResponse struct {
+	Acquired   bool   `json:"acquired"`
+	LeaseID    string `json:"lease_id,omitempty"`
+	UserID     string `json:"user_id"`
+	Limit      int    `json:"limit"`
+	InFlight   int    `json:"in_flight"`
+	Remaining  int    `json:"remaining"`
+	ExpiresIn  int64  `json:"expires_in_ms"`
+	RetryAfter int64  `json:"retry_after_ms"`
+}
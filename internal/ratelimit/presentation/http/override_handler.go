@@ -0,0 +1,278 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/application/query"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/internal/ratelimit/ports"
+	"github.com/go-clean/platform/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// OverrideHandler handles per-user rate limit override HTTP requests
+type OverrideHandler struct {
+	logger        logger.Logger
+	putHandler    *command.PutOverrideCommandHandler
+	deleteHandler *command.DeleteOverrideCommandHandler
+	getHandler    *query.GetOverrideQueryHandler
+	listHandler   *query.ListOverridesQueryHandler
+	clock         ports.Clock
+}
+
+// NewOverrideHandler creates a new override handler
+func NewOverrideHandler(
+	logger logger.Logger,
+	putHandler *command.PutOverrideCommandHandler,
+	deleteHandler *command.DeleteOverrideCommandHandler,
+	getHandler *query.GetOverrideQueryHandler,
+	listHandler *query.ListOverridesQueryHandler,
+	clock ports.Clock,
+) *OverrideHandler {
+	return &OverrideHandler{
+		logger:        logger,
+		putHandler:    putHandler,
+		deleteHandler: deleteHandler,
+		getHandler:    getHandler,
+		listHandler:   listHandler,
+		clock:         clock,
+	}
+}
+
+// PutOverride handles PUT /admin/overrides/{user_id} requests
+// @Summary Set the rate limit override of a user
+// @Description Scales the user's requested limits by a multiplier or replaces them with a fixed limit, optionally until an expiry date
+// @Tags Rate Limit Overrides
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body OverrideRequest true "Override to set"
+// @Success 200 {object} OverrideResponse "Override set"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/overrides/{user_id} [put]
+func (h *OverrideHandler) PutOverride(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/overrides/:user_id").Msg("Override put endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+
+	var req OverrideRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	window, err := parseWindow(req.Window)
+	if err != nil {
+		h.logger.Error().Str("window", req.Window).Msg("Invalid window in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if req.Window != "" && req.Limit == 0 {
+		h.logger.Error().Str("user_id", userID).Msg("Window without limit in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "window only applies to a fixed limit",
+		})
+	}
+
+	override := domain.Override{
+		UserID:     userID,
+		Multiplier: req.Multiplier,
+		Limit:      req.Limit,
+		Window:     window,
+		ExpiresAt:  req.ExpiresAt,
+	}
+	if override.Limit > 0 && override.Window == 0 {
+		override.Window = domain.DefaultWindow
+	}
+	if err := override.Validate(); err != nil {
+		h.logger.Error().Str("user_id", userID).Err(err).Msg("Invalid override request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(h.clock.Now()) {
+		h.logger.Error().Str("user_id", userID).Msg("Expired override in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "expires_at must be in the future",
+		})
+	}
+
+	result, err := h.putHandler.Handle(ctx, command.PutOverrideCommand{
+		UserID:     userID,
+		Multiplier: req.Multiplier,
+		Limit:      req.Limit,
+		Window:     window,
+		Reason:     req.Reason,
+		ExpiresAt:  req.ExpiresAt,
+	})
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to set override")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to set override",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Str("user_id", userID).Str("reason", result.Reason).Msg("Override set")
+	return c.JSON(newOverrideResponse(result, h.clock.Now()))
+}
+
+// ListOverrides handles GET /admin/overrides requests
+// @Summary List rate limit overrides
+// @Description Returns every stored override ordered by user ID, including expired ones
+// @Tags Rate Limit Overrides
+// @Produce json
+// @Success 200 {object} OverrideListResponse "Stored overrides"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/overrides [get]
+func (h *OverrideHandler) ListOverrides(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/overrides").Msg("Override list endpoint called")
+	ctx := c.Context()
+
+	overrides, err := h.listHandler.Handle(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list overrides")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list overrides",
+			"details": err.Error(),
+		})
+	}
+
+	now := h.clock.Now()
+	response := OverrideListResponse{Overrides: make([]OverrideResponse, len(overrides))}
+	for i := range overrides {
+		response.Overrides[i] = newOverrideResponse(&overrides[i], now)
+	}
+	return c.JSON(response)
+}
+
+// GetOverride handles GET /admin/overrides/{user_id} requests
+// @Summary Get the rate limit override of a user
+// @Description Returns the user's override, including an expired one
+// @Tags Rate Limit Overrides
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} OverrideResponse "Override"
+// @Failure 404 {object} map[string]string "Override not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/overrides/{user_id} [get]
+func (h *OverrideHandler) GetOverride(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/overrides/:user_id").Msg("Override get endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+	override, err := h.getHandler.Handle(ctx, query.GetOverrideQuery{UserID: userID})
+	if errors.Is(err, domain.ErrOverrideNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Override not found",
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to get override")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to get override",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(newOverrideResponse(override, h.clock.Now()))
+}
+
+// DeleteOverride handles DELETE /admin/overrides/{user_id} requests
+// @Summary Delete the rate limit override of a user
+// @Description Removes the user's override so their requested limits apply again
+// @Tags Rate Limit Overrides
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 204 "Override deleted"
+// @Failure 404 {object} map[string]string "Override not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/overrides/{user_id} [delete]
+func (h *OverrideHandler) DeleteOverride(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/overrides/:user_id").Msg("Override delete endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+	err := h.deleteHandler.Handle(ctx, command.DeleteOverrideCommand{UserID: userID})
+	if errors.Is(err, domain.ErrOverrideNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Override not found",
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to delete override")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete override",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Str("user_id", userID).Msg("Override deleted")
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// RegisterRoutes registers rate limit override routes
+func (h *OverrideHandler) RegisterRoutes(router fiber.Router) {
+	h.logger.Info().Msg("Registering rate limit override routes")
+	router.Get("/admin/overrides", h.ListOverrides)
+	router.Get("/admin/overrides/:user_id", h.GetOverride)
+	router.Put("/admin/overrides/:user_id", h.PutOverride)
+	router.Delete("/admin/overrides/:user_id", h.DeleteOverride)
+	h.logger.Debug().Str("route", "/admin/overrides").Msg("Rate limit override routes registered")
+}
+
+// OverrideRequest represents the request body for setting a user's override
+type OverrideRequest struct {
+	Multiplier float64    `json:"multiplier" validate:"omitempty,gt=0"`
+	Limit      int        `json:"limit" validate:"omitempty,min=1"`
+	Window     string     `json:"window"`
+	Reason     string     `json:"reason"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// OverrideResponse represents a stored rate limit override
+type OverrideResponse struct {
+	UserID     string     `json:"user_id"`
+	Multiplier float64    `json:"multiplier,omitempty"`
+	Limit      int        `json:"limit,omitempty"`
+	Window     string     `json:"window,omitempty"`
+	Reason     string     `json:"reason"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	Active     bool       `json:"active"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// newOverrideResponse converts a domain override into its response body, telling whether it is active at now
+func newOverrideResponse(override *domain.Override, now time.Time) OverrideResponse {
+	response := OverrideResponse{
+		UserID:     override.UserID,
+		Multiplier: override.Multiplier,
+		Limit:      override.Limit,
+		Reason:     override.Reason,
+		ExpiresAt:  override.ExpiresAt,
+		Active:     override.Active(now),
+		CreatedAt:  override.CreatedAt,
+		UpdatedAt:  override.UpdatedAt,
+	}
+	if override.Limit > 0 {
+		response.Window = override.Window.String()
+	}
+	return response
+}
+
+// OverrideListResponse represents the response body for override listings
+type OverrideListResponse struct {
+	Overrides []OverrideResponse `json:"overrides"`
+}
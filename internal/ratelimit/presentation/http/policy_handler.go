@@ -0,0 +1,505 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/application/query"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// PolicyHandler handles rate limit policy administration HTTP requests
+type PolicyHandler struct {
+	logger        logger.Logger
+	createHandler *command.CreatePolicyCommandHandler
+	updateHandler *command.UpdatePolicyCommandHandler
+	deleteHandler *command.DeletePolicyCommandHandler
+	getHandler    *query.GetPolicyQueryHandler
+	listHandler   *query.ListPoliciesQueryHandler
+	shadowHandler *query.GetShadowReportQueryHandler
+}
+
+// NewPolicyHandler creates a new policy handler
+func NewPolicyHandler(
+	logger logger.Logger,
+	createHandler *command.CreatePolicyCommandHandler,
+	updateHandler *command.UpdatePolicyCommandHandler,
+	deleteHandler *command.DeletePolicyCommandHandler,
+	getHandler *query.GetPolicyQueryHandler,
+	listHandler *query.ListPoliciesQueryHandler,
+	shadowHandler *query.GetShadowReportQueryHandler,
+) *PolicyHandler {
+	return &PolicyHandler{
+		logger:        logger,
+		createHandler: createHandler,
+		updateHandler: updateHandler,
+		deleteHandler: deleteHandler,
+		getHandler:    getHandler,
+		listHandler:   listHandler,
+		shadowHandler: shadowHandler,
+	}
+}
+
+// CreatePolicy handles POST /admin/policies requests
+// @Summary Create a rate limit policy
+// @Description Stores a named policy that rate limit checks can reference instead of sending their own limit
+// @Tags Rate Limit Policies
+// @Accept json
+// @Produce json
+// @Param request body PolicyRequest true "Policy to create"
+// @Success 201 {object} PolicyResponse "Policy created"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 409 {object} map[string]string "Policy already exists"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/policies [post]
+func (h *PolicyHandler) CreatePolicy(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/policies").Msg("Policy create endpoint called")
+	ctx := c.Context()
+
+	var req PolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	policy, invalid := req.toPolicy(req.Name)
+	if invalid != nil {
+		h.logger.Error().Str("policy", req.Name).Msg("Invalid policy request")
+		return c.Status(http.StatusBadRequest).JSON(invalid)
+	}
+
+	result, err := h.createHandler.Handle(ctx, command.CreatePolicyCommand{
+		Name:      policy.Name,
+		Limit:     policy.Limit,
+		Window:    policy.Window,
+		Algorithm: policy.Algorithm,
+		Burst:     policy.Burst,
+		Mode:      policy.Mode,
+		Schedules: policy.Schedules,
+	})
+	if errors.Is(err, domain.ErrPolicyExists) {
+		return c.Status(http.StatusConflict).JSON(fiber.Map{
+			"error":   "Policy already exists",
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("policy", req.Name).Msg("Failed to create policy")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to create policy",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Str("policy", result.Name).Msg("Policy created")
+	return c.Status(http.StatusCreated).JSON(newPolicyResponse(result))
+}
+
+// ListPolicies handles GET /admin/policies requests
+// @Summary List rate limit policies
+// @Description Returns every stored policy ordered by name
+// @Tags Rate Limit Policies
+// @Produce json
+// @Success 200 {object} PolicyListResponse "Stored policies"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/policies [get]
+func (h *PolicyHandler) ListPolicies(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/policies").Msg("Policy list endpoint called")
+	ctx := c.Context()
+
+	policies, err := h.listHandler.Handle(ctx)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("Failed to list policies")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to list policies",
+			"details": err.Error(),
+		})
+	}
+
+	response := PolicyListResponse{Policies: make([]PolicyResponse, len(policies))}
+	for i := range policies {
+		response.Policies[i] = newPolicyResponse(&policies[i])
+	}
+	return c.JSON(response)
+}
+
+// GetPolicy handles GET /admin/policies/{name} requests
+// @Summary Get a rate limit policy
+// @Description Returns the policy with the given name
+// @Tags Rate Limit Policies
+// @Produce json
+// @Param name path string true "Policy name"
+// @Success 200 {object} PolicyResponse "Policy"
+// @Failure 404 {object} map[string]string "Policy not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/policies/{name} [get]
+func (h *PolicyHandler) GetPolicy(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/policies/:name").Msg("Policy get endpoint called")
+	ctx := c.Context()
+
+	name := c.Params("name")
+	policy, err := h.getHandler.Handle(ctx, query.GetPolicyQuery{Name: name})
+	if errors.Is(err, domain.ErrPolicyNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Policy not found",
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("policy", name).Msg("Failed to get policy")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to get policy",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(newPolicyResponse(policy))
+}
+
+// UpdatePolicy handles PUT /admin/policies/{name} requests
+// @Summary Replace a rate limit policy
+// @Description Replaces the limit, window, algorithm and burst of an existing policy. Changing the window starts its users on fresh counters.
+// @Tags Rate Limit Policies
+// @Accept json
+// @Produce json
+// @Param name path string true "Policy name"
+// @Param request body PolicyRequest true "New policy settings"
+// @Success 200 {object} PolicyResponse "Policy updated"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 404 {object} map[string]string "Policy not found"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/policies/{name} [put]
+func (h *PolicyHandler) UpdatePolicy(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/policies/:name").Msg("Policy update endpoint called")
+	ctx := c.Context()
+
+	name := c.Params("name")
+
+	var req PolicyRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	if req.Name != "" && req.Name != name {
+		h.logger.Error().Str("policy", name).Str("name", req.Name).Msg("Policy name mismatch")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "name in body does not match the path, policies cannot be renamed",
+		})
+	}
+
+	policy, invalid := req.toPolicy(name)
+	if invalid != nil {
+		h.logger.Error().Str("policy", name).Msg("Invalid policy request")
+		return c.Status(http.StatusBadRequest).JSON(invalid)
+	}
+
+	result, err := h.updateHandler.Handle(ctx, command.UpdatePolicyCommand{
+		Name:      policy.Name,
+		Limit:     policy.Limit,
+		Window:    policy.Window,
+		Algorithm: policy.Algorithm,
+		Burst:     policy.Burst,
+		Mode:      policy.Mode,
+		Schedules: policy.Schedules,
+	})
+	if errors.Is(err, domain.ErrPolicyNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Policy not found",
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("policy", name).Msg("Failed to update policy")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to update policy",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Str("policy", result.Name).Msg("Policy updated")
+	return c.JSON(newPolicyResponse(result))
+}
+
+// DeletePolicy handles DELETE /admin/policies/{name} requests
+// @Summary Delete a rate limit policy
+// @Description Removes the policy, after which checks referencing it are rejected
+// @Tags Rate Limit Policies
+// @Produce json
+// @Param name path string true "Policy name"
+// @Success 204 "Policy deleted"
+// @Failure 404 {object} map[string]string "Policy not found"
+// @Failure 409 {object} map[string]string "Policy is assigned to users as their tier"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/policies/{name} [delete]
+func (h *PolicyHandler) DeletePolicy(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/policies/:name").Msg("Policy delete endpoint called")
+	ctx := c.Context()
+
+	name := c.Params("name")
+	err := h.deleteHandler.Handle(ctx, command.DeletePolicyCommand{Name: name})
+	if errors.Is(err, domain.ErrPolicyNotFound) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "Policy not found",
+		})
+	}
+	if errors.Is(err, domain.ErrPolicyInUse) {
+		return c.Status(http.StatusConflict).JSON(fiber.Map{
+			"error":   "Policy is in use",
+			"details": "users are assigned to this policy as their tier, reassign them first",
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("policy", name).Msg("Failed to delete policy")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to delete policy",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Str("policy", name).Msg("Policy deleted")
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// GetShadowReport handles GET /admin/shadow-report requests
+// @Summary Report the subjects a shadow policy would have denied
+// @Description Lists the subjects whose requests exceeded a policy in shadow mode within `rate_limit.shadow_retention`, most recently denied first. Policies declared in the policy file are named like `route:GET /reports`.
+// @Tags Rate Limit Policies
+// @Produce json
+// @Param policy query string true "Policy name"
+// @Param limit query int false "Maximum number of subjects, 100 by default and at most 1000"
+// @Success 200 {object} ShadowReportResponse "Would-be denied subjects"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/shadow-report [get]
+func (h *PolicyHandler) GetShadowReport(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/shadow-report").Msg("Shadow report endpoint called")
+	ctx := c.Context()
+
+	policy := c.Query("policy")
+	if policy == "" {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "policy is required",
+		})
+	}
+
+	limit := c.QueryInt("limit", query.DefaultShadowReportLimit)
+	if limit <= 0 {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit must be greater than 0",
+		})
+	}
+
+	denials, err := h.shadowHandler.Handle(ctx, query.GetShadowReportQuery{Policy: policy, Limit: limit})
+	if err != nil {
+		h.logger.Error().Err(err).Str("policy", policy).Msg("Failed to load shadow report")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to load shadow report",
+			"details": err.Error(),
+		})
+	}
+
+	response := ShadowReportResponse{Policy: policy, Subjects: make([]ShadowDenialResponse, len(denials))}
+	for i, denial := range denials {
+		response.Subjects[i] = ShadowDenialResponse{
+			Subject:      denial.Subject,
+			Denials:      denial.Denials,
+			LastDeniedAt: denial.LastDeniedAt,
+		}
+	}
+	return c.JSON(response)
+}
+
+// RegisterRoutes registers rate limit policy routes
+func (h *PolicyHandler) RegisterRoutes(router fiber.Router) {
+	h.logger.Info().Msg("Registering rate limit policy routes")
+	router.Post("/admin/policies", h.CreatePolicy)
+	router.Get("/admin/policies", h.ListPolicies)
+	router.Get("/admin/policies/:name", h.GetPolicy)
+	router.Put("/admin/policies/:name", h.UpdatePolicy)
+	router.Delete("/admin/policies/:name", h.DeletePolicy)
+	router.Get("/admin/shadow-report", h.GetShadowReport)
+	h.logger.Debug().Str("route", "/admin/policies").Msg("Rate limit policy routes registered")
+}
+
+// PolicyRequest represents the request body for creating or replacing a policy
+type PolicyRequest struct {
+	Name      string `json:"name"`
+	Limit     int    `json:"limit" validate:"required,min=1"`
+	Window    string `json:"window"`
+	Algorithm string `json:"algorithm" validate:"omitempty,oneof=fixed_window token_bucket sliding_window_log sliding_window_counter gcra"`
+	Burst     int    `json:"burst" validate:"omitempty,min=0"`
+	Mode      string `json:"mode" validate:"omitempty,oneof=enforce shadow"`
+	// Time ranges replacing the limit, e.g. a higher limit at night. The first active one wins.
+	Schedules []ScheduleRequest `json:"schedules" validate:"omitempty,dive"`
+}
+
+// ScheduleRequest represents a recurring time range in which a policy enforces a different limit
+type ScheduleRequest struct {
+	Days     []string `json:"days"`
+	Start    string   `json:"start" validate:"required"`
+	End      string   `json:"end" validate:"required"`
+	TimeZone string   `json:"time_zone"`
+	Limit    int      `json:"limit" validate:"required,min=1"`
+	Window   string   `json:"window"`
+	Burst    int      `json:"burst" validate:"omitempty,min=0"`
+}
+
+// toPolicy parses and validates the request as the policy with the given name, filling in the default window, algorithm and mode.
+// When the request is invalid the body of the bad request response is returned instead.
+func (r PolicyRequest) toPolicy(name string) (*domain.Policy, fiber.Map) {
+	window, err := parseWindow(r.Window)
+	if err != nil {
+		return nil, fiber.Map{"error": err.Error()}
+	}
+	if window == 0 {
+		window = domain.DefaultWindow
+	}
+
+	algorithm, err := domain.ParseAlgorithm(r.Algorithm)
+	if err != nil {
+		return nil, fiber.Map{
+			"error":   "Invalid algorithm",
+			"details": err.Error(),
+		}
+	}
+
+	mode := domain.PolicyMode(r.Mode)
+	if mode == "" {
+		mode = domain.DefaultPolicyMode
+	}
+
+	var schedules []domain.Schedule
+	for i, req := range r.Schedules {
+		schedule, err := req.toSchedule()
+		if err != nil {
+			return nil, fiber.Map{
+				"error":   "Invalid schedule",
+				"details": fmt.Sprintf("schedule %d: %s", i+1, err),
+			}
+		}
+		schedules = append(schedules, schedule)
+	}
+
+	policy := &domain.Policy{
+		Name:      name,
+		Limit:     r.Limit,
+		Window:    window,
+		Algorithm: algorithm,
+		Burst:     r.Burst,
+		Mode:      mode,
+		Schedules: schedules,
+	}
+	if err := policy.Validate(); err != nil {
+		return nil, fiber.Map{"error": err.Error()}
+	}
+	return policy, nil
+}
+
+// toSchedule parses the request into a schedule
+func (r ScheduleRequest) toSchedule() (domain.Schedule, error) {
+	window, err := parseWindow(r.Window)
+	if err != nil {
+		return domain.Schedule{}, err
+	}
+
+	return domain.ScheduleSpec{
+		Days:     r.Days,
+		Start:    r.Start,
+		End:      r.End,
+		TimeZone: r.TimeZone,
+		Limit:    r.Limit,
+		Window:   window,
+		Burst:    r.Burst,
+	}.Parse()
+}
+
+// PolicyResponse represents a stored rate limit policy
+type PolicyResponse struct {
+	Name      string             `json:"name"`
+	Limit     int                `json:"limit"`
+	Window    string             `json:"window"`
+	Algorithm string             `json:"algorithm"`
+	Burst     int                `json:"burst"`
+	Mode      string             `json:"mode"`
+	Schedules []ScheduleResponse `json:"schedules,omitempty"`
+	CreatedAt time.Time          `json:"created_at"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+// newPolicyResponse converts a domain policy into its response body
+func newPolicyResponse(policy *domain.Policy) PolicyResponse {
+	return PolicyResponse{
+		Name:      policy.Name,
+		Limit:     policy.Limit,
+		Window:    policy.Window.String(),
+		Algorithm: string(policy.Algorithm),
+		Burst:     policy.Burst,
+		Mode:      string(policy.Mode),
+		Schedules: newScheduleResponses(policy.Schedules),
+		CreatedAt: policy.CreatedAt,
+		UpdatedAt: policy.UpdatedAt,
+	}
+}
+
+// ScheduleResponse represents a recurring time range of a policy
+type ScheduleResponse struct {
+	Days     []string `json:"days,omitempty"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	TimeZone string   `json:"time_zone"`
+	Limit    int      `json:"limit"`
+	Window   string   `json:"window,omitempty"`
+	Burst    int      `json:"burst,omitempty"`
+}
+
+// newScheduleResponses converts domain schedules into their response bodies
+func newScheduleResponses(schedules []domain.Schedule) []ScheduleResponse {
+	if len(schedules) == 0 {
+		return nil
+	}
+
+	responses := make([]ScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		spec := schedule.Spec()
+		responses[i] = ScheduleResponse{
+			Days:     spec.Days,
+			Start:    spec.Start,
+			End:      spec.End,
+			TimeZone: spec.TimeZone,
+			Limit:    spec.Limit,
+			Burst:    spec.Burst,
+		}
+		if spec.Window != 0 {
+			responses[i].Window = spec.Window.String()
+		}
+	}
+	return responses
+}
+
+// PolicyListResponse represents the response body for policy listings
+type PolicyListResponse struct {
+	Policies []PolicyResponse `json:"policies"`
+}
+
+// ShadowReportResponse represents the subjects a shadow policy would have denied
+type ShadowReportResponse struct {
+	Policy   string                 `json:"policy"`
+	Subjects []ShadowDenialResponse `json:"subjects"`
+}
+
+// ShadowDenialResponse represents the would-be denials of a single subject
+type ShadowDenialResponse struct {
+	Subject      string    `json:"subject"`
+	Denials      int64     `json:"denials"`
+	LastDeniedAt time.Time `json:"last_denied_at"`
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// QuotaHandler handles quota HTTP requests
+type QuotaHandler struct {
+	logger         logger.Logger
+	consumeHandler *command.ConsumeQuotaCommandHandler
+}
+
+// NewQuotaHandler creates a new quota handler
+func NewQuotaHandler(
+	logger logger.Logger,
+	consumeHandler *command.ConsumeQuotaCommandHandler,
+) *QuotaHandler {
+	return &QuotaHandler{
+		logger:         logger,
+		consumeHandler: consumeHandler,
+	}
+}
+
+// ConsumeQuota handles POST /quota requests
+// @Summary Check a calendar-aligned quota for a user
+// @Description Counts a request against a daily, weekly, monthly or yearly quota that resets on calendar boundaries
+// @Tags Quota
+// @Accept json
+// @Produce json
+// @Param request body QuotaRequest true "Quota check request"
+// @Success 200 {object} QuotaResponse "Quota check successful"
+// @Success 429 {object} QuotaResponse "Quota exhausted"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /quota [post]
+func (h *QuotaHandler) ConsumeQuota(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/quota").Msg("Quota check endpoint called")
+	ctx := c.Context()
+
+	var req QuotaRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	if req.UserID == "" {
+		h.logger.Error().Msg("Missing user_id in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "user_id is required",
+		})
+	}
+
+	if req.Limit <= 0 {
+		h.logger.Error().Int("limit", req.Limit).Msg("Invalid limit in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "limit must be greater than 0",
+		})
+	}
+
+	if req.Cost < 0 || req.Cost > req.Limit {
+		h.logger.Error().Int("cost", req.Cost).Int("limit", req.Limit).Msg("Invalid cost in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "cost must be greater than 0 and cannot exceed limit",
+		})
+	}
+
+	period, err := domain.ParsePeriod(req.Period)
+	if err != nil {
+		h.logger.Error().Str("period", req.Period).Msg("Invalid period in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid period",
+			"details": err.Error(),
+		})
+	}
+
+	if req.TimeZone != "" {
+		if _, err := time.LoadLocation(req.TimeZone); err != nil {
+			h.logger.Error().Str("time_zone", req.TimeZone).Msg("Invalid time_zone in request")
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid time_zone",
+				"details": "time_zone must be an IANA time zone, e.g. UTC or Europe/Berlin",
+			})
+		}
+	}
+
+	result, err := h.consumeHandler.Handle(ctx, command.ConsumeQuotaCommand{
+		UserID:   req.UserID,
+		Limit:    req.Limit,
+		Period:   period,
+		TimeZone: req.TimeZone,
+		Cost:     req.Cost,
+	})
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", req.UserID).Int("limit", req.Limit).Msg("Failed to check quota")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to check quota",
+			"details": err.Error(),
+		})
+	}
+
+	response := QuotaResponse{
+		Allowed:     result.Allowed,
+		UserID:      req.UserID,
+		Limit:       result.Limit,
+		Used:        result.Used,
+		Remaining:   result.Remaining,
+		Period:      string(result.Period),
+		PeriodStart: result.PeriodStart.Format(time.RFC3339),
+		PeriodEnd:   result.PeriodEnd.Format(time.RFC3339),
+		ResetTime:   int64(result.ResetTime.Seconds()),
+		RetryAfter:  result.RetryAfter.Milliseconds(),
+		Cost:        result.Cost,
+	}
+
+	statusCode := http.StatusOK
+	if !result.Allowed {
+		statusCode = http.StatusTooManyRequests
+		c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(int64(math.Ceil(result.RetryAfter.Seconds())), 10))
+		h.logger.Warn().Str("user_id", req.UserID).Int("limit", result.Limit).Int("used", result.Used).Msg("Quota exhausted")
+	} else {
+		h.logger.Info().Str("user_id", req.UserID).Int("limit", result.Limit).Int("remaining", result.Remaining).Msg("Quota check passed")
+	}
+
+	return c.Status(statusCode).JSON(response)
+}
+
+// RegisterRoutes registers quota routes
+func (h *QuotaHandler) RegisterRoutes(router fiber.Router) {
+	h.logger.Info().Msg("Registering quota routes")
+	router.Post("/quota", h.ConsumeQuota)
+	h.logger.Debug().Str("route", "/quota").Msg("Quota route registered")
+}
+
+// QuotaRequest represents the request body for quota checks
+type QuotaRequest struct {
+	UserID   string `json:"user_id" validate:"required"`
+	Limit    int    `json:"limit" validate:"required,min=1"`
+	Period   string `json:"period" validate:"omitempty,oneof=day week month year"`
+	TimeZone string `json:"time_zone"`
+	Cost     int    `json:"cost" validate:"omitempty,min=1"`
+}
+
+// QuotaResponse represents the response body for quota checks
+type QuotaResponse struct {
+	Allowed     bool   `json:"allowed"`
+	UserID      string `json:"user_id"`
+	Limit       int    `json:"limit"`
+	Used        int    `json:"used"`
+	Remaining   int    `json:"remaining"`
+	Period      string `json:"period"`
+	PeriodStart string `json:"period_start"`
+	PeriodEnd   string `json:"period_end"`
+	ResetTime   int64  `json:"reset_time_seconds"`
+	RetryAfter  int64  `json:"retry_after_ms"`
+	Cost        int    `json:"cost"`
+}
@@ -0,0 +1,285 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimitAdminHandler handles administrative rate limit HTTP requests
+type RateLimitAdminHandler struct {
+	logger        logger.Logger
+	resetHandler  *command.ResetRateLimitCommandHandler
+	adjustHandler *command.AdjustRateLimitCommandHandler
+}
+
+// NewRateLimitAdminHandler creates a new rate limit admin handler
+func NewRateLimitAdminHandler(
+	logger logger.Logger,
+	resetHandler *command.ResetRateLimitCommandHandler,
+	adjustHandler *command.AdjustRateLimitCommandHandler,
+) *RateLimitAdminHandler {
+	return &RateLimitAdminHandler{
+		logger:        logger,
+		resetHandler:  resetHandler,
+		adjustHandler: adjustHandler,
+	}
+}
+
+// ResetRateLimit handles DELETE /admin/rate-limit/{user_id} requests
+// @Summary Reset the rate limit of a user
+// @Description Clears the user's counter for the window so their next request starts a fresh window.
+// @Description Without a user in the path the counter of the descriptors alone is cleared.
+// @Tags Rate Limit Admin
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param window query string false "Window duration, e.g. 1s, 15m or 24h"
+// @Param algorithm query string false "Algorithm whose state is cleared, every algorithm when omitted"
+// @Param level query string false "Hierarchy level whose counter is cleared: tenant, user or endpoint"
+// @Param api_key query string false "API key the counter is kept by"
+// @Param ip query string false "IP address the counter is kept by"
+// @Param route query string false "Route the counter is kept by"
+// @Param method query string false "HTTP method the counter is kept by"
+// @Param tenant query string false "Tenant the counter is kept by"
+// @Success 204 "Rate limit reset"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/rate-limit/{user_id} [delete]
+func (h *RateLimitAdminHandler) ResetRateLimit(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/rate-limit/:user_id").Msg("Rate limit reset endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+	descriptors := make(domain.Descriptors)
+	for _, name := range []domain.Descriptor{domain.DescriptorAPIKey, domain.DescriptorIP, domain.DescriptorRoute, domain.DescriptorMethod, domain.DescriptorTenant} {
+		if value := c.Query(string(name)); value != "" {
+			descriptors[name] = value
+		}
+	}
+
+	window, err := parseWindow(c.Query("window"))
+	if err != nil {
+		h.logger.Error().Str("window", c.Query("window")).Msg("Invalid window in query")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// An unset algorithm stays empty, so that the state of every algorithm is cleared
+	var algorithm domain.Algorithm
+	if value := c.Query("algorithm"); value != "" {
+		algorithm, err = domain.ParseAlgorithm(value)
+		if err != nil {
+			h.logger.Error().Str("algorithm", value).Msg("Invalid algorithm in query")
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid algorithm",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	level := domain.Level(c.Query("level"))
+	if err := validateAdminSubject(userID, descriptors, level); err != nil {
+		h.logger.Error().Str("user_id", userID).Err(err).Msg("Invalid subject in query")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	err = h.resetHandler.Handle(ctx, command.ResetRateLimitCommand{
+		UserID:      userID,
+		Window:      window,
+		Algorithm:   algorithm,
+		Descriptors: descriptors,
+		Level:       level,
+	})
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to reset rate limit")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to reset rate limit",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Str("user_id", userID).Msg("Rate limit reset")
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// AdjustRateLimit handles PATCH /admin/rate-limit/{user_id} requests
+// @Summary Adjust the rate limit of a user
+// @Description Overwrites the user's request count and/or the time until their window resets
+// @Tags Rate Limit Admin
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body RateLimitAdjustRequest true "Rate limit adjustment"
+// @Success 200 {object} RateLimitAdjustResponse "Rate limit adjusted"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/rate-limit/{user_id} [patch]
+func (h *RateLimitAdminHandler) AdjustRateLimit(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/rate-limit/:user_id").Msg("Rate limit adjust endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+
+	var req RateLimitAdjustRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	window, err := parseWindow(req.Window)
+	if err != nil {
+		h.logger.Error().Str("window", req.Window).Msg("Invalid window in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	algorithm, err := domain.ParseAlgorithm(req.Algorithm)
+	if err != nil {
+		h.logger.Error().Str("algorithm", req.Algorithm).Msg("Invalid algorithm in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid algorithm",
+			"details": err.Error(),
+		})
+	}
+
+	descriptors := make(domain.Descriptors, len(req.Descriptors))
+	for name, value := range req.Descriptors {
+		descriptors[domain.Descriptor(name)] = value
+	}
+	level := domain.Level(req.Level)
+	if err := validateAdminSubject(userID, descriptors, level); err != nil {
+		h.logger.Error().Str("user_id", userID).Err(err).Msg("Invalid subject in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	if req.Count == nil && req.ResetAfter == "" {
+		h.logger.Error().Msg("Empty adjustment in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "count or reset_after is required",
+		})
+	}
+
+	if req.Count != nil && *req.Count < 0 {
+		h.logger.Error().Int("count", *req.Count).Msg("Invalid count in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "count cannot be negative",
+		})
+	}
+
+	var resetAfter time.Duration
+	if req.ResetAfter != "" {
+		parsed, err := time.ParseDuration(req.ResetAfter)
+		if err != nil || parsed < domain.MinWindow {
+			h.logger.Error().Str("reset_after", req.ResetAfter).Msg("Invalid reset_after in request")
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "reset_after must be a duration of at least 1ms, e.g. 1s, 15m or 24h",
+			})
+		}
+		resetAfter = parsed
+	}
+
+	result, err := h.adjustHandler.Handle(ctx, command.AdjustRateLimitCommand{
+		UserID:      userID,
+		Window:      window,
+		Count:       req.Count,
+		ResetAfter:  resetAfter,
+		Algorithm:   algorithm,
+		Descriptors: descriptors,
+		Level:       level,
+	})
+	if errors.Is(err, domain.ErrAdjustUnsupported) {
+		h.logger.Error().Err(err).Str("user_id", userID).Str("algorithm", string(algorithm)).Msg("Adjustment not supported by algorithm")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Adjustment not supported",
+			"details": err.Error(),
+		})
+	}
+	if errors.Is(err, domain.ErrResetAfterBeyondWindow) {
+		h.logger.Error().Err(err).Str("user_id", userID).Dur("reset_after", resetAfter).Msg("Reset after past the end of the window")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "reset_after cannot be past the end of the window",
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to adjust rate limit")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to adjust rate limit",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Str("user_id", userID).Int("count", result.Count).Msg("Rate limit adjusted")
+	return c.JSON(RateLimitAdjustResponse{
+		UserID:    userID,
+		Count:     result.Count,
+		ResetTime: int64(result.ResetTime.Seconds()),
+		Window:    result.Window.String(),
+	})
+}
+
+// RegisterRoutes registers rate limit admin routes
+func (h *RateLimitAdminHandler) RegisterRoutes(router fiber.Router) {
+	h.logger.Info().Msg("Registering rate limit admin routes")
+	router.Delete("/admin/rate-limit", h.ResetRateLimit)
+	router.Delete("/admin/rate-limit/:user_id", h.ResetRateLimit)
+	router.Patch("/admin/rate-limit", h.AdjustRateLimit)
+	router.Patch("/admin/rate-limit/:user_id", h.AdjustRateLimit)
+	h.logger.Debug().Str("route", "/admin/rate-limit/:user_id").Msg("Rate limit admin routes registered")
+}
+
+// validateAdminSubject checks that the user, descriptors and hierarchy level name the counter of a subject
+func validateAdminSubject(userID string, descriptors domain.Descriptors, level domain.Level) error {
+	if userID == "" && len(descriptors) == 0 {
+		return fmt.Errorf("user_id or descriptors is required")
+	}
+	if _, ok := descriptors[domain.DescriptorUser]; ok {
+		return fmt.Errorf("the user is given by the user_id path parameter, not as a descriptor")
+	}
+	if err := descriptors.Validate(); err != nil {
+		return err
+	}
+	if level == "" {
+		return nil
+	}
+	if !level.IsValid() {
+		return fmt.Errorf("unsupported level, expected tenant, user or endpoint")
+	}
+	_, err := level.Descriptors(userID, descriptors)
+	return err
+}
+
+// RateLimitAdjustRequest represents the request body for rate limit adjustments
+type RateLimitAdjustRequest struct {
+	Window     string `json:"window"`
+	Count      *int   `json:"count" validate:"omitempty,min=0"`
+	ResetAfter string `json:"reset_after"`
+	Algorithm  string `json:"algorithm" validate:"omitempty,oneof=fixed_window token_bucket sliding_window_log sliding_window_counter gcra"`
+	// Descriptors names the counter kept by further dimensions: api_key, ip, route, method and tenant
+	Descriptors map[string]string `json:"descriptors"`
+	// Level names the counter of a hierarchy level: tenant, user or endpoint
+	Level string `json:"level"`
+}
+
+// RateLimitAdjustResponse represents the response body for rate limit adjustments
+type RateLimitAdjustResponse struct {
+	UserID    string `json:"user_id"`
+	Count     int    `json:"count"`
+	ResetTime int64  `json:"reset_time_seconds"`
+	Window    string `json:"window"`
+}
@@ -1,9 +1,16 @@
 package http
 
 import (
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/application/query"
+	"github.com/go-clean/internal/ratelimit/domain"
 	"github.com/go-clean/platform/logger"
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,16 +19,22 @@ import (
 type RateLimitHandler struct {
 	logger         logger.Logger
 	commandHandler *command.CheckRateLimitWithDetailCommandHandler
+	statusHandler  *query.GetRateLimitStatusQueryHandler
+	refundHandler  *command.RefundRateLimitCommandHandler
 }
 
 // NewRateLimitHandler creates a new rate limit handler
 func NewRateLimitHandler(
 	logger logger.Logger,
 	commandHandler *command.CheckRateLimitWithDetailCommandHandler,
+	statusHandler *query.GetRateLimitStatusQueryHandler,
+	refundHandler *command.RefundRateLimitCommandHandler,
 ) *RateLimitHandler {
 	return &RateLimitHandler{
 		logger:         logger,
 		commandHandler: commandHandler,
+		statusHandler:  statusHandler,
+		refundHandler:  refundHandler,
 	}
 }
 
@@ -34,6 +47,7 @@ func NewRateLimitHandler(
 // @Param request body RateLimitRequest true "Rate limit check request"
 // @Success 200 {object} RateLimitResponse "Rate limit check successful"
 // @Success 429 {object} RateLimitResponse "Rate limit exceeded"
+// @Success 403 {object} RateLimitResponse "Subject is on the deny list"
 // @Failure 400 {object} map[string]string "Bad request"
 // @Failure 500 {object} map[string]string "Internal server error"
 // @Router /rate-limit [post]
@@ -52,28 +66,31 @@ func (h *RateLimitHandler) CheckRateLimit(c *fiber.Ctx) error {
 	}
 
 	// Validate request
-	if req.UserID == "" {
-		h.logger.Error().Msg("Missing user_id in request")
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "user_id is required",
-		})
-	}
-
-	if req.Limit <= 0 {
-		h.logger.Error().Int("limit", req.Limit).Msg("Invalid limit in request")
-		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
-			"error": "limit must be greater than 0",
-		})
+	params, invalid := req.validate()
+	if invalid != nil {
+		h.logger.Error().Str("user_id", req.UserID).Str("error", fmt.Sprint(invalid["error"])).Msg("Invalid rate limit request")
+		return c.Status(http.StatusBadRequest).JSON(invalid)
 	}
 
 	// Create command
 	cmd := command.CheckRateLimitWithDetailCommand{
-		UserID: req.UserID,
-		Limit:  req.Limit,
+		UserID:      params.userID,
+		Limit:       req.Limit,
+		Window:      params.window,
+		Rules:       params.rules,
+		Cost:        req.Cost,
+		Algorithm:   params.algorithm,
+		Policy:      req.Policy,
+		Descriptors: params.descriptors,
+		Hierarchy:   params.hierarchy,
 	}
 
 	// Execute command
 	result, err := h.commandHandler.Handle(ctx, cmd)
+	if invalid := policyError(err); invalid != nil {
+		h.logger.Error().Err(err).Str("user_id", req.UserID).Str("policy", req.Policy).Msg("Invalid rate limit policy")
+		return c.Status(http.StatusBadRequest).JSON(invalid)
+	}
 	if err != nil {
 		h.logger.Error().Err(err).Str("user_id", req.UserID).Int("limit", req.Limit).Msg("Failed to check rate limit")
 		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
@@ -84,43 +101,604 @@ func (h *RateLimitHandler) CheckRateLimit(c *fiber.Ctx) error {
 
 	// Create response
 	response := RateLimitResponse{
-		Allowed:   result.Allowed,
-		Remaining: result.Remaining,
-		ResetTime: int64(result.ResetTime.Seconds()),
-		UserID:    req.UserID,
-		Limit:     req.Limit,
+		Allowed:     result.Allowed,
+		Remaining:   result.Remaining,
+		ResetTime:   int64(result.ResetTime.Seconds()),
+		RetryAfter:  result.RetryAfter.Milliseconds(),
+		UserID:      params.userID,
+		Limit:       result.Limit,
+		Window:      result.Window.String(),
+		Cost:        result.Cost,
+		Algorithm:   string(result.Algorithm),
+		Policy:      result.Policy,
+		Overridden:  result.Overridden,
+		DeniedBy:    string(result.DeniedBy),
+		Access:      string(result.Access),
+		WouldDeny:   result.WouldDeny,
+		Approximate: result.Approximate,
+	}
+	if result.Algorithm.Windowed() && len(result.Results) > 0 {
+		response.WindowIDs = make([]int64, len(result.Results))
+		for i, ruleResult := range result.Results {
+			response.WindowIDs[i] = ruleResult.WindowID
+		}
+	}
+	if len(req.Limits) > 0 || len(req.Hierarchy) > 0 {
+		response.Limits = make([]LimitResult, len(result.Results))
+		for i, ruleResult := range result.Results {
+			response.Limits[i] = LimitResult{
+				Allowed:    ruleResult.Allowed,
+				Remaining:  ruleResult.Remaining,
+				ResetTime:  int64(ruleResult.ResetTime.Seconds()),
+				RetryAfter: ruleResult.RetryAfter.Milliseconds(),
+				Limit:      ruleResult.Limit,
+				Window:     ruleResult.Window.String(),
+				Level:      string(ruleResult.Level),
+				WindowID:   ruleResult.WindowID,
+			}
+		}
+		binding := result.Binding
+		response.BindingLimit = &binding
 	}
 
 	// Return appropriate HTTP status
 	statusCode := http.StatusOK
-	if !result.Allowed {
+	if result.Access == domain.AccessDeny {
+		// Waiting does not help a denied subject, so no Retry-After is sent
+		statusCode = http.StatusForbidden
+		h.logger.Warn().Str("user_id", req.UserID).Int64("rule_id", result.AccessRule).Msg("Rate limit check denied by access list")
+	} else if !result.Allowed {
 		statusCode = http.StatusTooManyRequests
-		h.logger.Warn().Str("user_id", req.UserID).Int("limit", req.Limit).Int("remaining", result.Remaining).Msg("Rate limit exceeded")
+		c.Set(fiber.HeaderRetryAfter, strconv.FormatInt(int64(math.Ceil(result.RetryAfter.Seconds())), 10))
+		h.logger.Warn().Str("user_id", req.UserID).Int("limit", result.Limit).Int("remaining", result.Remaining).Msg("Rate limit exceeded")
 	} else {
-		h.logger.Info().Str("user_id", req.UserID).Int("limit", req.Limit).Int("remaining", result.Remaining).Msg("Rate limit check passed")
+		h.logger.Info().Str("user_id", req.UserID).Int("limit", result.Limit).Int("remaining", result.Remaining).Msg("Rate limit check passed")
 	}
 
 	return c.Status(statusCode).JSON(response)
 }
 
+// GetRateLimitStatus handles GET /rate-limit/{user_id} requests
+// @Summary Get the current rate limit status of a user
+// @Description Reports the user's used and remaining quota without consuming any of it
+// @Tags Rate Limit
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param limit query int false "Maximum number of requests per window"
+// @Param window query string false "Window duration, e.g. 1s, 15m or 24h"
+// @Param algorithm query string false "Rate limiting algorithm"
+// @Param policy query string false "Name of a stored policy supplying the limit, window and algorithm instead"
+// @Param api_key query string false "API key the requests are counted by"
+// @Param ip query string false "IP address the requests are counted by"
+// @Param route query string false "Route the requests are counted by"
+// @Param method query string false "HTTP method the requests are counted by"
+// @Param tenant query string false "Tenant the requests are counted by"
+// @Success 200 {object} RateLimitStatusResponse "Current rate limit status"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /rate-limit/{user_id} [get]
+func (h *RateLimitHandler) GetRateLimitStatus(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/rate-limit/:user_id").Msg("Rate limit status endpoint called")
+
+	userID := c.Params("user_id")
+	if userID == "" {
+		h.logger.Error().Msg("Missing user_id in path")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "user_id is required",
+		})
+	}
+
+	descriptors := make(domain.Descriptors)
+	for _, name := range []domain.Descriptor{domain.DescriptorAPIKey, domain.DescriptorIP, domain.DescriptorRoute, domain.DescriptorMethod, domain.DescriptorTenant} {
+		if value := c.Query(string(name)); value != "" {
+			descriptors[name] = value
+		}
+	}
+	if err := descriptors.Validate(); err != nil {
+		h.logger.Error().Str("user_id", userID).Err(err).Msg("Invalid descriptors in query")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid descriptors",
+			"details": err.Error(),
+		})
+	}
+
+	var limit int
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			h.logger.Error().Str("limit", value).Msg("Invalid limit in query")
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error": "limit must be greater than 0",
+			})
+		}
+		limit = parsed
+	}
+
+	window, err := parseWindow(c.Query("window"))
+	if err != nil {
+		h.logger.Error().Str("window", c.Query("window")).Msg("Invalid window in query")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	// An unset algorithm stays empty, so the query can tell a request that chose no limits apart
+	var algorithm domain.Algorithm
+	if value := c.Query("algorithm"); value != "" {
+		algorithm, err = domain.ParseAlgorithm(value)
+		if err != nil {
+			h.logger.Error().Str("algorithm", value).Msg("Invalid algorithm in query")
+			return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+				"error":   "Invalid algorithm",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	// A policy supplies its own limit, window and algorithm
+	policy := c.Query("policy")
+	if policy != "" && (limit != 0 || window != 0 || algorithm != "") {
+		h.logger.Error().Str("policy", policy).Msg("Policy combined with own limit in query")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "policy cannot be combined with limit, window or algorithm",
+		})
+	}
+
+	return h.rateLimitStatus(c, query.GetRateLimitStatusQuery{
+		UserID:      userID,
+		Limit:       limit,
+		Window:      window,
+		Algorithm:   algorithm,
+		Policy:      policy,
+		Descriptors: descriptors,
+	})
+}
+
+// QueryRateLimitStatus handles POST /rate-limit/status requests
+// @Summary Get the current rate limit status of a request
+// @Description Reports the used and remaining quota of the limits a request would be checked against without consuming any of it.
+// @Description The request is described like a check, so several limits, descriptors and hierarchies are supported, and the limit
+// @Description constraining the next request the most is reported.
+// @Tags Rate Limit
+// @Accept json
+// @Produce json
+// @Param request body RateLimitRequest true "Limits of the request, whose cost is ignored"
+// @Success 200 {object} RateLimitStatusResponse "Current rate limit status"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /rate-limit/status [post]
+func (h *RateLimitHandler) QueryRateLimitStatus(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/rate-limit/status").Msg("Rate limit status query endpoint called")
+
+	var req RateLimitRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	params, invalid := req.validate()
+	if invalid != nil {
+		h.logger.Error().Str("user_id", req.UserID).Str("error", fmt.Sprint(invalid["error"])).Msg("Invalid rate limit status request")
+		return c.Status(http.StatusBadRequest).JSON(invalid)
+	}
+
+	return h.rateLimitStatus(c, query.GetRateLimitStatusQuery{
+		UserID:      params.userID,
+		Limit:       req.Limit,
+		Window:      params.window,
+		Rules:       params.rules,
+		Algorithm:   params.algorithm,
+		Policy:      req.Policy,
+		Descriptors: params.descriptors,
+		Hierarchy:   params.hierarchy,
+	})
+}
+
+// rateLimitStatus runs the status query and writes its response
+func (h *RateLimitHandler) rateLimitStatus(c *fiber.Ctx, statusQuery query.GetRateLimitStatusQuery) error {
+	result, err := h.statusHandler.Handle(c.Context(), statusQuery)
+	if invalid := policyError(err); invalid != nil {
+		h.logger.Error().Err(err).Str("user_id", statusQuery.UserID).Str("policy", statusQuery.Policy).Msg("Invalid rate limit policy")
+		return c.Status(http.StatusBadRequest).JSON(invalid)
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", statusQuery.UserID).Msg("Failed to get rate limit status")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to get rate limit status",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(RateLimitStatusResponse{
+		UserID:     statusQuery.UserID,
+		Limit:      result.Limit,
+		Used:       result.Used,
+		Remaining:  result.Remaining,
+		ResetTime:  int64(result.ResetTime.Seconds()),
+		RetryAfter: result.RetryAfter.Milliseconds(),
+		Allowed:    result.Allowed,
+		Window:     result.Window.String(),
+		Algorithm:  string(result.Algorithm),
+		Policy:     result.Policy,
+		Overridden: result.Overridden,
+		Level:      string(result.Level),
+	})
+}
+
+// RefundRateLimit handles POST /rate-limit/refund requests
+// @Summary Refund a request that was never served
+// @Description Gives the cost of an admitted request back to its limits, never below zero usage and only within the window it was counted in, named by the window_ids the check returned
+// @Tags Rate Limit
+// @Accept json
+// @Produce json
+// @Param request body RateLimitRequest true "Limits and cost of the request to refund"
+// @Success 200 {object} RateLimitRefundResponse "Rate limit refunded"
+// @Failure 400 {object} map[string]string "Bad request"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /rate-limit/refund [post]
+func (h *RateLimitHandler) RefundRateLimit(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/rate-limit/refund").Msg("Rate limit refund endpoint called")
+	ctx := c.Context()
+
+	var req RateLimitRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	params, invalid := req.validate()
+	if invalid != nil {
+		h.logger.Error().Str("user_id", req.UserID).Str("error", fmt.Sprint(invalid["error"])).Msg("Invalid rate limit refund request")
+		return c.Status(http.StatusBadRequest).JSON(invalid)
+	}
+
+	result, err := h.refundHandler.Handle(ctx, command.RefundRateLimitCommand{
+		UserID:      params.userID,
+		Limit:       req.Limit,
+		Window:      params.window,
+		Rules:       params.rules,
+		Cost:        req.Cost,
+		Algorithm:   params.algorithm,
+		Policy:      req.Policy,
+		Descriptors: params.descriptors,
+		Hierarchy:   params.hierarchy,
+		WindowIDs:   req.WindowIDs,
+	})
+	if invalid := policyError(err); invalid != nil {
+		h.logger.Error().Err(err).Str("user_id", req.UserID).Str("policy", req.Policy).Msg("Invalid rate limit policy")
+		return c.Status(http.StatusBadRequest).JSON(invalid)
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", req.UserID).Msg("Failed to refund rate limit")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to refund rate limit",
+			"details": err.Error(),
+		})
+	}
+
+	response := RateLimitRefundResponse{
+		UserID:    params.userID,
+		Refunded:  result.Cost,
+		Remaining: result.Remaining,
+		ResetTime: int64(result.ResetTime.Seconds()),
+		Limit:     result.Limit,
+		Window:    result.Window.String(),
+		Algorithm: string(result.Algorithm),
+		Policy:    result.Policy,
+		Access:    string(result.Access),
+	}
+	if len(req.Limits) > 0 || len(req.Hierarchy) > 0 {
+		response.Limits = make([]LimitResult, len(result.Results))
+		for i, ruleResult := range result.Results {
+			response.Limits[i] = LimitResult{
+				Allowed:    ruleResult.Allowed,
+				Remaining:  ruleResult.Remaining,
+				ResetTime:  int64(ruleResult.ResetTime.Seconds()),
+				RetryAfter: ruleResult.RetryAfter.Milliseconds(),
+				Limit:      ruleResult.Limit,
+				Window:     ruleResult.Window.String(),
+				Level:      string(ruleResult.Level),
+				WindowID:   ruleResult.WindowID,
+			}
+		}
+	}
+
+	h.logger.Info().Str("user_id", req.UserID).Int("refunded", result.Cost).Int("remaining", result.Remaining).Msg("Rate limit refunded")
+	return c.JSON(response)
+}
+
 // RegisterRoutes registers rate limit related routes
 func (h *RateLimitHandler) RegisterRoutes(router fiber.Router) {
 	h.logger.Info().Msg("Registering rate limit routes")
 	router.Post("/rate-limit", h.CheckRateLimit)
+	router.Post("/rate-limit/refund", h.RefundRateLimit)
+	router.Post("/rate-limit/status", h.QueryRateLimitStatus)
+	router.Get("/rate-limit/:user_id", h.GetRateLimitStatus)
 	h.logger.Debug().Str("route", "/rate-limit").Msg("Rate limit route registered")
 }
 
 // RateLimitRequest represents the request body for rate limit check
 type RateLimitRequest struct {
-	UserID string `json:"user_id" validate:"required"`
-	Limit  int    `json:"limit" validate:"required,min=1"`
+	UserID    string         `json:"user_id" validate:"required"`
+	Limit     int            `json:"limit" validate:"omitempty,min=1"`
+	Window    string         `json:"window"`
+	Limits    []LimitRequest `json:"limits" validate:"omitempty,dive"`
+	Cost      int            `json:"cost" validate:"omitempty,min=1"`
+	Algorithm string         `json:"algorithm" validate:"omitempty,oneof=fixed_window token_bucket sliding_window_log sliding_window_counter gcra"`
+	Policy    string         `json:"policy"`
+	Route     string         `json:"route"`
+	Tenant    string         `json:"tenant"`
+	// Descriptors counts the request by further dimensions: api_key, ip, route, method and tenant, and user in place of user_id
+	Descriptors map[string]string `json:"descriptors"`
+	// Hierarchy limits the request at the tenant, user and endpoint levels at once, instead of its own limits
+	Hierarchy map[string]LimitRequest `json:"hierarchy"`
+	// WindowIDs names the window of every limit a refunded request was counted in, as returned by the check
+	WindowIDs []int64 `json:"window_ids"`
 }
 
 // RateLimitResponse represents the response body for rate limit check
 type RateLimitResponse struct {
-	Allowed   bool   `json:"allowed"`
-	Remaining int    `json:"remaining"`
-	ResetTime int64  `json:"reset_time_seconds"`
-	UserID    string `json:"user_id"`
-	Limit     int    `json:"limit"`
-}
\ No newline at end of file
+	Allowed      bool          `json:"allowed"`
+	Remaining    int           `json:"remaining"`
+	ResetTime    int64         `json:"reset_time_seconds"`
+	RetryAfter   int64         `json:"retry_after_ms"`
+	UserID       string        `json:"user_id"`
+	Limit        int           `json:"limit"`
+	Window       string        `json:"window"`
+	Cost         int           `json:"cost"`
+	Algorithm    string        `json:"algorithm"`
+	Policy       string        `json:"policy,omitempty"`
+	Overridden   bool          `json:"overridden,omitempty"`
+	DeniedBy     string        `json:"denied_by,omitempty"`
+	Access       string        `json:"access,omitempty"`
+	WouldDeny    bool          `json:"would_deny,omitempty"`
+	Approximate  bool          `json:"approximate,omitempty"`
+	Limits       []LimitResult `json:"limits,omitempty"`
+	BindingLimit *int          `json:"binding_limit,omitempty"`
+	WindowIDs    []int64       `json:"window_ids,omitempty"` // Window of every limit the request was counted in, for a refund
+}
+
+// rateLimitParams holds the parsed user, descriptors, window, limits, hierarchy and algorithm of a valid RateLimitRequest
+type rateLimitParams struct {
+	userID      string
+	descriptors domain.Descriptors
+	window      time.Duration
+	rules       []domain.Rule
+	hierarchy   map[domain.Level]domain.Rule
+	algorithm   domain.Algorithm
+}
+
+// validate checks the request and parses its user, descriptors, window, limits, hierarchy and algorithm.
+// When the request is invalid the body of the bad request response is returned instead.
+func (r RateLimitRequest) validate() (*rateLimitParams, fiber.Map) {
+	userID, descriptors, err := r.parseDescriptors()
+	if err != nil {
+		return nil, fiber.Map{
+			"error":   "Invalid descriptors",
+			"details": err.Error(),
+		}
+	}
+
+	if userID == "" && len(descriptors) == 0 {
+		return nil, fiber.Map{"error": "user_id or descriptors is required"}
+	}
+
+	if r.Limit < 0 {
+		return nil, fiber.Map{"error": "limit must be greater than 0"}
+	}
+
+	window, err := parseWindow(r.Window)
+	if err != nil {
+		return nil, fiber.Map{"error": err.Error()}
+	}
+
+	if r.Cost < 0 {
+		return nil, fiber.Map{"error": "cost must be greater than 0"}
+	}
+
+	if r.Cost > r.Limit && r.Limit > 0 {
+		return nil, fiber.Map{"error": "cost cannot exceed limit"}
+	}
+
+	if len(r.Limits) > 0 && (r.Limit != 0 || r.Window != "") {
+		return nil, fiber.Map{"error": "limit and window cannot be combined with limits"}
+	}
+
+	// A policy supplies its own limit, window and algorithm
+	if r.Policy != "" && (r.Limit != 0 || r.Window != "" || len(r.Limits) > 0 || r.Algorithm != "") {
+		return nil, fiber.Map{"error": "policy cannot be combined with limit, window, limits or algorithm"}
+	}
+
+	// Every level of a hierarchy brings its own limit
+	if len(r.Hierarchy) > 0 && (r.Limit != 0 || r.Window != "" || len(r.Limits) > 0 || r.Policy != "") {
+		return nil, fiber.Map{"error": "hierarchy cannot be combined with limit, window, limits or policy"}
+	}
+
+	hierarchy := make(map[domain.Level]domain.Rule, len(r.Hierarchy))
+	for name, limit := range r.Hierarchy {
+		level := domain.Level(name)
+		rule, err := limit.toRule()
+		if err == nil && !level.IsValid() {
+			err = fmt.Errorf("unsupported level, expected tenant, user or endpoint")
+		}
+		if err == nil && rule.Limit > 0 && r.Cost > rule.Limit {
+			err = fmt.Errorf("cost cannot exceed limit")
+		}
+		if err != nil {
+			return nil, fiber.Map{
+				"error":   "Invalid hierarchy",
+				"details": fmt.Sprintf("hierarchy.%s: %s", name, err.Error()),
+			}
+		}
+		hierarchy[level] = rule
+	}
+
+	rules := make([]domain.Rule, 0, len(r.Limits))
+	windows := make(map[time.Duration]bool, len(r.Limits))
+	for i, limit := range r.Limits {
+		rule, err := limit.toRule()
+		if err == nil && rule.Limit > 0 && r.Cost > rule.Limit {
+			err = fmt.Errorf("cost cannot exceed limit")
+		}
+		if err == nil && windows[rule.Window] {
+			err = fmt.Errorf("only one limit per window is allowed")
+		}
+		if err != nil {
+			return nil, fiber.Map{
+				"error":   "Invalid limits",
+				"details": fmt.Sprintf("limits[%d]: %s", i, err.Error()),
+			}
+		}
+		windows[rule.Window] = true
+		rules = append(rules, rule)
+	}
+
+	// An unset algorithm stays empty, so the command can tell a request that chose no limits apart
+	var algorithm domain.Algorithm
+	if r.Algorithm != "" {
+		algorithm, err = domain.ParseAlgorithm(r.Algorithm)
+		if err != nil {
+			return nil, fiber.Map{
+				"error":   "Invalid algorithm",
+				"details": err.Error(),
+			}
+		}
+	}
+
+	return &rateLimitParams{userID: userID, descriptors: descriptors, window: window, rules: rules, hierarchy: hierarchy, algorithm: algorithm}, nil
+}
+
+// parseDescriptors merges user_id, route and tenant into the descriptors of the request, rejecting a dimension given
+// twice with different values. The user is returned apart from the other descriptors.
+func (r RateLimitRequest) parseDescriptors() (string, domain.Descriptors, error) {
+	descriptors := make(domain.Descriptors, len(r.Descriptors)+2)
+	for name, value := range r.Descriptors {
+		descriptors[domain.Descriptor(name)] = value
+	}
+
+	fields := []struct {
+		name  domain.Descriptor
+		field string
+		value string
+	}{
+		{domain.DescriptorUser, "user_id", r.UserID},
+		{domain.DescriptorRoute, "route", r.Route},
+		{domain.DescriptorTenant, "tenant", r.Tenant},
+	}
+	for _, f := range fields {
+		if f.value == "" {
+			continue
+		}
+		if value, ok := descriptors[f.name]; ok && value != f.value {
+			return "", nil, fmt.Errorf("%s conflicts with descriptors.%s", f.field, f.name)
+		}
+		descriptors[f.name] = f.value
+	}
+
+	if err := descriptors.Validate(); err != nil {
+		return "", nil, err
+	}
+
+	userID := descriptors[domain.DescriptorUser]
+	delete(descriptors, domain.DescriptorUser)
+	return userID, descriptors, nil
+}
+
+// policyError returns the bad request body for a command error caused by the policy the request referenced,
+// by a cost its limits can never admit or by a refund naming no windows, or nil when the error has another cause
+func policyError(err error) fiber.Map {
+	switch {
+	case errors.Is(err, domain.ErrWindowsRequired):
+		return fiber.Map{"error": "window_ids are required", "details": err.Error()}
+	case errors.Is(err, domain.ErrCostExceedsCapacity):
+		return fiber.Map{"error": "cost exceeds capacity", "details": err.Error()}
+	case errors.Is(err, domain.ErrPolicyNotFound):
+		return fiber.Map{"error": "Unknown policy", "details": err.Error()}
+	case errors.Is(err, domain.ErrPolicyRequired):
+		return fiber.Map{"error": "policy is required", "details": "limits are managed by the server, reference a policy by name instead"}
+	}
+	return nil
+}
+
+// LimitRequest represents one of several limits checked together in a single request
+type LimitRequest struct {
+	Limit  int    `json:"limit" validate:"omitempty,min=1"`
+	Window string `json:"window"`
+}
+
+// toRule converts the limit into a domain rule, leaving a zero limit for the command to default
+func (l LimitRequest) toRule() (domain.Rule, error) {
+	if l.Limit < 0 {
+		return domain.Rule{}, fmt.Errorf("limit must be greater than 0")
+	}
+
+	window, err := parseWindow(l.Window)
+	if err != nil {
+		return domain.Rule{}, err
+	}
+	if window == 0 {
+		window = domain.DefaultWindow
+	}
+	return domain.Rule{Limit: l.Limit, Window: window}, nil
+}
+
+// parseWindow parses an optional window duration, returning zero when it is empty
+func parseWindow(value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window < domain.MinWindow {
+		return 0, fmt.Errorf("window must be a duration of at least 1ms, e.g. 1s, 15m or 24h")
+	}
+	return window, nil
+}
+
+// LimitResult represents the outcome of one limit within a compound rate limit check
+type LimitResult struct {
+	Allowed    bool   `json:"allowed"`
+	Remaining  int    `json:"remaining"`
+	ResetTime  int64  `json:"reset_time_seconds"`
+	RetryAfter int64  `json:"retry_after_ms"`
+	Limit      int    `json:"limit"`
+	Window     string `json:"window"`
+	Level      string `json:"level,omitempty"`
+	WindowID   int64  `json:"window_id,omitempty"`
+}
+
+// RateLimitStatusResponse represents the response body for rate limit status queries
+type RateLimitStatusResponse struct {
+	UserID     string `json:"user_id"`
+	Limit      int    `json:"limit"`
+	Used       int    `json:"used"`
+	Remaining  int    `json:"remaining"`
+	ResetTime  int64  `json:"reset_time_seconds"`
+	RetryAfter int64  `json:"retry_after_ms"`
+	Allowed    bool   `json:"allowed"`
+	Window     string `json:"window"`
+	Algorithm  string `json:"algorithm"`
+	Policy     string `json:"policy,omitempty"`
+	Overridden bool   `json:"overridden,omitempty"`
+	Level      string `json:"level,omitempty"` // Hierarchy level of the reported limit, if any
+}
+
+// RateLimitRefundResponse represents the response body for rate limit refunds
+type RateLimitRefundResponse struct {
+	UserID    string        `json:"user_id"`
+	Refunded  int           `json:"refunded"`
+	Remaining int           `json:"remaining"`
+	ResetTime int64         `json:"reset_time_seconds"`
+	Limit     int           `json:"limit"`
+	Window    string        `json:"window"`
+	Algorithm string        `json:"algorithm"`
+	Policy    string        `json:"policy,omitempty"`
+	Access    string        `json:"access,omitempty"`
+	Limits    []LimitResult `json:"limits,omitempty"`
+}
@@ -0,0 +1,298 @@
+package http
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/application/query"
+	"github.com/go-clean/internal/ratelimit/domain"
+	"github.com/go-clean/platform/logger"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxImportErrors caps the number of invalid rows reported for a rejected tier import
+const maxImportErrors = 20
+
+// TierHandler handles user tier assignment HTTP requests
+type TierHandler struct {
+	logger          logger.Logger
+	assignHandler   *command.AssignTierCommandHandler
+	unassignHandler *command.UnassignTierCommandHandler
+	importHandler   *command.ImportTiersCommandHandler
+	getHandler      *query.GetTierQueryHandler
+}
+
+// NewTierHandler creates a new tier handler
+func NewTierHandler(
+	logger logger.Logger,
+	assignHandler *command.AssignTierCommandHandler,
+	unassignHandler *command.UnassignTierCommandHandler,
+	importHandler *command.ImportTiersCommandHandler,
+	getHandler *query.GetTierQueryHandler,
+) *TierHandler {
+	return &TierHandler{
+		logger:          logger,
+		assignHandler:   assignHandler,
+		unassignHandler: unassignHandler,
+		importHandler:   importHandler,
+		getHandler:      getHandler,
+	}
+}
+
+// AssignTier handles PUT /admin/tiers/{user_id} requests
+// @Summary Assign a user to a tier
+// @Description Replaces the user's tier. Checks that carry only a user ID are limited by the policy named after the tier.
+// @Tags Rate Limit Tiers
+// @Accept json
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Param request body TierRequest true "Tier to assign"
+// @Success 200 {object} TierResponse "Tier assigned"
+// @Failure 400 {object} map[string]string "Bad request or unknown tier"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/tiers/{user_id} [put]
+func (h *TierHandler) AssignTier(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/tiers/:user_id").Msg("Tier assign endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+
+	var req TierRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.logger.Error().Err(err).Msg("Failed to parse request body")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
+
+	if req.Tier == "" {
+		h.logger.Error().Str("user_id", userID).Msg("Missing tier in request")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "tier is required",
+		})
+	}
+
+	result, err := h.assignHandler.Handle(ctx, command.AssignTierCommand{UserID: userID, Tier: req.Tier})
+	if errors.Is(err, domain.ErrUnknownTier) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Unknown tier",
+			"details": fmt.Sprintf("no policy is named %q", req.Tier),
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to assign tier")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to assign tier",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Str("user_id", userID).Str("tier", result.Tier).Msg("Tier assigned")
+	return c.JSON(newTierResponse(result))
+}
+
+// GetTier handles GET /admin/tiers/{user_id} requests
+// @Summary Get the tier of a user
+// @Description Returns the tier the user is assigned to. Users without one are limited by the default tier, if configured.
+// @Tags Rate Limit Tiers
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 200 {object} TierResponse "Tier assignment"
+// @Failure 404 {object} map[string]string "User has no tier"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/tiers/{user_id} [get]
+func (h *TierHandler) GetTier(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/tiers/:user_id").Msg("Tier get endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+	assignment, err := h.getHandler.Handle(ctx, query.GetTierQuery{UserID: userID})
+	if errors.Is(err, domain.ErrTierNotAssigned) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "User has no tier",
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to get tier")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to get tier",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(newTierResponse(assignment))
+}
+
+// UnassignTier handles DELETE /admin/tiers/{user_id} requests
+// @Summary Remove the tier of a user
+// @Description Removes the user's tier assignment so the default tier applies again
+// @Tags Rate Limit Tiers
+// @Produce json
+// @Param user_id path string true "User ID"
+// @Success 204 "Tier removed"
+// @Failure 404 {object} map[string]string "User has no tier"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/tiers/{user_id} [delete]
+func (h *TierHandler) UnassignTier(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/tiers/:user_id").Msg("Tier unassign endpoint called")
+	ctx := c.Context()
+
+	userID := c.Params("user_id")
+	err := h.unassignHandler.Handle(ctx, command.UnassignTierCommand{UserID: userID})
+	if errors.Is(err, domain.ErrTierNotAssigned) {
+		return c.Status(http.StatusNotFound).JSON(fiber.Map{
+			"error": "User has no tier",
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Str("user_id", userID).Msg("Failed to remove tier")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to remove tier",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Str("user_id", userID).Msg("Tier removed")
+	return c.SendStatus(http.StatusNoContent)
+}
+
+// ImportTiers handles POST /admin/tiers/import requests
+// @Summary Import tier assignments from CSV
+// @Description Assigns users to tiers from `user_id,tier` rows, with an optional header row. Either every row is imported or none is.
+// @Tags Rate Limit Tiers
+// @Accept text/csv
+// @Produce json
+// @Param request body string true "CSV rows of user_id,tier"
+// @Success 200 {object} TierImportResponse "Assignments imported"
+// @Failure 400 {object} map[string]interface{} "Invalid rows or unknown tier"
+// @Failure 500 {object} map[string]string "Internal server error"
+// @Router /admin/tiers/import [post]
+func (h *TierHandler) ImportTiers(c *fiber.Ctx) error {
+	h.logger.Info().Str("endpoint", "/admin/tiers/import").Msg("Tier import endpoint called")
+	ctx := c.Context()
+
+	assignments, rowErrors := parseTierCSV(c.Body())
+	if len(rowErrors) > 0 {
+		h.logger.Error().Int("invalid_rows", len(rowErrors)).Msg("Invalid tier import")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Invalid CSV",
+			"details": rowErrors,
+		})
+	}
+
+	if len(assignments) == 0 {
+		h.logger.Error().Msg("Empty tier import")
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error": "CSV contains no assignments",
+		})
+	}
+
+	result, err := h.importHandler.Handle(ctx, command.ImportTiersCommand{Assignments: assignments})
+	if errors.Is(err, domain.ErrUnknownTier) {
+		return c.Status(http.StatusBadRequest).JSON(fiber.Map{
+			"error":   "Unknown tier",
+			"details": err.Error(),
+		})
+	}
+	if err != nil {
+		h.logger.Error().Err(err).Int("assignments", len(assignments)).Msg("Failed to import tiers")
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{
+			"error":   "Failed to import tiers",
+			"details": err.Error(),
+		})
+	}
+
+	h.logger.Info().Int("imported", result.Imported).Msg("Tiers imported")
+	return c.JSON(TierImportResponse{Imported: result.Imported, Tiers: result.Tiers})
+}
+
+// RegisterRoutes registers user tier routes
+func (h *TierHandler) RegisterRoutes(router fiber.Router) {
+	h.logger.Info().Msg("Registering user tier routes")
+	router.Post("/admin/tiers/import", h.ImportTiers)
+	router.Get("/admin/tiers/:user_id", h.GetTier)
+	router.Put("/admin/tiers/:user_id", h.AssignTier)
+	router.Delete("/admin/tiers/:user_id", h.UnassignTier)
+	h.logger.Debug().Str("route", "/admin/tiers").Msg("User tier routes registered")
+}
+
+// parseTierCSV reads `user_id,tier` rows, skipping a leading header row and blank lines.
+// Every invalid or duplicate row is reported by line number, up to maxImportErrors.
+func parseTierCSV(body []byte) ([]domain.TierAssignment, []string) {
+	reader := csv.NewReader(bytes.NewReader(body))
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var assignments []domain.TierAssignment
+	var rowErrors []string
+	lines := make(map[string]int)
+	for len(rowErrors) < maxImportErrors {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) || parseErr.Err != csv.ErrFieldCount {
+				// The reader cannot resume after malformed quoting
+				rowErrors = append(rowErrors, err.Error())
+				break
+			}
+			rowErrors = append(rowErrors, fmt.Sprintf("line %d: expected user_id,tier", parseErr.Line))
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+		userID, tier := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if len(assignments) == 0 && len(rowErrors) == 0 && strings.EqualFold(userID, "user_id") && strings.EqualFold(tier, "tier") {
+			continue
+		}
+
+		switch {
+		case userID == "" || tier == "":
+			rowErrors = append(rowErrors, fmt.Sprintf("line %d: user_id and tier cannot be empty", line))
+		case lines[userID] > 0:
+			rowErrors = append(rowErrors, fmt.Sprintf("line %d: user %s is already assigned on line %d", line, userID, lines[userID]))
+		default:
+			lines[userID] = line
+			assignments = append(assignments, domain.TierAssignment{UserID: userID, Tier: tier})
+		}
+	}
+
+	return assignments, rowErrors
+}
+
+// TierRequest represents the request body for assigning a user to a tier
+type TierRequest struct {
+	Tier string `json:"tier" validate:"required"`
+}
+
+// TierResponse represents a user's tier assignment
+type TierResponse struct {
+	UserID     string    `json:"user_id"`
+	Tier       string    `json:"tier"`
+	AssignedAt time.Time `json:"assigned_at"`
+}
+
+// newTierResponse converts a domain tier assignment into its response body
+func newTierResponse(assignment *domain.TierAssignment) TierResponse {
+	return TierResponse{
+		UserID:     assignment.UserID,
+		Tier:       assignment.Tier,
+		AssignedAt: assignment.AssignedAt,
+	}
+}
+
+// TierImportResponse represents the outcome of a tier import
+type TierImportResponse struct {
+	Imported int            `json:"imported"`
+	Tiers    map[string]int `json:"tiers"`
+}
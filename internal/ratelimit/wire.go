@@ -5,12 +5,16 @@ package ratelimit
 
 import (
 	"github.com/google/wire"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/redis/go-redis/v9"
 	
 	"github.com/go-clean/internal/ratelimit/application/command"
+	"github.com/go-clean/internal/ratelimit/application/limits"
+	"github.com/go-clean/internal/ratelimit/application/query"
 	"github.com/go-clean/internal/ratelimit/infrastructure"
 	"github.com/go-clean/internal/ratelimit/ports"
 	"github.com/go-clean/internal/ratelimit/presentation/http"
+	"github.com/go-clean/platform/config"
 	"github.com/go-clean/platform/logger"
 )
 
@@ -19,13 +23,77 @@ var ProviderSet = wire.NewSet(
 	// Infrastructure providers
 	infrastructure.NewRedisRateLimitRepository,
 	wire.Bind(new(ports.RateLimitRepository), new(*infrastructure.RedisRateLimitRepository)),
+	wire.Bind(new(ports.RateLimitAdminRepository), new(*infrastructure.RedisRateLimitRepository)),
+	infrastructure.NewTokenBucketRateLimitRepository,
+	infrastructure.NewSlidingWindowLogRateLimitRepository,
+	infrastructure.NewSlidingWindowCounterRateLimitRepository,
+	infrastructure.NewGCRARateLimitRepository,
+	infrastructure.NewAlgorithmRepositoryProvider,
+	infrastructure.NewRedisConcurrencyLimitRepository,
+	wire.Bind(new(ports.ConcurrencyLimitRepository), new(*infrastructure.RedisConcurrencyLimitRepository)),
+	infrastructure.NewPostgresQuotaUsageStore,
+	wire.Bind(new(ports.QuotaUsageStore), new(*infrastructure.PostgresQuotaUsageStore)),
+	infrastructure.NewRedisQuotaRepository,
+	wire.Bind(new(ports.QuotaRepository), new(*infrastructure.RedisQuotaRepository)),
+	infrastructure.NewPostgresPolicyRepository,
+	infrastructure.NewCachedPolicyRepository,
+	wire.Bind(new(ports.PolicyRepository), new(*infrastructure.CachedPolicyRepository)),
+	infrastructure.NewFilePolicySource,
+	wire.Bind(new(ports.PolicyFileSource), new(*infrastructure.FilePolicySource)),
+	infrastructure.NewPostgresOverrideRepository,
+	infrastructure.NewCachedOverrideRepository,
+	wire.Bind(new(ports.OverrideRepository), new(*infrastructure.CachedOverrideRepository)),
+	infrastructure.NewPostgresTierRepository,
+	infrastructure.NewCachedTierRepository,
+	wire.Bind(new(ports.TierRepository), new(*infrastructure.CachedTierRepository)),
+	infrastructure.NewPostgresAccessRuleRepository,
+	infrastructure.NewCachedAccessRuleRepository,
+	wire.Bind(new(ports.AccessRuleRepository), new(*infrastructure.CachedAccessRuleRepository)),
+	infrastructure.NewRedisShadowLog,
+	wire.Bind(new(ports.ShadowLog), new(*infrastructure.RedisShadowLog)),
+	infrastructure.NewSystemClock,
+	wire.Bind(new(ports.Clock), new(*infrastructure.SystemClock)),
+	wire.Bind(new(ports.RateLimitRepositoryProvider), new(*infrastructure.AlgorithmRepositoryProvider)),
 	
 	// Application providers
+	limits.NewResolver,
 	command.NewCheckRateLimitCommandHandler,
 	command.NewCheckRateLimitWithDetailCommandHandler,
+	command.NewRefundRateLimitCommandHandler,
+	command.NewResetRateLimitCommandHandler,
+	command.NewAdjustRateLimitCommandHandler,
+	command.NewAcquireLeaseCommandHandler,
+	command.NewReleaseLeaseCommandHandler,
+	command.NewConsumeQuotaCommandHandler,
+	command.NewCreatePolicyCommandHandler,
+	command.NewUpdatePolicyCommandHandler,
+	command.NewDeletePolicyCommandHandler,
+	command.NewPutOverrideCommandHandler,
+	command.NewDeleteOverrideCommandHandler,
+	command.NewAssignTierCommandHandler,
+	command.NewUnassignTierCommandHandler,
+	command.NewImportTiersCommandHandler,
+	command.NewCreateAccessRuleCommandHandler,
+	command.NewDeleteAccessRuleCommandHandler,
+	query.NewGetRateLimitStatusQueryHandler,
+	query.NewGetPolicyQueryHandler,
+	query.NewListPoliciesQueryHandler,
+	query.NewGetOverrideQueryHandler,
+	query.NewListOverridesQueryHandler,
+	query.NewGetTierQueryHandler,
+	query.NewListAccessRulesQueryHandler,
+	query.NewListAccessAuditQueryHandler,
+	query.NewGetShadowReportQueryHandler,
 	
 	// Presentation providers
 	http.NewRateLimitHandler,
+	http.NewRateLimitAdminHandler,
+	http.NewConcurrencyLimitHandler,
+	http.NewQuotaHandler,
+	http.NewPolicyHandler,
+	http.NewOverrideHandler,
+	http.NewTierHandler,
+	http.NewAccessHandler,
 )
 
 // HybridProviderSet is the Wire provider set for the rate-limit module with hybrid caching
@@ -34,20 +102,87 @@ var HybridProviderSet = wire.NewSet(
 	infrastructure.NewRedisRateLimitRepository,
 	infrastructure.NewHybridRateLimitRepository,
 	wire.Bind(new(ports.RateLimitRepository), new(*infrastructure.HybridRateLimitRepository)),
+	wire.Bind(new(ports.RateLimitAdminRepository), new(*infrastructure.HybridRateLimitRepository)),
+	infrastructure.NewTokenBucketRateLimitRepository,
+	infrastructure.NewSlidingWindowLogRateLimitRepository,
+	infrastructure.NewSlidingWindowCounterRateLimitRepository,
+	infrastructure.NewGCRARateLimitRepository,
+	infrastructure.NewAlgorithmRepositoryProvider,
+	infrastructure.NewRedisConcurrencyLimitRepository,
+	wire.Bind(new(ports.ConcurrencyLimitRepository), new(*infrastructure.RedisConcurrencyLimitRepository)),
+	infrastructure.NewPostgresQuotaUsageStore,
+	wire.Bind(new(ports.QuotaUsageStore), new(*infrastructure.PostgresQuotaUsageStore)),
+	infrastructure.NewRedisQuotaRepository,
+	wire.Bind(new(ports.QuotaRepository), new(*infrastructure.RedisQuotaRepository)),
+	infrastructure.NewPostgresPolicyRepository,
+	infrastructure.NewCachedPolicyRepository,
+	wire.Bind(new(ports.PolicyRepository), new(*infrastructure.CachedPolicyRepository)),
+	infrastructure.NewFilePolicySource,
+	wire.Bind(new(ports.PolicyFileSource), new(*infrastructure.FilePolicySource)),
+	infrastructure.NewPostgresOverrideRepository,
+	infrastructure.NewCachedOverrideRepository,
+	wire.Bind(new(ports.OverrideRepository), new(*infrastructure.CachedOverrideRepository)),
+	infrastructure.NewPostgresTierRepository,
+	infrastructure.NewCachedTierRepository,
+	wire.Bind(new(ports.TierRepository), new(*infrastructure.CachedTierRepository)),
+	infrastructure.NewPostgresAccessRuleRepository,
+	infrastructure.NewCachedAccessRuleRepository,
+	wire.Bind(new(ports.AccessRuleRepository), new(*infrastructure.CachedAccessRuleRepository)),
+	infrastructure.NewRedisShadowLog,
+	wire.Bind(new(ports.ShadowLog), new(*infrastructure.RedisShadowLog)),
+	infrastructure.NewSystemClock,
+	wire.Bind(new(ports.Clock), new(*infrastructure.SystemClock)),
+	wire.Bind(new(ports.RateLimitRepositoryProvider), new(*infrastructure.AlgorithmRepositoryProvider)),
 	
 	// Application providers
+	limits.NewResolver,
 	command.NewCheckRateLimitCommandHandler,
 	command.NewCheckRateLimitWithDetailCommandHandler,
+	command.NewRefundRateLimitCommandHandler,
+	command.NewResetRateLimitCommandHandler,
+	command.NewAdjustRateLimitCommandHandler,
+	command.NewAcquireLeaseCommandHandler,
+	command.NewReleaseLeaseCommandHandler,
+	command.NewConsumeQuotaCommandHandler,
+	command.NewCreatePolicyCommandHandler,
+	command.NewUpdatePolicyCommandHandler,
+	command.NewDeletePolicyCommandHandler,
+	command.NewPutOverrideCommandHandler,
+	command.NewDeleteOverrideCommandHandler,
+	command.NewAssignTierCommandHandler,
+	command.NewUnassignTierCommandHandler,
+	command.NewImportTiersCommandHandler,
+	command.NewCreateAccessRuleCommandHandler,
+	command.NewDeleteAccessRuleCommandHandler,
+	query.NewGetRateLimitStatusQueryHandler,
+	query.NewGetPolicyQueryHandler,
+	query.NewListPoliciesQueryHandler,
+	query.NewGetOverrideQueryHandler,
+	query.NewListOverridesQueryHandler,
+	query.NewGetTierQueryHandler,
+	query.NewListAccessRulesQueryHandler,
+	query.NewListAccessAuditQueryHandler,
+	query.NewGetShadowReportQueryHandler,
 	
 	// Presentation providers
 	http.NewRateLimitHandler,
+	http.NewRateLimitAdminHandler,
+	http.NewConcurrencyLimitHandler,
+	http.NewQuotaHandler,
+	http.NewPolicyHandler,
+	http.NewOverrideHandler,
+	http.NewTierHandler,
+	http.NewAccessHandler,
 )
 
-// NewRateLimitModule creates a new rate-limit module with all dependencies wired
+// NewRateLimitModule creates a new rate-limit module with all dependencies wired.
+// The returned cleanup function stops the background work of the dependencies.
 func NewRateLimitModule(
 	logger logger.Logger,
 	redisClient *redis.Client,
-) (*http.RateLimitHandler, error) {
+	db *pgxpool.Pool,
+	cfg *config.Config,
+) (*http.RateLimitHandler, func(), error) {
 	wire.Build(ProviderSet)
-	return nil, nil
+	return nil, nil, nil
 }
\ No newline at end of file
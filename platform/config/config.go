@@ -79,9 +79,28 @@ type CORSConfig struct {
 
 // RateLimitConfig holds rate limiting configuration
 type RateLimitConfig struct {
-	Enabled           bool `mapstructure:"enabled"`
-	RequestsPerMinute int  `mapstructure:"requests_per_minute"`
-	Burst             int  `mapstructure:"burst"`
+	Enabled                        bool          `mapstructure:"enabled"`
+	RequestsPerMinute              int           `mapstructure:"requests_per_minute"`
+	Burst                          int           `mapstructure:"burst"`
+	MaxConcurrent                  int           `mapstructure:"max_concurrent"`
+	LeaseTTL                       time.Duration `mapstructure:"lease_ttl"`
+	QuotaTimeZone                  string        `mapstructure:"quota_time_zone"`
+	QuotaPersistInterval           time.Duration `mapstructure:"quota_persist_interval"`
+	RequirePolicy                  bool          `mapstructure:"require_policy"`
+	CacheTTL                       time.Duration `mapstructure:"cache_ttl"`
+	DefaultTier                    string        `mapstructure:"default_tier"`
+	PolicyFile                     string        `mapstructure:"policy_file"`
+	ShadowRetention                time.Duration `mapstructure:"shadow_retention"`
+	LocalCacheMaxEntries           int           `mapstructure:"local_cache_max_entries"`
+	LocalCacheCleanupInterval      time.Duration `mapstructure:"local_cache_cleanup_interval"`
+	TokenLeaseEnabled              bool          `mapstructure:"token_lease_enabled"`
+	TokenLeaseFraction             float64       `mapstructure:"token_lease_fraction"`
+	WriteBehindEnabled             bool          `mapstructure:"write_behind_enabled"`
+	WriteBehindFlushInterval       time.Duration `mapstructure:"write_behind_flush_interval"`
+	WriteBehindMaxOvershoot        int           `mapstructure:"write_behind_max_overshoot"`
+	CircuitBreakerFailureThreshold int           `mapstructure:"circuit_breaker_failure_threshold"`
+	CircuitBreakerSuccessThreshold int           `mapstructure:"circuit_breaker_success_threshold"`
+	CircuitBreakerOpenTimeout      time.Duration `mapstructure:"circuit_breaker_open_timeout"`
 }
 
 // HealthConfig holds health check configuration
@@ -189,6 +208,25 @@ func setDefaults() {
 	viper.SetDefault("rate_limit.enabled", true)
 	viper.SetDefault("rate_limit.requests_per_minute", 100)
 	viper.SetDefault("rate_limit.burst", 10)
+	viper.SetDefault("rate_limit.max_concurrent", 5)
+	viper.SetDefault("rate_limit.lease_ttl", "30s")
+	viper.SetDefault("rate_limit.quota_time_zone", "UTC")
+	viper.SetDefault("rate_limit.quota_persist_interval", "5s")
+	viper.SetDefault("rate_limit.require_policy", true)
+	viper.SetDefault("rate_limit.cache_ttl", "30s")
+	viper.SetDefault("rate_limit.default_tier", "")
+	viper.SetDefault("rate_limit.policy_file", "./configs/policies.yaml")
+	viper.SetDefault("rate_limit.shadow_retention", "24h")
+	viper.SetDefault("rate_limit.local_cache_max_entries", 100000)
+	viper.SetDefault("rate_limit.local_cache_cleanup_interval", "1m")
+	viper.SetDefault("rate_limit.token_lease_enabled", false)
+	viper.SetDefault("rate_limit.token_lease_fraction", 0.1)
+	viper.SetDefault("rate_limit.write_behind_enabled", false)
+	viper.SetDefault("rate_limit.write_behind_flush_interval", "50ms")
+	viper.SetDefault("rate_limit.write_behind_max_overshoot", 10)
+	viper.SetDefault("rate_limit.circuit_breaker_failure_threshold", 5)
+	viper.SetDefault("rate_limit.circuit_breaker_success_threshold", 1)
+	viper.SetDefault("rate_limit.circuit_breaker_open_timeout", "5s")
 
 	// Health check defaults
 	viper.SetDefault("health.database_timeout", "5s")
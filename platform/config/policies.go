@@ -0,0 +1,129 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/go-clean/platform/logger"
+	"github.com/spf13/viper"
+)
+
+// policyReloadDelay is how long the policy file must go without changes before it is reloaded
+const policyReloadDelay = 100 * time.Millisecond
+
+// PolicyFile holds the rate limits declared in the policy file, keyed by route, tenant and tier
+type PolicyFile struct {
+	Routes  map[string]PolicyLimit `mapstructure:"routes"`
+	Tenants map[string]PolicyLimit `mapstructure:"tenants"`
+	Tiers   map[string]PolicyLimit `mapstructure:"tiers"`
+}
+
+// PolicyLimit holds a single limit declared in the policy file
+type PolicyLimit struct {
+	Limit     int              `mapstructure:"limit"`
+	Window    time.Duration    `mapstructure:"window"`
+	Algorithm string           `mapstructure:"algorithm"`
+	Burst     int              `mapstructure:"burst"`
+	Mode      string           `mapstructure:"mode"`
+	Schedules []PolicySchedule `mapstructure:"schedules"`
+}
+
+// PolicySchedule holds a recurring time range in which a declared policy enforces a different limit
+type PolicySchedule struct {
+	Days     []string      `mapstructure:"days"`
+	Start    string        `mapstructure:"start"`
+	End      string        `mapstructure:"end"`
+	TimeZone string        `mapstructure:"time_zone"`
+	Limit    int           `mapstructure:"limit"`
+	Window   time.Duration `mapstructure:"window"`
+	Burst    int           `mapstructure:"burst"`
+}
+
+// WatchPolicyFile loads the policy file at path and passes it to apply, then reloads it whenever the file changes.
+// A file that fails to load at startup is returned as an error, while a changed file that cannot be read or that
+// apply rejects is logged and ignored, so the last good version stays in effect. A missing file declares no policies.
+func WatchPolicyFile(log logger.Logger, path string, apply func(*PolicyFile) error) error {
+	if path == "" {
+		log.Debug().Msg("No policy file configured")
+		return apply(&PolicyFile{})
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		log.Info().Str("policy_file", path).Msg("Policy file not found, no policies declared")
+		return apply(&PolicyFile{})
+	}
+
+	policies, err := readPolicyFile(path)
+	if err != nil {
+		log.Error().Str("policy_file", path).Err(err).Msg("Failed to read policy file")
+		return err
+	}
+	if err := apply(policies); err != nil {
+		log.Error().Str("policy_file", path).Err(err).Msg("Invalid policy file")
+		return fmt.Errorf("invalid policy file %s: %w", path, err)
+	}
+	log.Info().Str("policy_file", path).Int("routes", len(policies.Routes)).Int("tenants", len(policies.Tenants)).Int("tiers", len(policies.Tiers)).Msg("Policy file loaded successfully")
+
+	// Editors save a file in several writes, so it is only read once the writes have settled
+	var mu sync.Mutex
+	var pending *time.Timer
+	reload := func() {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// The watcher's own copy is not used, since it keeps the previous contents when the new file cannot be parsed
+		policies, err := readPolicyFile(path)
+		if err != nil {
+			log.Error().Str("policy_file", path).Err(err).Msg("Failed to reload policy file, keeping the last good version")
+			return
+		}
+		if err := apply(policies); err != nil {
+			log.Error().Str("policy_file", path).Err(err).Msg("Invalid policy file, keeping the last good version")
+			return
+		}
+		log.Info().Str("policy_file", path).Int("routes", len(policies.Routes)).Int("tenants", len(policies.Tenants)).Int("tiers", len(policies.Tiers)).Msg("Policy file reloaded")
+	}
+
+	watcher := viper.New()
+	watcher.SetConfigFile(path)
+	watcher.OnConfigChange(func(event fsnotify.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		if pending != nil {
+			pending.Stop()
+		}
+		pending = time.AfterFunc(policyReloadDelay, reload)
+	})
+	watcher.WatchConfig()
+
+	return nil
+}
+
+// readPolicyFile parses the policy file, rejecting keys that PolicyFile does not declare so that typos are not silently ignored.
+// Viper lowercases every key, and the delimiter is changed from '.' so that routes and names containing dots are not split.
+func readPolicyFile(path string) (*PolicyFile, error) {
+	reader := viper.NewWithOptions(viper.KeyDelimiter("::"))
+	reader.SetConfigFile(path)
+	reader.SetConfigType("yaml")
+	if err := reader.ReadInConfig(); err != nil {
+		return nil, err
+	}
+
+	// An empty file is more likely caught halfway through a save than meant to remove every policy
+	if !reader.IsSet("policies") {
+		return nil, fmt.Errorf("missing policies section")
+	}
+
+	var file struct {
+		Policies PolicyFile `mapstructure:"policies"`
+	}
+	if err := reader.UnmarshalExact(&file); err != nil {
+		return nil, err
+	}
+	return &file.Policies, nil
+}
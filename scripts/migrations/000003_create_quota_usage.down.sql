@@ -0,0 +1,8 @@
+-- Rollback create quota_usage table migration
+-- This removes the quota_usage table created in the up migration
+
+BEGIN;
+
+DROP TABLE IF EXISTS quota_usage;
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Create quota_usage table migration
+-- Persists the usage of calendar-aligned quotas so it survives Redis flushes
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS quota_usage (
+    user_id TEXT NOT NULL,
+    period VARCHAR(16) NOT NULL,
+    period_start TIMESTAMP WITH TIME ZONE NOT NULL,
+    used BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, period, period_start)
+);
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Rollback create rate_limit_policies table migration
+-- This removes the rate_limit_policies table created in the up migration
+
+BEGIN;
+
+DROP TABLE IF EXISTS rate_limit_policies;
+
+COMMIT;
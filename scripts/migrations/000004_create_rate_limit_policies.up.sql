@@ -0,0 +1,16 @@
+-- Create rate_limit_policies table migration
+-- Stores named rate limit policies that callers reference instead of sending their own limit
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS rate_limit_policies (
+    name VARCHAR(64) PRIMARY KEY,
+    request_limit INTEGER NOT NULL CHECK (request_limit > 0),
+    window_ms BIGINT NOT NULL CHECK (window_ms > 0),
+    algorithm VARCHAR(32) NOT NULL,
+    burst INTEGER NOT NULL DEFAULT 0 CHECK (burst >= 0),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+COMMIT;
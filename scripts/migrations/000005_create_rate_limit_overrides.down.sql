@@ -0,0 +1,8 @@
+-- Rollback create rate_limit_overrides table migration
+-- This removes the rate_limit_overrides table created in the up migration
+
+BEGIN;
+
+DROP TABLE IF EXISTS rate_limit_overrides;
+
+COMMIT;
@@ -0,0 +1,20 @@
+-- Create rate_limit_overrides table migration
+-- Stores per-user overrides that scale or replace the limits a user is checked against
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS rate_limit_overrides (
+    user_id TEXT PRIMARY KEY,
+    multiplier DOUBLE PRECISION CHECK (multiplier > 0),
+    request_limit INTEGER CHECK (request_limit > 0),
+    window_ms BIGINT CHECK (window_ms > 0),
+    reason TEXT NOT NULL DEFAULT '',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- An override either scales the requested limits or replaces them with a fixed limit
+    CHECK ((multiplier IS NULL) <> (request_limit IS NULL)),
+    CHECK ((request_limit IS NULL) = (window_ms IS NULL))
+);
+
+COMMIT;
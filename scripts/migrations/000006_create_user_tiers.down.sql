@@ -0,0 +1,8 @@
+-- Rollback create user_tiers table migration
+-- This removes the user_tiers table created in the up migration
+
+BEGIN;
+
+DROP TABLE IF EXISTS user_tiers;
+
+COMMIT;
@@ -0,0 +1,15 @@
+-- Create user_tiers table migration
+-- Assigns users to tiers, whose limits are the rate limit policies of the same name
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS user_tiers (
+    user_id TEXT PRIMARY KEY,
+    -- A policy cannot be deleted while users are assigned to it as their tier
+    tier VARCHAR(64) NOT NULL REFERENCES rate_limit_policies (name) ON DELETE RESTRICT,
+    assigned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tiers_tier ON user_tiers (tier);
+
+COMMIT;
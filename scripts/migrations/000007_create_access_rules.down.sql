@@ -0,0 +1,9 @@
+-- Rollback create access_rules and access_rule_audit tables migration
+-- This removes the tables created in the up migration
+
+BEGIN;
+
+DROP TABLE IF EXISTS access_rule_audit;
+DROP TABLE IF EXISTS access_rules;
+
+COMMIT;
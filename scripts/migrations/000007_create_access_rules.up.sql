@@ -0,0 +1,32 @@
+-- Create access_rules and access_rule_audit tables migration
+-- Stores the allow and deny lists checked before any rate limit, and the history of changes made to them
+
+BEGIN;
+
+CREATE TABLE IF NOT EXISTS access_rules (
+    id BIGSERIAL PRIMARY KEY,
+    list TEXT NOT NULL CHECK (list IN ('allow', 'deny')),
+    match_type TEXT NOT NULL CHECK (match_type IN ('id', 'prefix', 'cidr')),
+    value TEXT NOT NULL CHECK (value <> ''),
+    reason TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (list, match_type, value)
+);
+
+-- Audit entries copy the rule rather than reference it, so they outlive deleted rules
+CREATE TABLE IF NOT EXISTS access_rule_audit (
+    id BIGSERIAL PRIMARY KEY,
+    rule_id BIGINT NOT NULL,
+    action TEXT NOT NULL CHECK (action IN ('created', 'deleted')),
+    list TEXT NOT NULL,
+    match_type TEXT NOT NULL,
+    value TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    actor TEXT NOT NULL DEFAULT '',
+    at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_access_rule_audit_at ON access_rule_audit (at DESC);
+
+COMMIT;
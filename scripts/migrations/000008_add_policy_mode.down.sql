@@ -0,0 +1,8 @@
+-- Rollback add mode column to rate_limit_policies migration
+-- This removes the mode column added in the up migration, so every policy is enforced again
+
+BEGIN;
+
+ALTER TABLE rate_limit_policies DROP COLUMN IF EXISTS mode;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Add mode column to rate_limit_policies migration
+-- Shadow policies count requests as usual but only record the ones they would deny
+
+BEGIN;
+
+ALTER TABLE rate_limit_policies
+    ADD COLUMN IF NOT EXISTS mode VARCHAR(16) NOT NULL DEFAULT 'enforce' CHECK (mode IN ('enforce', 'shadow'));
+
+COMMIT;
@@ -0,0 +1,8 @@
+-- Rollback add schedules column to rate_limit_policies migration
+-- This removes the schedules column added in the up migration, so every policy enforces its base limit again
+
+BEGIN;
+
+ALTER TABLE rate_limit_policies DROP COLUMN IF EXISTS schedules;
+
+COMMIT;
@@ -0,0 +1,9 @@
+-- Add schedules column to rate_limit_policies migration
+-- Schedules replace the limit of a policy during recurring time ranges, e.g. higher limits at night
+
+BEGIN;
+
+ALTER TABLE rate_limit_policies
+    ADD COLUMN IF NOT EXISTS schedules JSONB NOT NULL DEFAULT '[]'::jsonb;
+
+COMMIT;